@@ -0,0 +1,85 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command crd-features generates skeleton features from CustomResourceDefinitions:
+//
+//	go run sigs.k8s.io/e2e-framework/cmd/crd-features -crd config/crd/bases -package e2e -out crd_features_test.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"sigs.k8s.io/e2e-framework/pkg/codegen"
+)
+
+func main() {
+	crdPath := flag.String("crd", "", "CRD manifest file, or directory of manifest files")
+	pkg := flag.String("package", "e2e", "Package name of the generated file")
+	out := flag.String("out", "", "Output file (default stdout)")
+	flag.Parse()
+
+	if err := run(*crdPath, *pkg, *out); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(crdPath, pkg, out string) error {
+	if crdPath == "" {
+		return fmt.Errorf("-crd is required")
+	}
+	files := []string{crdPath}
+	if info, err := os.Stat(crdPath); err != nil {
+		return err
+	} else if info.IsDir() {
+		files = nil
+		for _, pattern := range []string{"*.yaml", "*.yml", "*.json"} {
+			matches, err := filepath.Glob(filepath.Join(crdPath, pattern))
+			if err != nil {
+				return err
+			}
+			files = append(files, matches...)
+		}
+	}
+
+	var kinds []codegen.Kind
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		k, err := codegen.ParseCRDs(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		kinds = append(kinds, k...)
+	}
+
+	src, err := codegen.Generate(pkg, kinds)
+	if err != nil {
+		return err
+	}
+
+	if out != "" {
+		return os.WriteFile(out, src, 0o644)
+	}
+	_, err = os.Stdout.Write(src)
+	return err
+}
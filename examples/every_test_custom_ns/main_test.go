@@ -90,6 +90,11 @@ func createNSForTest(ctx context.Context, cfg *envconf.Config, t *testing.T, run
 // DeleteNSForTest looks up the namespace corresponding to the given test and deletes it.
 func deleteNSForTest(ctx context.Context, cfg *envconf.Config, t *testing.T, runID string) (context.Context, error) {
 	ns := fmt.Sprint(ctx.Value(nsKey(t)))
+	// keep the namespace to debug the failure when the run is configured to
+	if env.TeardownSkipped(ctx) {
+		t.Logf("Keeping NS %v of failed test %v", ns, t.Name())
+		return ctx, nil
+	}
 	t.Logf("Deleting NS %v for test %v", ns, t.Name())
 
 	nsObj := v1.Namespace{}
@@ -0,0 +1,60 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suites
+
+import (
+	"context"
+	"testing"
+
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+)
+
+type greetingKey struct{}
+
+// TestGreetingSuite shows how Environment.TestSuite groups features
+// in a suite, with setup and teardown steps shared by its features
+// and labels applied to all of them.
+func TestGreetingSuite(t *testing.T) {
+	suite := features.NewSuite("greetings").
+		WithLabel("type", "greeting").
+		Setup(func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+			return context.WithValue(ctx, greetingKey{}, "Hello")
+		}).
+		Teardown(func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+			t.Log("greetings done")
+			return ctx
+		}).
+		WithFeatures(
+			features.New("bazz greeting").
+				Assess("Hello Bazz", func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+					if result := Hello("bazz"); result != ctx.Value(greetingKey{}).(string)+" bazz" {
+						t.Error("unexpected message")
+					}
+					return ctx
+				}).Feature(),
+			features.New("batt greeting").
+				Assess("Hello Batt", func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+					if result := Hello("batt"); result != ctx.Value(greetingKey{}).(string)+" batt" {
+						t.Error("unexpected message")
+					}
+					return ctx
+				}).Feature(),
+		).Suite()
+
+	testenv.TestSuite(t, suite)
+}
@@ -20,3 +20,5 @@ set -o pipefail
 
 go mod tidy
 git diff --exit-code
+(cd third_party/ginkgo && go mod tidy)
+git diff --exit-code
@@ -0,0 +1,239 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package apicalls counts the requests sent to the API server, by scope (i.e. the
+// test of a feature), verb, and resource, to diagnose the throttling of large
+// parallel suites and report the API usage of each test.
+//
+// The scope of a request is read from its context: the requests sent with a context
+// that does not derive from the one of the test, i.e. context.TODO() or the wait
+// conditions created without conditions.Condition.WithContext, are counted with
+// an empty scope.
+package apicalls
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+)
+
+type scopeContextKey struct{}
+
+// WithScope returns a context whose requests are counted under scope,
+// i.e. the name of the test of a feature
+func WithScope(ctx context.Context, scope string) context.Context {
+	return context.WithValue(ctx, scopeContextKey{}, scope)
+}
+
+// ScopeFrom returns the scope of the requests of the context, empty if not set
+func ScopeFrom(ctx context.Context) string {
+	scope, _ := ctx.Value(scopeContextKey{}).(string)
+	return scope
+}
+
+// Call is the count of the requests of a scope with the same verb and resource
+type Call struct {
+	// Scope is the scope of the context of the requests, see WithScope
+	Scope string
+	// Verb is the API verb of the requests, i.e. get, list, or create
+	Verb string
+	// Resource is the resource of the requests, qualified by its group and
+	// followed by the subresource, i.e. deployments.apps or pods/log. It is
+	// the path of the requests to non-resource URLs, i.e. /version.
+	Resource string
+	// Count is the number of requests sent
+	Count int
+	// Throttled is the number of requests rejected with 429 Too Many Requests by the
+	// API server. The requests delayed by the client-side rate limiter, configured with
+	// klient.WithQPS, are not throttled by the server and are not counted.
+	Throttled int
+}
+
+type callKey struct {
+	scope, verb, resource string
+}
+
+// Counter counts the requests sent through the round trippers it wraps.
+// It is safe for concurrent use.
+type Counter struct {
+	mu    sync.Mutex
+	calls map[callKey]*Call
+}
+
+// NewCounter creates a counter with no request counted
+func NewCounter() *Counter {
+	return &Counter{calls: make(map[callKey]*Call)}
+}
+
+// Wrap returns a round tripper counting the requests sent with rt, to
+// be installed with rest.Config.WrapTransport or klient.WithTransportWrapper
+func (c *Counter) Wrap(rt http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := rt.RoundTrip(req)
+		c.record(req, resp)
+		return resp, err
+	})
+}
+
+func (c *Counter) record(req *http.Request, resp *http.Response) {
+	verb, resource := requestInfo(req)
+	key := callKey{scope: ScopeFrom(req.Context()), verb: verb, resource: resource}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	call, ok := c.calls[key]
+	if !ok {
+		call = &Call{Scope: key.scope, Verb: key.verb, Resource: key.resource}
+		c.calls[key] = call
+	}
+	call.Count++
+	if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+		call.Throttled++
+	}
+}
+
+// Calls returns the requests counted, sorted by scope, resource, and verb
+func (c *Counter) Calls() []Call {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	calls := make([]Call, 0, len(c.calls))
+	for _, call := range c.calls {
+		calls = append(calls, *call)
+	}
+	sort.Slice(calls, func(i, j int) bool {
+		if calls[i].Scope != calls[j].Scope {
+			return calls[i].Scope < calls[j].Scope
+		}
+		if calls[i].Resource != calls[j].Resource {
+			return calls[i].Resource < calls[j].Resource
+		}
+		return calls[i].Verb < calls[j].Verb
+	})
+	return calls
+}
+
+// Total returns the number of requests sent, and throttled, under scope
+func (c *Counter) Total(scope string) (count, throttled int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, call := range c.calls {
+		if key.scope == scope {
+			count += call.Count
+			throttled += call.Throttled
+		}
+	}
+	return count, throttled
+}
+
+// Reset clears the requests counted
+func (c *Counter) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = make(map[callKey]*Call)
+}
+
+// WriteTo writes a table of the requests counted to w
+func (c *Counter) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	tw := tabwriter.NewWriter(cw, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "SCOPE\tVERB\tRESOURCE\tCOUNT\tTHROTTLED")
+	for _, call := range c.Calls() {
+		scope := call.Scope
+		if scope == "" {
+			scope = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\n", scope, call.Verb, call.Resource, call.Count, call.Throttled)
+	}
+	err := tw.Flush()
+	return cw.n, err
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// requestInfo returns the API verb and the resource of the request from its
+// method and path, i.e. /apis/apps/v1/namespaces/default/deployments/app
+func requestInfo(req *http.Request) (verb, resource string) {
+	parts := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	var group string
+	switch {
+	case len(parts) >= 3 && parts[0] == "api":
+		parts = parts[2:]
+	case len(parts) >= 4 && parts[0] == "apis":
+		group, parts = parts[1], parts[3:]
+	default:
+		return strings.ToLower(req.Method), req.URL.Path
+	}
+	// namespaced resources, except the namespaces themselves
+	if len(parts) >= 3 && parts[0] == "namespaces" {
+		parts = parts[2:]
+	}
+
+	resource = parts[0]
+	if group != "" {
+		resource += "." + group
+	}
+	if len(parts) >= 3 {
+		resource += "/" + parts[2]
+	}
+	named := len(parts) >= 2
+
+	switch req.Method {
+	case http.MethodGet:
+		switch {
+		case req.URL.Query().Get("watch") == "true":
+			verb = "watch"
+		case named:
+			verb = "get"
+		default:
+			verb = "list"
+		}
+	case http.MethodPost:
+		verb = "create"
+	case http.MethodPut:
+		verb = "update"
+	case http.MethodPatch:
+		verb = "patch"
+	case http.MethodDelete:
+		if named {
+			verb = "delete"
+		} else {
+			verb = "deletecollection"
+		}
+	default:
+		verb = strings.ToLower(req.Method)
+	}
+	return verb, resource
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apicalls
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRequestInfo(t *testing.T) {
+	tests := []struct {
+		method   string
+		path     string
+		verb     string
+		resource string
+	}{
+		{http.MethodGet, "/api/v1/namespaces/default/pods", "list", "pods"},
+		{http.MethodGet, "/api/v1/namespaces/default/pods/app", "get", "pods"},
+		{http.MethodGet, "/api/v1/namespaces/default/pods?watch=true", "watch", "pods"},
+		{http.MethodGet, "/api/v1/namespaces/default/pods/app/log", "get", "pods/log"},
+		{http.MethodGet, "/api/v1/namespaces/default", "get", "namespaces"},
+		{http.MethodPost, "/api/v1/namespaces", "create", "namespaces"},
+		{http.MethodGet, "/api/v1/nodes", "list", "nodes"},
+		{http.MethodPost, "/apis/apps/v1/namespaces/default/deployments", "create", "deployments.apps"},
+		{http.MethodPut, "/apis/apps/v1/namespaces/default/deployments/app/status", "update", "deployments.apps/status"},
+		{http.MethodPatch, "/apis/rbac.authorization.k8s.io/v1/clusterroles/admin", "patch", "clusterroles.rbac.authorization.k8s.io"},
+		{http.MethodDelete, "/api/v1/namespaces/default/configmaps/cm", "delete", "configmaps"},
+		{http.MethodDelete, "/api/v1/namespaces/default/configmaps", "deletecollection", "configmaps"},
+		{http.MethodGet, "/version", "get", "/version"},
+		{http.MethodGet, "/apis", "get", "/apis"},
+	}
+	for _, test := range tests {
+		t.Run(test.method+" "+test.path, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, test.path, nil)
+			verb, resource := requestInfo(req)
+			if verb != test.verb || resource != test.resource {
+				t.Errorf("expected %s %s, got %s %s", test.verb, test.resource, verb, resource)
+			}
+		})
+	}
+}
+
+func TestCounter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	counter := NewCounter()
+	client := &http.Client{Transport: counter.Wrap(http.DefaultTransport)}
+	send := func(ctx context.Context, method, path string) {
+		req, err := http.NewRequestWithContext(ctx, method, server.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	ctx := WithScope(context.Background(), "TestFoo/feature")
+	send(ctx, http.MethodGet, "/api/v1/namespaces/default/pods")
+	send(ctx, http.MethodGet, "/api/v1/namespaces/default/pods")
+	send(ctx, http.MethodPost, "/api/v1/namespaces/default/pods")
+	send(context.Background(), http.MethodGet, "/version")
+
+	expected := []Call{
+		{Scope: "", Verb: "get", Resource: "/version", Count: 1},
+		{Scope: "TestFoo/feature", Verb: "create", Resource: "pods", Count: 1, Throttled: 1},
+		{Scope: "TestFoo/feature", Verb: "list", Resource: "pods", Count: 2},
+	}
+	if calls := counter.Calls(); !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected calls %+v, got %+v", expected, calls)
+	}
+	if count, throttled := counter.Total("TestFoo/feature"); count != 3 || throttled != 1 {
+		t.Errorf("expected 3 calls with 1 throttled, got %d and %d", count, throttled)
+	}
+
+	var report bytes.Buffer
+	if _, err := counter.WriteTo(&report); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(report.String()), "\n"); len(lines) != 4 || !strings.HasPrefix(lines[0], "SCOPE") {
+		t.Errorf("unexpected report:\n%s", report.String())
+	}
+
+	counter.Reset()
+	if calls := counter.Calls(); len(calls) != 0 {
+		t.Errorf("expected no call after reset, got %+v", calls)
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package assert provides eventually-consistent assertions on Kubernetes objects.
+// The object is polled until it matches, and a failed assertion reports the fields
+// that did not have their expected value at the last check, rather than a timeout.
+package assert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	apimachinerywait "k8s.io/apimachinery/pkg/util/wait"
+	log "k8s.io/klog/v2"
+
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+)
+
+// MismatchError is returned when an object did not match before the wait ended.
+// It lists the mismatches found at the last check of the object.
+type MismatchError struct {
+	// Object identifies the object checked
+	Object string
+	// Mismatches are the fields without their expected value at the last check
+	Mismatches []Mismatch
+	// LastErr is the error of the last attempt to get the object, if it failed
+	LastErr error
+	// Err is the reason the wait ended, i.e. wait.ErrWaitTimeout
+	Err error
+}
+
+func (e *MismatchError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s did not match: %s", e.Object, e.Err)
+	if e.LastErr != nil {
+		fmt.Fprintf(&sb, "\nlast get failed: %s", e.LastErr)
+		return sb.String()
+	}
+	for _, m := range e.Mismatches {
+		fmt.Fprintf(&sb, "\n  %s", m)
+	}
+	return sb.String()
+}
+
+func (e *MismatchError) Unwrap() error {
+	return e.Err
+}
+
+// Eventually gets obj until it satisfies the matcher, i.e.
+//
+//	err := assert.Eventually(ctx, r, deployment, assert.Fields(expected), wait.WithTimeout(time.Minute))
+//
+// The wait options are the ones of wait.For. The requests are sent with ctx, i.e. the
+// context of a feature assessment, and the wait ends when ctx is done. If the object
+// still does not match when the wait ends, a *MismatchError lists the fields that did
+// not have their expected value at the last check. obj is updated with the last state
+// retrieved.
+func Eventually(ctx context.Context, r *resources.Resources, obj k8s.Object, matcher Matcher, opts ...wait.Option) error {
+	get := func() error {
+		return r.Get(ctx, obj.GetName(), obj.GetNamespace(), obj)
+	}
+	return eventually(ctx, get, obj, matcher, opts...)
+}
+
+// ResourceEventually is like Eventually, but reports a failed assertion as an error
+// of the test. It returns whether the assertion succeeded.
+func ResourceEventually(ctx context.Context, t *testing.T, r *resources.Resources, obj k8s.Object, matcher Matcher, opts ...wait.Option) bool {
+	t.Helper()
+	if err := Eventually(ctx, r, obj, matcher, opts...); err != nil {
+		t.Error(err)
+		return false
+	}
+	return true
+}
+
+func eventually(ctx context.Context, get func() error, obj k8s.Object, matcher Matcher, opts ...wait.Option) error {
+	desc := describe(obj)
+	var (
+		mismatches []Mismatch
+		lastErr    error
+	)
+	err := wait.For(func() (bool, error) {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		log.V(4).InfoS("Checking for resource to match", "resource", desc)
+		if lastErr = get(); lastErr != nil {
+			return false, nil
+		}
+		var err error
+		if mismatches, err = matcher(obj); err != nil {
+			return false, err
+		}
+		log.V(4).InfoS("Resource mismatches", "resource", desc, "mismatches", len(mismatches))
+		return len(mismatches) == 0, nil
+	}, opts...)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, apimachinerywait.ErrWaitTimeout) {
+		return fmt.Errorf("assert: %s: %w", desc, err)
+	}
+	return &MismatchError{Object: desc, Mismatches: mismatches, LastErr: lastErr, Err: err}
+}
+
+// describe identifies obj in the failure messages
+func describe(obj k8s.Object) string {
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	if kind == "" {
+		kind = fmt.Sprintf("%T", obj)
+	}
+	if obj.GetNamespace() == "" {
+		return fmt.Sprintf("%s %s", kind, obj.GetName())
+	}
+	return fmt.Sprintf("%s %s/%s", kind, obj.GetNamespace(), obj.GetName())
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assert
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apimachinerywait "k8s.io/apimachinery/pkg/util/wait"
+
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+)
+
+func deployment(replicas, ready int32, image string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Labels: map[string]string{"app.kubernetes.io/name": "web"}},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: v1.PodTemplateSpec{Spec: v1.PodSpec{Containers: []v1.Container{{Name: "web", Image: image}}}},
+		},
+		Status: appsv1.DeploymentStatus{ReadyReplicas: ready},
+	}
+}
+
+func TestFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected k8s.Object
+		actual   k8s.Object
+		want     []Mismatch
+	}{
+		{
+			name:     "match",
+			expected: &appsv1.Deployment{Status: appsv1.DeploymentStatus{ReadyReplicas: 3}},
+			actual:   deployment(3, 3, "nginx"),
+		},
+		{
+			name:     "mismatched fields",
+			expected: deployment(3, 3, "nginx:1.23"),
+			actual:   deployment(3, 1, "nginx"),
+			want: []Mismatch{
+				{Path: "spec.template.spec.containers[0].image", Expected: `"nginx:1.23"`, Actual: `"nginx"`},
+				{Path: "status.readyReplicas", Expected: "3", Actual: "1"},
+			},
+		},
+		{
+			name: "label with dots",
+			expected: &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{"app.kubernetes.io/name": "api"},
+			}},
+			actual: deployment(1, 1, "nginx"),
+			want: []Mismatch{
+				{Path: `metadata.labels["app.kubernetes.io/name"]`, Expected: `"api"`, Actual: `"web"`},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Fields(test.expected)(test.actual)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected mismatches %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestJSONPath(t *testing.T) {
+	d := deployment(3, 1, "nginx")
+	if got, err := JSONPath(".status.readyReplicas", "1")(d); err != nil || len(got) != 0 {
+		t.Errorf("expected a match, got %v, %v", got, err)
+	}
+	got, err := JSONPath(".status.readyReplicas", "3")(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Mismatch{{Path: ".status.readyReplicas", Expected: "3", Actual: "1"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected mismatches %v, got %v", want, got)
+	}
+	if _, err := JSONPath("{.status[", "1")(d); err == nil {
+		t.Error("expected an error for an invalid expression")
+	}
+}
+
+func TestQuotaUsed(t *testing.T) {
+	quota := &v1.ResourceQuota{Status: v1.ResourceQuotaStatus{Used: v1.ResourceList{
+		v1.ResourceRequestsCPU: resource.MustParse("1"),
+		v1.ResourcePods:        resource.MustParse("2"),
+	}}}
+	if got, err := QuotaUsed(v1.ResourceList{v1.ResourceRequestsCPU: resource.MustParse("1000m")})(quota); err != nil || len(got) != 0 {
+		t.Errorf("expected a match, got %v, %v", got, err)
+	}
+	got, err := QuotaUsed(v1.ResourceList{
+		v1.ResourcePods:           resource.MustParse("3"),
+		v1.ResourceRequestsMemory: resource.MustParse("1Gi"),
+	})(quota)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Mismatch{
+		{Path: "status.used.pods", Expected: "3", Actual: "2"},
+		{Path: `status.used["requests.memory"]`, Expected: "1Gi", Actual: missing},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected mismatches %v, got %v", want, got)
+	}
+	if _, err := QuotaUsed(nil)(deployment(1, 1, "nginx")); err == nil {
+		t.Error("expected an error for an object other than a ResourceQuota")
+	}
+}
+
+func TestEventually(t *testing.T) {
+	ctx := context.Background()
+	opts := []wait.Option{wait.WithImmediate(), wait.WithInterval(time.Millisecond), wait.WithTimeout(100 * time.Millisecond)}
+
+	t.Run("eventually matches", func(t *testing.T) {
+		obj := deployment(3, 0, "nginx")
+		get := func() error {
+			obj.Status.ReadyReplicas++
+			return nil
+		}
+		if err := eventually(ctx, get, obj, Func("ready", func(o k8s.Object) bool {
+			return o.(*appsv1.Deployment).Status.ReadyReplicas == 3
+		}), opts...); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("reports the last mismatches", func(t *testing.T) {
+		obj := deployment(3, 1, "nginx")
+		err := eventually(ctx, func() error { return nil }, obj, All(
+			Fields(&appsv1.Deployment{Status: appsv1.DeploymentStatus{ReadyReplicas: 3}}),
+			JSONPath(".spec.replicas", "3"),
+		), opts...)
+		var mismatchErr *MismatchError
+		if !errors.As(err, &mismatchErr) {
+			t.Fatalf("expected a *MismatchError, got %v", err)
+		}
+		if !errors.Is(err, apimachinerywait.ErrWaitTimeout) {
+			t.Errorf("expected the error to wrap the timeout, got %v", err)
+		}
+		want := "*v1.Deployment default/web did not match: timed out waiting for the condition\n  status.readyReplicas: expected 3, got 1"
+		if err.Error() != want {
+			t.Errorf("expected error:\n%s\ngot:\n%s", want, err)
+		}
+	})
+
+	t.Run("reports the get error", func(t *testing.T) {
+		err := eventually(ctx, func() error { return errors.New("not found") }, deployment(1, 1, "nginx"), Fields(&appsv1.Deployment{}), opts...)
+		if err == nil || !strings.Contains(err.Error(), "last get failed: not found") {
+			t.Errorf("expected the get error to be reported, got %v", err)
+		}
+	})
+
+	t.Run("context done", func(t *testing.T) {
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		err := eventually(canceled, func() error { return nil }, deployment(1, 1, "nginx"), Fields(&appsv1.Deployment{}), opts...)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected the canceled context to stop the wait, got %v", err)
+		}
+	})
+
+	t.Run("matcher error", func(t *testing.T) {
+		err := eventually(ctx, func() error { return nil }, deployment(1, 1, "nginx"), QuotaUsed(nil), opts...)
+		var mismatchErr *MismatchError
+		if err == nil || errors.As(err, &mismatchErr) {
+			t.Errorf("expected the matcher error to stop the wait, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assert
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/jsonpath"
+
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+)
+
+// missing is the value reported for the fields absent from the object
+const missing = "<missing>"
+
+// Mismatch is a field of an object that does not have its expected value
+type Mismatch struct {
+	// Path of the field, i.e. spec.template.spec.containers[0].image
+	Path     string
+	Expected string
+	Actual   string
+}
+
+func (m Mismatch) String() string {
+	return fmt.Sprintf("%s: expected %s, got %s", m.Path, m.Expected, m.Actual)
+}
+
+// Matcher returns the mismatches of the object with its expected state,
+// none when the object matches.
+type Matcher func(obj k8s.Object) ([]Mismatch, error)
+
+// All matches the objects matching all the provided matchers, reporting
+// the mismatches of each of them.
+func All(matchers ...Matcher) Matcher {
+	return func(obj k8s.Object) ([]Mismatch, error) {
+		var mismatches []Mismatch
+		for _, m := range matchers {
+			found, err := m(obj)
+			if err != nil {
+				return nil, err
+			}
+			mismatches = append(mismatches, found...)
+		}
+		return mismatches, nil
+	}
+}
+
+// Fields matches the objects whose fields have the value they have in expected.
+// The fields not set in expected are ignored, and the elements of the lists are
+// compared by index, so that expected only needs the fields to check:
+//
+//	expected := &appsv1.Deployment{Status: appsv1.DeploymentStatus{ReadyReplicas: 3}}
+//	err := assert.Eventually(ctx, r, deployment, assert.Fields(expected))
+func Fields(expected k8s.Object) Matcher {
+	return func(obj k8s.Object) ([]Mismatch, error) {
+		exp, err := runtime.DefaultUnstructuredConverter.ToUnstructured(expected)
+		if err != nil {
+			return nil, fmt.Errorf("assert: converting %T to unstructured: %w", expected, err)
+		}
+		act, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil, fmt.Errorf("assert: converting %T to unstructured: %w", obj, err)
+		}
+		var mismatches []Mismatch
+		diffFields("", exp, act, true, &mismatches)
+		return mismatches, nil
+	}
+}
+
+// diffFields appends to mismatches the fields set in expected that differ in actual
+func diffFields(path string, expected, actual interface{}, found bool, mismatches *[]Mismatch) {
+	switch exp := expected.(type) {
+	case nil:
+		return
+	case map[string]interface{}:
+		act, ok := actual.(map[string]interface{})
+		if found && actual != nil && !ok {
+			*mismatches = append(*mismatches, Mismatch{Path: path, Expected: format(expected), Actual: format(actual)})
+			return
+		}
+		keys := make([]string, 0, len(exp))
+		for k := range exp {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			v, ok := act[k]
+			diffFields(fieldPath(path, k), exp[k], v, ok, mismatches)
+		}
+	case []interface{}:
+		act, ok := actual.([]interface{})
+		if found && actual != nil && !ok {
+			*mismatches = append(*mismatches, Mismatch{Path: path, Expected: format(expected), Actual: format(actual)})
+			return
+		}
+		for i, e := range exp {
+			var v interface{}
+			if i < len(act) {
+				v = act[i]
+			}
+			diffFields(fmt.Sprintf("%s[%d]", path, i), e, v, i < len(act), mismatches)
+		}
+	default:
+		if !found {
+			*mismatches = append(*mismatches, Mismatch{Path: path, Expected: format(expected), Actual: missing})
+		} else if !reflect.DeepEqual(expected, actual) {
+			*mismatches = append(*mismatches, Mismatch{Path: path, Expected: format(expected), Actual: format(actual)})
+		}
+	}
+}
+
+// fieldPath appends the key to the path, quoting the keys containing dots (i.e. label keys)
+func fieldPath(path, key string) string {
+	if strings.ContainsAny(key, ".[]") {
+		return fmt.Sprintf("%s[%q]", path, key)
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// format returns the JSON representation of v, as it appears in the manifests
+func format(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
+
+// JSONPath matches the objects whose field, selected by the JSONPath expression, has the
+// expected value, as the ResourceJSONPathMatch condition does. The expression can be
+// provided with or without the surrounding braces.
+func JSONPath(expr, value string) Matcher {
+	return func(obj k8s.Object) ([]Mismatch, error) {
+		template := strings.TrimSpace(expr)
+		if !strings.HasPrefix(template, "{") {
+			template = fmt.Sprintf("{%s}", template)
+		}
+		parser := jsonpath.New("assert").AllowMissingKeys(true)
+		if err := parser.Parse(template); err != nil {
+			return nil, fmt.Errorf("assert: invalid jsonpath expression %s: %w", template, err)
+		}
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil, fmt.Errorf("assert: converting %T to unstructured: %w", obj, err)
+		}
+		results, err := parser.FindResults(content)
+		if err != nil {
+			return nil, fmt.Errorf("assert: jsonpath lookup: %w", err)
+		}
+		var values []string
+		for _, result := range results {
+			for _, r := range result {
+				v := fmt.Sprintf("%v", r.Interface())
+				if v == value {
+					return nil, nil
+				}
+				values = append(values, v)
+			}
+		}
+		actual := missing
+		if len(values) > 0 {
+			actual = strings.Join(values, ",")
+		}
+		return []Mismatch{{Path: expr, Expected: value, Actual: actual}}, nil
+	}
+}
+
+// Func matches the objects for which fn returns true. The mismatch reported
+// when it returns false is described by desc.
+func Func(desc string, fn func(obj k8s.Object) bool) Matcher {
+	return func(obj k8s.Object) ([]Mismatch, error) {
+		if fn(obj) {
+			return nil, nil
+		}
+		return []Mismatch{{Path: desc, Expected: "true", Actual: "false"}}, nil
+	}
+}
+
+// QuotaUsed matches the ResourceQuotas whose status reports the given usage, i.e. once the
+// quota controller accounted for the objects created. The quantities are compared by value,
+// so that 1 and 1000m match. The resources of the quota not listed in used are ignored.
+func QuotaUsed(used v1.ResourceList) Matcher {
+	return func(obj k8s.Object) ([]Mismatch, error) {
+		quota, ok := obj.(*v1.ResourceQuota)
+		if !ok {
+			return nil, fmt.Errorf("assert: expected *v1.ResourceQuota, got %T", obj)
+		}
+		names := make([]string, 0, len(used))
+		for name := range used {
+			names = append(names, string(name))
+		}
+		sort.Strings(names)
+
+		var mismatches []Mismatch
+		for _, name := range names {
+			expected := used[v1.ResourceName(name)]
+			path := fieldPath("status.used", name)
+			actual, ok := quota.Status.Used[v1.ResourceName(name)]
+			if !ok {
+				mismatches = append(mismatches, Mismatch{Path: path, Expected: expected.String(), Actual: missing})
+			} else if actual.Cmp(expected) != 0 {
+				mismatches = append(mismatches, Mismatch{Path: path, Expected: expected.String(), Actual: actual.String()})
+			}
+		}
+		return mismatches, nil
+	}
+}
@@ -38,8 +38,10 @@ type client struct {
 	resources *resources.Resources
 }
 
-// New returns a new Client value
-func New(cfg *rest.Config) (Client, error) {
+// New returns a new Client value. The options are applied to
+// a copy of cfg, which is not modified.
+func New(cfg *rest.Config, opts ...Option) (Client, error) {
+	cfg = applyOptions(cfg, opts)
 	res, err := resources.New(cfg)
 	if err != nil {
 		return nil, err
@@ -48,12 +50,31 @@ func New(cfg *rest.Config) (Client, error) {
 }
 
 // NewWithKubeConfigFile creates a client using the kubeconfig filePath
-func NewWithKubeConfigFile(filePath string) (Client, error) {
+func NewWithKubeConfigFile(filePath string, opts ...Option) (Client, error) {
 	cfg, err := conf.New(filePath)
 	if err != nil {
 		return nil, err
 	}
-	return New(cfg)
+	return New(cfg, opts...)
+}
+
+// NewWithKubeConfigContext creates a client using the kubeconfig filePath
+// and the named context. This is useful when a single kubeconfig file
+// holds the credentials of several clusters.
+func NewWithKubeConfigContext(filePath, context string, opts ...Option) (Client, error) {
+	cfg, err := conf.NewWithContextName(filePath, context)
+	if err != nil {
+		return nil, err
+	}
+	return New(cfg, opts...)
+}
+
+// NewImpersonating returns a client derived from cfg that impersonates
+// the provided user and groups. The original cfg is not modified.
+func NewImpersonating(cfg *rest.Config, user string, groups ...string) (Client, error) {
+	impersonated := rest.CopyConfig(cfg)
+	impersonated.Impersonate = rest.ImpersonationConfig{UserName: user, Groups: groups}
+	return New(impersonated)
 }
 
 // RESTConfig returns the *rest.Config value associated
@@ -0,0 +1,71 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package klient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+)
+
+func TestNewImpersonating(t *testing.T) {
+	var user string
+	var groups []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api":
+			w.Write([]byte(`{"kind":"APIVersions","versions":["v1"]}`))
+		case "/api/v1":
+			w.Write([]byte(`{"kind":"APIResourceList","groupVersion":"v1","resources":[
+				{"name":"configmaps","namespaced":true,"kind":"ConfigMap","verbs":["get"]}]}`))
+		case "/apis":
+			w.Write([]byte(`{"kind":"APIGroupList","groups":[]}`))
+		case "/api/v1/namespaces/ns/configmaps/cm":
+			user, groups = r.Header.Get("Impersonate-User"), r.Header.Values("Impersonate-Group")
+			w.Write([]byte(`{"kind":"ConfigMap","apiVersion":"v1","metadata":{"name":"cm","namespace":"ns"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	cfg := &rest.Config{Host: server.URL}
+	client, err := NewImpersonating(cfg, "system:serviceaccount:ns:sa", "system:serviceaccounts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Impersonate.UserName != "" {
+		t.Errorf("original config modified: %v", cfg.Impersonate)
+	}
+
+	var cm corev1.ConfigMap
+	if err := client.Resources().Get(context.TODO(), "cm", "ns", &cm); err != nil {
+		t.Fatal(err)
+	}
+	if user != "system:serviceaccount:ns:sa" {
+		t.Errorf("unexpected impersonated user: %q", user)
+	}
+	if !reflect.DeepEqual(groups, []string{"system:serviceaccounts"}) {
+		t.Errorf("unexpected impersonated groups: %v", groups)
+	}
+}
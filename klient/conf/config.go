@@ -30,6 +30,10 @@ import (
 // DefaultClusterContext default cluster context
 var DefaultClusterContext = ""
 
+// inClusterTokenFile is the location where the service account
+// token is mounted for processes running inside a pod.
+var inClusterTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
 // New returns Kubernetes configuration value of type *rest.Config.
 // filename is kubeconfig file
 func New(fileName string) (*rest.Config, error) {
@@ -59,20 +63,29 @@ func NewInCluster() (*rest.Config, error) {
 	return rest.InClusterConfig()
 }
 
+// IsInCluster reports whether the current process appears to be running
+// inside a pod on kubernetes. It checks for the service environment variables
+// injected by the kubelet and the mounted service account token.
+func IsInCluster() bool {
+	if os.Getenv("KUBERNETES_SERVICE_HOST") == "" || os.Getenv("KUBERNETES_SERVICE_PORT") == "" {
+		return false
+	}
+	_, err := os.Stat(inClusterTokenFile)
+	return err == nil
+}
+
 // ResolveKubeConfigFile returns the kubeconfig file from
 // either flag --kubeconfig or env KUBECONFIG.
-// If flag.Parsed() is true then lookup for --kubeconfig flag.
+// The --kubeconfig flag is looked up in the global flag.CommandLine, where
+// it is also set by the framework flags parsing.
 // If --kubeconfig, or KUBECONFIG, or  $HOME/.kube/config not provided then
 // assume in cluster.
 func ResolveKubeConfigFile() string {
 	var kubeConfigPath string
 
 	// If a flag --kubeconfig  is specified with the config location, use that
-	if flag.Parsed() {
-		f := flag.Lookup("kubeconfig")
-		if f != nil && f.Value.String() != "" {
-			return f.Value.String()
-		}
+	if f := flag.Lookup("kubeconfig"); f != nil && f.Value.String() != "" {
+		return f.Value.String()
 	}
 
 	// if KUBECONFIG env is defined then use that
@@ -111,11 +124,8 @@ func ResolveKubeConfigFile() string {
 // ResolveClusterContext returns cluster context name based on --context flag.
 func ResolveClusterContext() string {
 	// If a flag --kube-context is specified use that
-	if flag.Parsed() {
-		f := flag.Lookup("kube-context")
-		if f != nil {
-			return f.Value.String()
-		}
+	if f := flag.Lookup("kube-context"); f != nil {
+		return f.Value.String()
 	}
 
 	return ""
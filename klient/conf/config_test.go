@@ -17,6 +17,8 @@ limitations under the License.
 package conf
 
 import (
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -55,3 +57,30 @@ func TestNewWithContextName(t *testing.T) {
 		t.Errorf("client config is nill")
 	}
 }
+
+func TestIsInCluster(t *testing.T) {
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	if IsInCluster() {
+		t.Error("expected not to be in cluster without service env")
+	}
+
+	dir, err := ioutil.TempDir("", "incluster")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tokenFile := filepath.Join(dir, "token")
+	if err := ioutil.WriteFile(tokenFile, []byte("token"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	orig := inClusterTokenFile
+	inClusterTokenFile = tokenFile
+	defer func() { inClusterTokenFile = orig }()
+
+	t.Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")
+	t.Setenv("KUBERNETES_SERVICE_PORT", "443")
+	if !IsInCluster() {
+		t.Error("expected to be in cluster")
+	}
+}
@@ -56,6 +56,8 @@ type MutateFunc func(obj k8s.Object) error
 type HandlerFunc func(ctx context.Context, obj k8s.Object) error
 
 // DecodeEachFile resolves files at the filesystem matching the pattern, decoding JSON or YAML files. Supports multi-document files.
+// The filesystem can be a directory on disk, with os.DirFS, or embedded in the test binary, with embed.FS,
+// so that test binaries shipped as container images carry their manifests.
 //
 // If handlerFn returns an error, decoding is halted.
 // Options may be provided to configure the behavior of the decoder.
@@ -299,6 +301,13 @@ func UpdateHandler(r *resources.Resources, opts ...resources.UpdateOption) Handl
 	}
 }
 
+// ApplyHandler returns a HandlerFunc that will create or update objects using server-side apply
+func ApplyHandler(r *resources.Resources, opts ...resources.PatchOption) HandlerFunc {
+	return func(ctx context.Context, obj k8s.Object) error {
+		return r.Apply(ctx, obj, opts...)
+	}
+}
+
 // DeleteHandler returns a HandlerFunc that will delete objects
 func DeleteHandler(r *resources.Resources, opts ...resources.DeleteOption) HandlerFunc {
 	return func(ctx context.Context, obj k8s.Object) error {
@@ -18,6 +18,8 @@ package decoder
 
 import (
 	"context"
+	"embed"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"testing"
@@ -167,6 +169,35 @@ func TestDecodeEachFile(t *testing.T) {
 	}
 }
 
+//go:embed testdata/examples
+var embeddedExamples embed.FS
+
+func TestDecodeEachFile_Embedded(t *testing.T) {
+	// load `testdata/examples/example-sa*` from the test binary
+	count := 0
+	if err := DecodeEachFile(context.TODO(), embeddedExamples, "testdata/examples/"+serviceAccountPrefix, func(ctx context.Context, obj k8s.Object) error {
+		if _, ok := obj.(*v1.ServiceAccount); !ok {
+			t.Errorf("expected a service account, got %T", obj)
+		}
+		count++
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	} else if expected := 3; count != expected {
+		t.Fatalf("expected %d objects, got: %d", expected, count)
+	}
+	// load `testdata/examples/*` from a sub tree of the embedded filesystem
+	examples, err := fs.Sub(embeddedExamples, "testdata/examples")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if objects, err := DecodeAllFiles(context.TODO(), examples, "*"); err != nil {
+		t.Fatal(err)
+	} else if expected, got := 4, len(objects); got != expected {
+		t.Fatalf("expected %d objects, got: %d", expected, got)
+	}
+}
+
 func TestDecodeAllFiles(t *testing.T) {
 	// load `testdata/examples/example-sa*`
 	testdata := os.DirFS(filepath.Join("testdata", "examples"))
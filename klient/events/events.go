@@ -0,0 +1,186 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package events collects Kubernetes events emitted while a test runs and
+// provides expectations to assert on their count and order, so that the
+// behavior of controllers can be tested rather than only their final state.
+package events
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	log "k8s.io/klog/v2"
+
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+)
+
+// Record is a simplified representation of a Kubernetes event.
+type Record struct {
+	// Type of the event (Normal, Warning)
+	Type string
+	// Reason is the short, machine understandable, reason of the event (i.e. Scheduled)
+	Reason string
+	// Message is the human readable description of the event
+	Message string
+	// Kind, Namespace, and Name identify the object involved in the event
+	Kind      string
+	Namespace string
+	Name      string
+	// Time is the last time the event was observed
+	Time time.Time
+	// Count is the number of times the event was observed
+	Count int32
+}
+
+func (r Record) String() string {
+	return fmt.Sprintf("%s %s %s/%s/%s: %s", r.Type, r.Reason, r.Kind, r.Namespace, r.Name, r.Message)
+}
+
+// NewRecord converts a core/v1 Event into a Record
+func NewRecord(e *v1.Event) Record {
+	t := e.LastTimestamp.Time
+	if t.IsZero() {
+		t = e.EventTime.Time
+	}
+	return Record{
+		Type:      e.Type,
+		Reason:    e.Reason,
+		Message:   e.Message,
+		Kind:      e.InvolvedObject.Kind,
+		Namespace: e.InvolvedObject.Namespace,
+		Name:      e.InvolvedObject.Name,
+		Time:      t,
+		Count:     e.Count,
+	}
+}
+
+// FromEventList converts the items of an EventList into Records
+func FromEventList(list *v1.EventList) []Record {
+	records := make([]Record, 0, len(list.Items))
+	for i := range list.Items {
+		records = append(records, NewRecord(&list.Items[i]))
+	}
+	return records
+}
+
+// InvolvingObject returns a list option selecting the events involving the object,
+// identified by its namespace, name, and, when set, its kind and uid
+func InvolvingObject(obj k8s.Object) resources.ListOption {
+	selectors := []string{
+		fmt.Sprintf("involvedObject.namespace=%s", obj.GetNamespace()),
+		fmt.Sprintf("involvedObject.name=%s", obj.GetName()),
+	}
+	if kind := obj.GetObjectKind().GroupVersionKind().Kind; kind != "" {
+		selectors = append(selectors, fmt.Sprintf("involvedObject.kind=%s", kind))
+	}
+	if uid := obj.GetUID(); uid != "" {
+		selectors = append(selectors, fmt.Sprintf("involvedObject.uid=%s", uid))
+	}
+	return resources.WithFieldSelector(strings.Join(selectors, ","))
+}
+
+// ListFor returns the events involving the object, ordered by the time they were last observed
+func ListFor(ctx context.Context, r *resources.Resources, obj k8s.Object) ([]Record, error) {
+	var list v1.EventList
+	if err := r.List(ctx, &list, InvolvingObject(obj)); err != nil {
+		return nil, fmt.Errorf("events list: %w", err)
+	}
+	records := FromEventList(&list)
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Time.Before(records[j].Time) })
+	return records, nil
+}
+
+// Collector accumulates the events received from a watch, in the order they are first received.
+// An event observed again, i.e. with its count incremented, updates its record in place.
+type Collector struct {
+	w watch.Interface
+
+	mu      sync.Mutex
+	records []Record
+	index   map[types.UID]int
+	done    chan struct{}
+}
+
+// Collect starts watching the events, in the namespace of the resources if set, and accumulates
+// them until Stop is called. The list options can be used to narrow down the collected events,
+// i.e. resources.WithFieldSelector("involvedObject.name=my-pod").
+//
+// The watch starts from the resource version of a list of the events, so that only the events
+// emitted, or observed again, after Collect is called are collected.
+func Collect(ctx context.Context, r *resources.Resources, opts ...resources.ListOption) (*Collector, error) {
+	var list v1.EventList
+	if err := r.List(ctx, &list, opts...); err != nil {
+		return nil, fmt.Errorf("events collect: %w", err)
+	}
+	w, err := r.Watch(ctx, &v1.EventList{}, append(opts, resources.WithResourceVersion(list.ResourceVersion))...)
+	if err != nil {
+		return nil, fmt.Errorf("events collect: %w", err)
+	}
+	return newCollector(w), nil
+}
+
+func newCollector(w watch.Interface) *Collector {
+	c := &Collector{w: w, index: make(map[types.UID]int), done: make(chan struct{})}
+	go c.run()
+	return c
+}
+
+func (c *Collector) run() {
+	defer close(c.done)
+	for event := range c.w.ResultChan() {
+		e, ok := event.Object.(*v1.Event)
+		if !ok {
+			log.V(4).Infof("events collector: ignoring unexpected object %T", event.Object)
+			continue
+		}
+		if event.Type != watch.Added && event.Type != watch.Modified {
+			continue
+		}
+		c.mu.Lock()
+		if i, ok := c.index[e.UID]; ok {
+			c.records[i] = NewRecord(e)
+		} else {
+			c.index[e.UID] = len(c.records)
+			c.records = append(c.records, NewRecord(e))
+		}
+		c.mu.Unlock()
+	}
+}
+
+// Records returns a copy of the events collected so far
+func (c *Collector) Records() []Record {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	records := make([]Record, len(c.records))
+	copy(records, c.records)
+	return records
+}
+
+// Stop stops collecting events and returns the collected events
+func (c *Collector) Stop() []Record {
+	c.w.Stop()
+	<-c.done
+	return c.Records()
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+func TestInvolvingObject(t *testing.T) {
+	tests := []struct {
+		name     string
+		pod      *v1.Pod
+		selector string
+	}{
+		{
+			name:     "name only",
+			pod:      &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "p1"}},
+			selector: "involvedObject.namespace=ns,involvedObject.name=p1",
+		},
+		{
+			name: "kind and uid",
+			pod: &v1.Pod{
+				TypeMeta:   metav1.TypeMeta{Kind: "Pod", APIVersion: "v1"},
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "p1", UID: "1234"},
+			},
+			selector: "involvedObject.namespace=ns,involvedObject.name=p1,involvedObject.kind=Pod,involvedObject.uid=1234",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var opts metav1.ListOptions
+			InvolvingObject(test.pod)(&opts)
+			if opts.FieldSelector != test.selector {
+				t.Errorf("expected field selector %q, got %q", test.selector, opts.FieldSelector)
+			}
+		})
+	}
+}
+
+func TestCollector(t *testing.T) {
+	event := func(uid types.UID, reason string, count int32) *v1.Event {
+		return &v1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Namespace: "ns", Name: string(uid), UID: uid},
+			InvolvedObject: v1.ObjectReference{Kind: "Pod", Namespace: "ns", Name: "p1"},
+			Type:           v1.EventTypeNormal,
+			Reason:         reason,
+			Count:          count,
+		}
+	}
+
+	w := watch.NewFake()
+	c := newCollector(w)
+	w.Add(event("1", "Scheduled", 1))
+	w.Add(event("2", "BackOff", 1))
+	w.Modify(event("2", "BackOff", 3))
+	w.Delete(event("1", "Scheduled", 1))
+	records := c.Stop()
+
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d: %v", len(records), records)
+	}
+	if records[0].Reason != "Scheduled" || records[0].Count != 1 {
+		t.Errorf("unexpected first record: %v (count %d)", records[0], records[0].Count)
+	}
+	if records[1].Reason != "BackOff" || records[1].Count != 3 {
+		t.Errorf("expected modified event to be updated in place, got %v (count %d)", records[1], records[1].Count)
+	}
+}
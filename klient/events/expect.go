@@ -0,0 +1,178 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Matcher selects the records an expectation applies to.
+type Matcher struct {
+	// Desc describes the matcher in failure messages
+	Desc  string
+	Match func(Record) bool
+}
+
+// WithReason matches records with the given reason
+func WithReason(reason string) Matcher {
+	return Matcher{Desc: fmt.Sprintf("reason=%s", reason), Match: func(r Record) bool { return r.Reason == reason }}
+}
+
+// WithType matches records with the given type (Normal, Warning)
+func WithType(eventType string) Matcher {
+	return Matcher{Desc: fmt.Sprintf("type=%s", eventType), Match: func(r Record) bool { return r.Type == eventType }}
+}
+
+// WithObject matches records involving the object of the given kind and name
+func WithObject(kind, name string) Matcher {
+	return Matcher{
+		Desc:  fmt.Sprintf("object=%s/%s", kind, name),
+		Match: func(r Record) bool { return r.Kind == kind && r.Name == name },
+	}
+}
+
+// WithMessageContaining matches records whose message contains substr
+func WithMessageContaining(substr string) Matcher {
+	return Matcher{
+		Desc:  fmt.Sprintf("message~%q", substr),
+		Match: func(r Record) bool { return strings.Contains(r.Message, substr) },
+	}
+}
+
+// WithMessageRegex matches records whose message matches the regular
+// expression. It panics if the expression cannot be compiled.
+func WithMessageRegex(expr string) Matcher {
+	re := regexp.MustCompile(expr)
+	return Matcher{
+		Desc:  fmt.Sprintf("message=~%q", expr),
+		Match: func(r Record) bool { return re.MatchString(r.Message) },
+	}
+}
+
+// All matches records matching all the provided matchers
+func All(matchers ...Matcher) Matcher {
+	descs := make([]string, 0, len(matchers))
+	for _, m := range matchers {
+		descs = append(descs, m.Desc)
+	}
+	return Matcher{
+		Desc: strings.Join(descs, ","),
+		Match: func(r Record) bool {
+			for _, m := range matchers {
+				if !m.Match(r) {
+					return false
+				}
+			}
+			return true
+		},
+	}
+}
+
+// Expectation checks the collected records and returns a descriptive
+// error when they do not satisfy it.
+type Expectation func([]Record) error
+
+// InOrder expects records matching each of the matchers to appear in the given
+// order. Other records may appear in between.
+func InOrder(matchers ...Matcher) Expectation {
+	return func(records []Record) error {
+		next := 0
+		for _, r := range records {
+			if next < len(matchers) && matchers[next].Match(r) {
+				next++
+			}
+		}
+		if next == len(matchers) {
+			return nil
+		}
+		var sb strings.Builder
+		sb.WriteString("expected events in order:\n")
+		for i, m := range matchers {
+			mark := "+"
+			if i >= next {
+				mark = "-"
+			}
+			fmt.Fprintf(&sb, "  %s [%d] %s\n", mark, i, m.Desc)
+		}
+		fmt.Fprintf(&sb, "first missing: [%d] %s", next, matchers[next].Desc)
+		return fmt.Errorf("%s", sb.String())
+	}
+}
+
+// Exactly expects exactly n occurrences of the records matching m. A record
+// counts for the number of times the event was observed, see Record.Count.
+func Exactly(n int, m Matcher) Expectation {
+	return count(m, fmt.Sprintf("exactly %d", n), func(found int) bool { return found == n })
+}
+
+// AtLeast expects n or more occurrences of the records matching m
+func AtLeast(n int, m Matcher) Expectation {
+	return count(m, fmt.Sprintf("at least %d", n), func(found int) bool { return found >= n })
+}
+
+// None expects no record matching m
+func None(m Matcher) Expectation {
+	return Exactly(0, m)
+}
+
+func count(m Matcher, desc string, ok func(int) bool) Expectation {
+	return func(records []Record) error {
+		found := 0
+		for _, r := range records {
+			if m.Match(r) {
+				found += occurrences(r)
+			}
+		}
+		if ok(found) {
+			return nil
+		}
+		return fmt.Errorf("expected %s event(s) matching %s, found %d", desc, m.Desc, found)
+	}
+}
+
+// occurrences returns the number of times the event of r was observed. Events
+// recorded without a count, i.e. by the events.k8s.io API, were observed once.
+func occurrences(r Record) int {
+	if r.Count <= 0 {
+		return 1
+	}
+	return int(r.Count)
+}
+
+// Verify checks the records against all the expectations. The returned error lists
+// every unmet expectation followed by the observed records.
+func Verify(records []Record, expectations ...Expectation) error {
+	var failures []string
+	for _, expect := range expectations {
+		if err := expect(records); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(strings.Join(failures, "\n"))
+	fmt.Fprintf(&sb, "\nobserved %d event(s):", len(records))
+	for i, r := range records {
+		fmt.Fprintf(&sb, "\n  [%d] %s", i, r)
+	}
+	return fmt.Errorf("%s", sb.String())
+}
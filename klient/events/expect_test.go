@@ -0,0 +1,108 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	records := []Record{
+		{Type: "Normal", Reason: "Scheduled", Kind: "Pod", Name: "p1", Message: "Successfully assigned default/p1 to node-1"},
+		{Type: "Normal", Reason: "Pulled", Kind: "Pod", Name: "p1"},
+		{Type: "Normal", Reason: "Created", Kind: "Pod", Name: "p1"},
+		{Type: "Normal", Reason: "Started", Kind: "Pod", Name: "p1"},
+		{Type: "Normal", Reason: "ScalingReplicaSet", Kind: "Deployment", Name: "d1"},
+		{Type: "Warning", Reason: "BackOff", Kind: "Pod", Name: "p2", Count: 3},
+	}
+
+	tests := []struct {
+		name         string
+		expectations []Expectation
+		failures     []string
+	}{
+		{
+			name: "in order",
+			expectations: []Expectation{
+				InOrder(WithReason("Scheduled"), WithReason("Created"), WithReason("Started")),
+			},
+		},
+		{
+			name: "out of order",
+			expectations: []Expectation{
+				InOrder(WithReason("Started"), WithReason("Created")),
+			},
+			failures: []string{"first missing: [1] reason=Created"},
+		},
+		{
+			name: "counts",
+			expectations: []Expectation{
+				Exactly(1, All(WithReason("ScalingReplicaSet"), WithObject("Deployment", "d1"))),
+				AtLeast(4, WithObject("Pod", "p1")),
+				None(All(WithType("Warning"), WithObject("Pod", "p1"))),
+				Exactly(1, WithMessageRegex(`assigned \S+/p1 to`)),
+			},
+		},
+		{
+			name: "repeated event",
+			expectations: []Expectation{
+				Exactly(3, WithReason("BackOff")),
+				AtLeast(2, WithObject("Pod", "p2")),
+			},
+		},
+		{
+			name: "unmet repeated event",
+			expectations: []Expectation{
+				Exactly(1, WithReason("BackOff")),
+			},
+			failures: []string{"expected exactly 1 event(s) matching reason=BackOff, found 3"},
+		},
+		{
+			name: "unmet counts",
+			expectations: []Expectation{
+				Exactly(2, WithReason("Scheduled")),
+				None(WithObject("Pod", "p1")),
+			},
+			failures: []string{
+				"expected exactly 2 event(s) matching reason=Scheduled, found 1",
+				"expected exactly 0 event(s) matching object=Pod/p1, found 4",
+				"observed 6 event(s)",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := Verify(records, test.expectations...)
+			if len(test.failures) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected failure: %s", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected failure")
+			}
+			for _, f := range test.failures {
+				if !strings.Contains(err.Error(), f) {
+					t.Errorf("expected failure to contain %q, got:\n%s", f, err)
+				}
+			}
+		})
+	}
+}
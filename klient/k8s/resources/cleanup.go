@@ -0,0 +1,136 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	log "k8s.io/klog/v2"
+
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+)
+
+type cleanupContextKey struct{}
+
+// cleanupRegistry holds the objects to delete when cleaning up. It is stored
+// in the context as a pointer so that objects registered in a step are visible
+// to later steps even when the step does not return its context (i.e. on t.Fatal).
+type cleanupRegistry struct {
+	mu   sync.Mutex
+	objs []k8s.Object
+	// tracked are all the objects ever registered, used to verify the cleanup
+	tracked []k8s.Object
+}
+
+// WithCleanup returns a context with a new, empty, cleanup registry
+func WithCleanup(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cleanupContextKey{}, &cleanupRegistry{})
+}
+
+// RegisterCleanup adds the objects to the cleanup registry of the context, created by
+// WithCleanup, so that they are deleted by Cleanup. Returns an error if the context
+// has no cleanup registry.
+func RegisterCleanup(ctx context.Context, objs ...k8s.Object) error {
+	registry, ok := ctx.Value(cleanupContextKey{}).(*cleanupRegistry)
+	if !ok {
+		return errors.New("resources: no cleanup registry in context, use WithCleanup")
+	}
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.objs = append(registry.objs, objs...)
+	registry.tracked = append(registry.tracked, objs...)
+	return nil
+}
+
+// Cleanup deletes the objects of the cleanup registry of the context, in the reverse
+// order of their registration, ignoring objects already deleted. All the objects are
+// attempted and the registry is emptied, the returned error lists the failed deletions.
+func Cleanup(ctx context.Context, r *Resources, opts ...DeleteOption) error {
+	registry, ok := ctx.Value(cleanupContextKey{}).(*cleanupRegistry)
+	if !ok {
+		return nil
+	}
+	registry.mu.Lock()
+	objs := registry.objs
+	registry.objs = nil
+	registry.mu.Unlock()
+
+	var failed []string
+	for i := len(objs) - 1; i >= 0; i-- {
+		obj := objs[i]
+		log.V(4).Infof("Cleaning up %T %s/%s", obj, obj.GetNamespace(), obj.GetName())
+		if err := r.Delete(ctx, obj, opts...); err != nil && !apierrors.IsNotFound(err) {
+			failed = append(failed, fmt.Sprintf("%s/%s: %s", obj.GetNamespace(), obj.GetName(), err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("resources cleanup: %d deletion(s) failed: %v", len(failed), failed)
+	}
+	return nil
+}
+
+// VerifyCleanup waits, up to timeout, for all the objects ever registered in the cleanup
+// registry of the context to be gone, whether deleted by Cleanup or by the teardown steps.
+// The returned error lists the objects still present once the timeout expires.
+func VerifyCleanup(ctx context.Context, r *Resources, timeout time.Duration) error {
+	registry, ok := ctx.Value(cleanupContextKey{}).(*cleanupRegistry)
+	if !ok {
+		return nil
+	}
+	registry.mu.Lock()
+	remaining := append([]k8s.Object(nil), registry.tracked...)
+	registry.mu.Unlock()
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var lastErr error
+	err := wait.PollImmediateUntil(time.Second, func() (bool, error) {
+		var present []k8s.Object
+		for _, obj := range remaining {
+			// get into a copy to keep the registered object unchanged
+			err := r.Get(waitCtx, obj.GetName(), obj.GetNamespace(), obj.DeepCopyObject().(k8s.Object))
+			switch {
+			case apierrors.IsNotFound(err):
+			case err != nil:
+				lastErr = err
+				present = append(present, obj)
+			default:
+				present = append(present, obj)
+			}
+		}
+		remaining = present
+		return len(remaining) == 0, nil
+	}, waitCtx.Done())
+	if err == nil {
+		return nil
+	}
+
+	stragglers := make([]string, 0, len(remaining))
+	for _, obj := range remaining {
+		stragglers = append(stragglers, fmt.Sprintf("%T %s/%s", obj, obj.GetNamespace(), obj.GetName()))
+	}
+	if lastErr != nil {
+		return fmt.Errorf("resources cleanup: %d object(s) not deleted after %s: %v: last error: %w", len(remaining), timeout, stragglers, lastErr)
+	}
+	return fmt.Errorf("resources cleanup: %d object(s) not deleted after %s: %v", len(remaining), timeout, stragglers)
+}
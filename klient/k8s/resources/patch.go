@@ -0,0 +1,115 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/types"
+	cr "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+)
+
+// NewMergePatch returns a JSON merge patch (RFC 7386) of the data marshaled
+// to JSON, i.e. a map or a partial object. Lists in the patch replace the
+// lists of the object.
+func NewMergePatch(data interface{}) (k8s.Patch, error) {
+	return newPatch(types.MergePatchType, data)
+}
+
+// NewStrategicMergePatch returns a strategic merge patch of the data marshaled
+// to JSON. Unlike merge patches, lists of built-in types such as the containers
+// of a pod are merged. Strategic merge patches are not supported by custom resources.
+func NewStrategicMergePatch(data interface{}) (k8s.Patch, error) {
+	return newPatch(types.StrategicMergePatchType, data)
+}
+
+// JSONPatchOp is an operation of a JSON patch (RFC 6902)
+type JSONPatchOp struct {
+	// Op is the operation: add, remove, replace, move, copy, or test
+	Op string `json:"op"`
+	// Path is the JSON pointer to the target location, i.e. /spec/replicas
+	Path string `json:"path"`
+	// Value is the value of add, replace, and test operations
+	Value interface{} `json:"value,omitempty"`
+	// From is the source location of move and copy operations
+	From string `json:"from,omitempty"`
+}
+
+// NewJSONPatch returns a JSON patch applying the operations in order. A test
+// operation can be used to make the patch fail if the object was changed:
+//
+//	resources.NewJSONPatch(
+//		resources.JSONPatchOp{Op: "test", Path: "/spec/replicas", Value: 1},
+//		resources.JSONPatchOp{Op: "replace", Path: "/spec/replicas", Value: 3},
+//	)
+func NewJSONPatch(ops ...JSONPatchOp) (k8s.Patch, error) {
+	return newPatch(types.JSONPatchType, ops)
+}
+
+func newPatch(patchType types.PatchType, data interface{}) (k8s.Patch, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return k8s.Patch{}, fmt.Errorf("resources patch: %w", err)
+	}
+	return k8s.Patch{PatchType: patchType, Data: raw}, nil
+}
+
+// MergePatchFrom returns the JSON merge patch of the changes from original to modified
+func MergePatchFrom(original, modified k8s.Object) (k8s.Patch, error) {
+	return patchFrom(cr.MergeFrom(original), modified)
+}
+
+// StrategicMergePatchFrom returns the strategic merge patch of the changes
+// from original to modified, which must be objects of built-in types.
+func StrategicMergePatchFrom(original, modified k8s.Object) (k8s.Patch, error) {
+	return patchFrom(cr.StrategicMergeFrom(original), modified)
+}
+
+func patchFrom(p cr.Patch, modified k8s.Object) (k8s.Patch, error) {
+	data, err := p.Data(modified)
+	if err != nil {
+		return k8s.Patch{}, fmt.Errorf("resources patch: %w", err)
+	}
+	return k8s.Patch{PatchType: p.Type(), Data: data}, nil
+}
+
+// PatchWithMutation applies the changes made to obj by mutate with a JSON merge patch. Since
+// the patch only includes the changed fields, and no resource version, it replaces the loops
+// updating an object and retrying on conflicts. Once patched, obj holds the updated object:
+//
+//	err := r.PatchWithMutation(ctx, dep, func() error {
+//		dep.Spec.Replicas = &replicas
+//		return nil
+//	}, resources.WithFieldManager("my-test"))
+func (r *Resources) PatchWithMutation(ctx context.Context, obj k8s.Object, mutate func() error, opts ...PatchOption) error {
+	original, ok := obj.DeepCopyObject().(k8s.Object)
+	if !ok {
+		return fmt.Errorf("resources patch: unexpected copy of %T", obj)
+	}
+	if err := mutate(); err != nil {
+		return err
+	}
+	patch, err := MergePatchFrom(original, obj)
+	if err != nil {
+		return err
+	}
+	return r.Patch(ctx, obj, patch, opts...)
+}
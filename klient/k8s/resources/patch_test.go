@@ -0,0 +1,75 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestNewPatches(t *testing.T) {
+	merge, err := NewMergePatch(map[string]interface{}{"metadata": map[string]interface{}{"labels": map[string]string{"app": "web"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merge.PatchType != types.MergePatchType || string(merge.Data) != `{"metadata":{"labels":{"app":"web"}}}` {
+		t.Errorf("unexpected merge patch: %s %s", merge.PatchType, merge.Data)
+	}
+
+	jsonPatch, err := NewJSONPatch(
+		JSONPatchOp{Op: "test", Path: "/spec/replicas", Value: 1},
+		JSONPatchOp{Op: "move", Path: "/metadata/labels/tier", From: "/metadata/labels/app"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `[{"op":"test","path":"/spec/replicas","value":1},{"op":"move","path":"/metadata/labels/tier","from":"/metadata/labels/app"}]`
+	if jsonPatch.PatchType != types.JSONPatchType || string(jsonPatch.Data) != expected {
+		t.Errorf("unexpected JSON patch: %s %s", jsonPatch.PatchType, jsonPatch.Data)
+	}
+}
+
+func TestPatchFrom(t *testing.T) {
+	original := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "p1", ResourceVersion: "42"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: "nginx:1.20"}}},
+	}
+	modified := original.DeepCopy()
+	modified.Labels = map[string]string{"app": "web"}
+	modified.Spec.Containers[0].Image = "nginx:1.21"
+
+	merge, err := MergePatchFrom(original, modified)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"metadata":{"labels":{"app":"web"}},"spec":{"containers":[{"image":"nginx:1.21","name":"app","resources":{}}]}}`
+	if merge.PatchType != types.MergePatchType || string(merge.Data) != expected {
+		t.Errorf("unexpected merge patch: %s %s", merge.PatchType, merge.Data)
+	}
+
+	strategic, err := StrategicMergePatchFrom(original, modified)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = `{"metadata":{"labels":{"app":"web"}},"spec":{"$setElementOrder/containers":[{"name":"app"}],"containers":[{"image":"nginx:1.21","name":"app"}]}}`
+	if strategic.PatchType != types.StrategicMergePatchType || string(strategic.Data) != expected {
+		t.Errorf("unexpected strategic merge patch: %s %s", strategic.PatchType, strategic.Data)
+	}
+}
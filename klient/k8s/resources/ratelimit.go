@@ -0,0 +1,63 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+type rateLimiterContextKey struct{}
+
+// WithRateLimiter returns a context overriding the rate limiter of the resources
+// for the create, apply, and delete operations made with the context. A nil limiter
+// removes the override so that the limiter of the resources, if any, is used.
+func WithRateLimiter(ctx context.Context, limiter flowcontrol.RateLimiter) context.Context {
+	return context.WithValue(ctx, rateLimiterContextKey{}, limiter)
+}
+
+// RateLimiterFrom returns the rate limiter set in the context with WithRateLimiter, if any
+func RateLimiterFrom(ctx context.Context) flowcontrol.RateLimiter {
+	limiter, _ := ctx.Value(rateLimiterContextKey{}).(flowcontrol.RateLimiter)
+	return limiter
+}
+
+// WithRateLimiter sets the rate limiter used to pace the create, apply, and delete operations,
+// i.e. flowcontrol.NewTokenBucketRateLimiter(qps, burst). Unlike the rate limiting of
+// the rest.Config, it only applies to the operations changing the cluster state, to
+// protect shared clusters enforcing admission rate limits or to mimic production rollouts.
+func (r *Resources) WithRateLimiter(limiter flowcontrol.RateLimiter) *Resources {
+	r.limiter = limiter
+	return r
+}
+
+// wait blocks until the rate limiter of the context, or of the resources, allows an operation
+func (r *Resources) wait(ctx context.Context) error {
+	limiter := RateLimiterFrom(ctx)
+	if limiter == nil {
+		limiter = r.limiter
+	}
+	if limiter == nil {
+		return nil
+	}
+	if err := limiter.Wait(ctx); err != nil {
+		return fmt.Errorf("resources rate limiter: %w", err)
+	}
+	return nil
+}
@@ -22,14 +22,20 @@ import (
 	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/flowcontrol"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
 	cr "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/e2e-framework/klient/k8s"
 )
 
+// DefaultFieldManager is the field manager used by Apply when none is provided
+const DefaultFieldManager = "e2e-framework"
+
 type Resources struct {
 	// config is the rest.Config to talk to an apiserver
 	config *rest.Config
@@ -38,10 +44,13 @@ type Resources struct {
 	scheme *runtime.Scheme
 
 	// client is a wrapper for controller runtime client
-	client cr.Client
+	client cr.WithWatch
 
 	// namespace for namespaced object requests
 	namespace string
+
+	// limiter paces the create, apply, and delete operations
+	limiter flowcontrol.RateLimiter
 }
 
 // New instantiates the controller runtime client
@@ -53,7 +62,7 @@ func New(cfg *rest.Config) (*Resources, error) {
 		return nil, errors.New("must provide rest.Config")
 	}
 
-	cl, err := cr.New(cfg, cr.Options{Scheme: scheme.Scheme})
+	cl, err := cr.NewWithWatch(cfg, cr.Options{Scheme: scheme.Scheme})
 	if err != nil {
 		return nil, err
 	}
@@ -67,6 +76,42 @@ func New(cfg *rest.Config) (*Resources, error) {
 	return res, nil
 }
 
+// GetScheme returns the scheme used to map go types to GroupVersionKinds
+func (r *Resources) GetScheme() *runtime.Scheme {
+	return r.scheme
+}
+
+// WithScheme returns a copy of the resources that uses the provided scheme to map
+// go types to GroupVersionKinds. This allows working with typed custom resources
+// without registering their types with the global client-go scheme.
+func (r *Resources) WithScheme(s *runtime.Scheme) (*Resources, error) {
+	if s == nil {
+		return nil, errors.New("must provide scheme")
+	}
+	cl, err := cr.NewWithWatch(r.config, cr.Options{Scheme: s, Mapper: r.client.RESTMapper()})
+	if err != nil {
+		return nil, err
+	}
+	return &Resources{config: r.config, scheme: s, client: cl, namespace: r.namespace, limiter: r.limiter}, nil
+}
+
+// RegisterCRD registers the types of custom resources with the scheme used by
+// the resources, so that custom resources can be created and retrieved using their
+// typed go structs. It accepts the AddToScheme functions generated for API packages:
+//
+//   err := res.RegisterCRD(myapiv1.AddToScheme)
+//
+// Since the resources use the global client-go scheme by default, use WithScheme
+// first to avoid registering the types globally.
+func (r *Resources) RegisterCRD(addToSchemeFuncs ...func(*runtime.Scheme) error) error {
+	for _, fn := range addToSchemeFuncs {
+		if err := fn(r.scheme); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (r *Resources) WithNamespace(ns string) *Resources {
 	r.namespace = ns
 	return r
@@ -84,6 +129,10 @@ func (r *Resources) Create(ctx context.Context, obj k8s.Object, opts ...CreateOp
 		fn(createOptions)
 	}
 
+	if err := r.wait(ctx); err != nil {
+		return err
+	}
+
 	o := &cr.CreateOptions{Raw: createOptions}
 
 	return r.client.Create(ctx, obj, o)
@@ -109,6 +158,10 @@ func (r *Resources) Delete(ctx context.Context, obj k8s.Object, opts ...DeleteOp
 		fn(deleteOptions)
 	}
 
+	if err := r.wait(ctx); err != nil {
+		return err
+	}
+
 	o := &cr.DeleteOptions{Raw: deleteOptions}
 	return r.client.Delete(ctx, obj, o)
 }
@@ -148,11 +201,35 @@ func WithFieldSelector(sel string) ListOption {
 	return func(lo *metav1.ListOptions) { lo.FieldSelector = sel }
 }
 
+// WithResourceVersion sets the resource version of a list, or the resource
+// version a watch starts from, i.e. the one returned by a previous list
+func WithResourceVersion(rv string) ListOption {
+	return func(lo *metav1.ListOptions) { lo.ResourceVersion = rv }
+}
+
 func WithTimeout(to time.Duration) ListOption {
 	t := to.Milliseconds()
 	return func(lo *metav1.ListOptions) { lo.TimeoutSeconds = &t }
 }
 
+// Watch starts a watch on the objects of the list type, in the namespace of the
+// resources if set. The list options can be used to narrow down the watched objects.
+// The caller is responsible for stopping the returned watch.Interface.
+func (r *Resources) Watch(ctx context.Context, objs k8s.ObjectList, opts ...ListOption) (watch.Interface, error) {
+	listOptions := &metav1.ListOptions{}
+
+	for _, fn := range opts {
+		fn(listOptions)
+	}
+
+	o := &cr.ListOptions{Raw: listOptions}
+	if r.namespace != "" {
+		o.Namespace = r.namespace
+	}
+
+	return r.client.Watch(ctx, objs, o)
+}
+
 // PatchOption is used to provide additional arguments to the Patch call.
 type PatchOption func(*metav1.PatchOptions)
 
@@ -170,6 +247,53 @@ func (r *Resources) Patch(ctx context.Context, objs k8s.Object, patch k8s.Patch,
 	return r.client.Patch(ctx, objs, p, o)
 }
 
+// WithFieldManager sets the name of the actor making the changes
+func WithFieldManager(name string) PatchOption {
+	return func(po *metav1.PatchOptions) { po.FieldManager = name }
+}
+
+// WithForce sets whether conflicts with other field managers
+// are overridden when applying an object
+func WithForce(force bool) PatchOption {
+	return func(po *metav1.PatchOptions) { po.Force = &force }
+}
+
+// Apply creates or updates the object using server-side apply, the equivalent of
+// `kubectl apply --server-side`. Unless specified with the options, the changes are
+// made by DefaultFieldManager. The apply fails on a conflict with the fields owned by
+// other field managers, unless forced with WithForce(true) to take their ownership.
+// As with Create and Update, obj is updated with the object returned by the server.
+func (r *Resources) Apply(ctx context.Context, obj k8s.Object, opts ...PatchOption) error {
+	patchOptions := &metav1.PatchOptions{FieldManager: DefaultFieldManager}
+	for _, fn := range opts {
+		fn(patchOptions)
+	}
+
+	// the apply patch is the serialized object which must include its type
+	if obj.GetObjectKind().GroupVersionKind().Empty() {
+		gvk, err := apiutil.GVKForObject(obj, r.scheme)
+		if err != nil {
+			return err
+		}
+		obj.GetObjectKind().SetGroupVersionKind(gvk)
+	}
+	// managed fields and resource version are not allowed in apply patches
+	obj.SetManagedFields(nil)
+	obj.SetResourceVersion("")
+
+	if err := r.wait(ctx); err != nil {
+		return err
+	}
+
+	o := &cr.PatchOptions{
+		Raw:          patchOptions,
+		DryRun:       patchOptions.DryRun,
+		Force:        patchOptions.Force,
+		FieldManager: patchOptions.FieldManager,
+	}
+	return r.client.Patch(ctx, obj, cr.Apply, o)
+}
+
 // Annotate attach annotations to an existing resource objec
 func (r *Resources) Annotate(obj k8s.Object, annotation map[string]string) {
 	obj.SetAnnotations(annotation)
@@ -20,14 +20,20 @@ import (
 	"context"
 	"encoding/json"
 	"testing"
+	"time"
 
 	log "k8s.io/klog/v2"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/flowcontrol"
 	"sigs.k8s.io/e2e-framework/klient/k8s"
 )
 
@@ -54,6 +60,40 @@ func TestCreate(t *testing.T) {
 	}
 }
 
+func TestWithSchemeAndRegisterCRD(t *testing.T) {
+	res, err := New(cfg)
+	if err != nil {
+		t.Fatalf("Error creating new resources object: %v", err)
+	}
+
+	s := runtime.NewScheme()
+	if err := scheme.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	scoped, err := res.WithScheme(s)
+	if err != nil {
+		t.Fatalf("Error creating resources with scheme: %v", err)
+	}
+	if scoped.GetScheme() != s {
+		t.Error("expected resources to use the provided scheme")
+	}
+
+	gv := schema.GroupVersion{Group: "e2e.example.com", Version: "v1"}
+	err = scoped.RegisterCRD(func(s *runtime.Scheme) error {
+		s.AddKnownTypeWithName(gv.WithKind("ConfigMapLike"), &corev1.ConfigMap{})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Error registering types: %v", err)
+	}
+	if !s.Recognizes(gv.WithKind("ConfigMapLike")) {
+		t.Error("expected custom kind to be registered with the scoped scheme")
+	}
+	if scheme.Scheme.Recognizes(gv.WithKind("ConfigMapLike")) {
+		t.Error("expected custom kind not to be registered with the global scheme")
+	}
+}
+
 func TestRes(t *testing.T) {
 	res, err := New(cfg)
 	if err != nil {
@@ -237,3 +277,142 @@ func TestListAllPods(t *testing.T) {
 
 	t.Logf("pod list contains %d pods", len(pods.Items))
 }
+
+func TestApply(t *testing.T) {
+	res, err := New(cfg)
+	if err != nil {
+		t.Fatalf("Error creating new resources object: %v", err)
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "applied-config", Namespace: namespace.Name},
+		Data:       map[string]string{"key": "value"},
+	}
+	if err := res.Apply(context.Background(), cm); err != nil {
+		t.Fatal("error while applying the configmap", err)
+	}
+	if cm.ResourceVersion == "" {
+		t.Error("expected the configmap to be updated with the applied object")
+	}
+
+	cm = &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "applied-config", Namespace: namespace.Name},
+		Data:       map[string]string{"key": "updated"},
+	}
+	if err := res.Apply(context.Background(), cm, WithFieldManager("other-manager")); !apierrors.IsConflict(err) {
+		t.Fatalf("expected a conflict with the fields of the first manager, got %v", err)
+	}
+	if err := res.Apply(context.Background(), cm, WithFieldManager("other-manager"), WithForce(true)); err != nil {
+		t.Fatal("error while re-applying the configmap", err)
+	}
+
+	obj := &corev1.ConfigMap{}
+	if err := res.Get(context.Background(), cm.Name, cm.Namespace, obj); err != nil {
+		t.Fatal("error while getting applied configmap", err)
+	}
+	if obj.Data["key"] != "updated" {
+		t.Errorf("expected applied value to be updated, got %q", obj.Data["key"])
+	}
+}
+
+func TestCleanup(t *testing.T) {
+	res, err := New(cfg)
+	if err != nil {
+		t.Fatalf("Error creating new resources object: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := RegisterCleanup(ctx, &corev1.ConfigMap{}); err == nil {
+		t.Error("expected registration without a cleanup registry to fail")
+	}
+
+	ctx = WithCleanup(ctx)
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cleanup-config", Namespace: namespace.Name}}
+	if err := res.Create(ctx, cm); err != nil {
+		t.Fatal("error while creating the configmap", err)
+	}
+	// registering an object twice must not fail the cleanup
+	if err := RegisterCleanup(ctx, cm, cm); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Cleanup(ctx, res); err != nil {
+		t.Fatal(err)
+	}
+
+	err = res.Get(ctx, cm.Name, cm.Namespace, &corev1.ConfigMap{})
+	if err == nil {
+		t.Error("expected configmap to be deleted by the cleanup")
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	res, err := New(cfg)
+	if err != nil {
+		t.Fatalf("Error creating new resources object: %v", err)
+	}
+	res.WithRateLimiter(flowcontrol.NewFakeNeverRateLimiter())
+
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "rate-limited-config", Namespace: namespace.Name}}
+	if err := res.Create(context.TODO(), cm); err == nil {
+		t.Fatal("expected create to be rejected by the rate limiter")
+	}
+
+	// the limiter of the context overrides the limiter of the resources
+	ctx := WithRateLimiter(context.TODO(), flowcontrol.NewFakeAlwaysRateLimiter())
+	if err := res.Create(ctx, cm); err != nil {
+		t.Fatal("error while creating the configmap", err)
+	}
+	if err := res.Delete(ctx, cm); err != nil {
+		t.Fatal("error while deleting the configmap", err)
+	}
+}
+
+func TestVerifyCleanup(t *testing.T) {
+	res, err := New(cfg)
+	if err != nil {
+		t.Fatalf("Error creating new resources object: %v", err)
+	}
+
+	ctx := WithCleanup(context.Background())
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "verify-cleanup-config", Namespace: namespace.Name}}
+	if err := res.Create(ctx, cm); err != nil {
+		t.Fatal("error while creating the configmap", err)
+	}
+	if err := RegisterCleanup(ctx, cm); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := VerifyCleanup(ctx, res, 2*time.Second); err == nil {
+		t.Error("expected verification to report the leaked configmap")
+	}
+
+	if err := Cleanup(ctx, res); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyCleanup(ctx, res, 10*time.Second); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPatchWithMutation(t *testing.T) {
+	res, err := New(cfg)
+	if err != nil {
+		t.Fatalf("Error creating new resources object: %v", err)
+	}
+
+	obj := &appsv1.Deployment{}
+	if err := res.Get(context.TODO(), dep.Name, dep.Namespace, obj); err != nil {
+		t.Fatal("error while getting the deployment", err)
+	}
+	err = res.PatchWithMutation(context.TODO(), obj, func() error {
+		obj.Annotations = map[string]string{"mutated": "true"}
+		return nil
+	}, WithFieldManager("mutation-test"))
+	if err != nil {
+		t.Fatal("error while patching the deployment", err)
+	}
+	if obj.Annotations["mutated"] != "true" {
+		t.Error("expected the patched deployment to be returned")
+	}
+}
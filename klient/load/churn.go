@@ -0,0 +1,239 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package load
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	log "k8s.io/klog/v2"
+
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+)
+
+const (
+	OpCreate = "create"
+	OpUpdate = "update"
+	OpDelete = "delete"
+)
+
+// ChurnOptions configures the object churn generated by Churn.
+type ChurnOptions struct {
+	// Duration is how long the churn is generated
+	Duration time.Duration
+	// CreateRate is the number of objects created per second
+	CreateRate float64
+	// UpdateRate is the number of objects updated per second
+	UpdateRate float64
+	// DeleteRate is the number of objects deleted per second
+	DeleteRate float64
+	// UpdateFunc mutates an object before it is updated. Updates
+	// are skipped when not set.
+	UpdateFunc func(k8s.Object)
+	// Cleanup deletes the remaining objects when the churn is done
+	Cleanup bool
+}
+
+type ChurnOption func(*ChurnOptions)
+
+// WithDuration sets how long the load is generated
+func WithDuration(d time.Duration) ChurnOption {
+	return func(o *ChurnOptions) {
+		o.Duration = d
+	}
+}
+
+// WithCreateRate sets the number of objects created per second
+func WithCreateRate(rate float64) ChurnOption {
+	return func(o *ChurnOptions) {
+		o.CreateRate = rate
+	}
+}
+
+// WithUpdateRate sets the number of objects updated per second using fn to mutate them
+func WithUpdateRate(rate float64, fn func(k8s.Object)) ChurnOption {
+	return func(o *ChurnOptions) {
+		o.UpdateRate = rate
+		o.UpdateFunc = fn
+	}
+}
+
+// WithDeleteRate sets the number of objects deleted per second
+func WithDeleteRate(rate float64) ChurnOption {
+	return func(o *ChurnOptions) {
+		o.DeleteRate = rate
+	}
+}
+
+// WithCleanup deletes the objects still present once the churn is done
+func WithCleanup() ChurnOption {
+	return func(o *ChurnOptions) {
+		o.Cleanup = true
+	}
+}
+
+// churner keeps track of the objects created during a churn run
+type churner struct {
+	r        *resources.Resources
+	template k8s.Object
+	opts     *ChurnOptions
+	result   *Result
+
+	mu      sync.Mutex
+	counter int
+	objects []k8s.Object
+	// updated is the position of the next object to update
+	updated int
+}
+
+// Churn creates, updates, and deletes copies of the template object at the configured
+// rates until the duration elapses or ctx is done. The template can be a typed object
+// or an *unstructured.Unstructured with its GroupVersionKind set, which allows churning
+// any kind of resource. Object names are generated from the template name.
+func Churn(ctx context.Context, r *resources.Resources, template k8s.Object, opts ...ChurnOption) (*Result, error) {
+	if r == nil || template == nil {
+		return nil, fmt.Errorf("load churn: resources and template object are required")
+	}
+	options := &ChurnOptions{Duration: defaultDuration}
+	for _, fn := range opts {
+		fn(options)
+	}
+
+	c := &churner{r: r, template: template, opts: options, result: newResult()}
+
+	runCtx, cancel := context.WithTimeout(ctx, options.Duration)
+	defer cancel()
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	c.every(runCtx, &wg, interval(options.CreateRate), c.create)
+	if options.UpdateFunc != nil {
+		c.every(runCtx, &wg, interval(options.UpdateRate), c.update)
+	}
+	c.every(runCtx, &wg, interval(options.DeleteRate), c.delete)
+	wg.Wait()
+	c.result.Duration = time.Since(start)
+
+	if options.Cleanup {
+		for c.delete(ctx) {
+		}
+	}
+
+	return c.result, nil
+}
+
+// every runs op, in its own goroutine, at the given interval until ctx is done
+func (c *churner) every(ctx context.Context, wg *sync.WaitGroup, d time.Duration, op func(context.Context) bool) {
+	if d == 0 {
+		return
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(d)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				op(ctx)
+			}
+		}
+	}()
+}
+
+func (c *churner) create(ctx context.Context) bool {
+	obj, ok := c.template.DeepCopyObject().(k8s.Object)
+	if !ok {
+		return false
+	}
+	c.mu.Lock()
+	c.counter++
+	obj.SetName(fmt.Sprintf("%s-%d", c.template.GetName(), c.counter))
+	c.mu.Unlock()
+
+	start := time.Now()
+	err := c.r.Create(ctx, obj)
+	c.result.record(OpCreate, time.Since(start), err)
+	if err != nil {
+		log.V(4).ErrorS(err, "Load churn create failed", "name", obj.GetName())
+		return false
+	}
+
+	c.mu.Lock()
+	c.objects = append(c.objects, obj)
+	c.mu.Unlock()
+	return true
+}
+
+func (c *churner) update(ctx context.Context) bool {
+	// the objects are updated in turn, on a copy since the
+	// object can be deleted concurrently
+	c.mu.Lock()
+	if len(c.objects) == 0 {
+		c.mu.Unlock()
+		return false
+	}
+	if c.updated >= len(c.objects) {
+		c.updated = 0
+	}
+	obj, ok := c.objects[c.updated].DeepCopyObject().(k8s.Object)
+	c.updated++
+	c.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	start := time.Now()
+	err := c.r.Get(ctx, obj.GetName(), obj.GetNamespace(), obj)
+	if err == nil {
+		c.opts.UpdateFunc(obj)
+		err = c.r.Update(ctx, obj)
+	}
+	c.result.record(OpUpdate, time.Since(start), err)
+	if err != nil {
+		log.V(4).ErrorS(err, "Load churn update failed", "name", obj.GetName())
+	}
+	return err == nil
+}
+
+// delete removes the oldest object created and reports whether an object was removed
+func (c *churner) delete(ctx context.Context) bool {
+	c.mu.Lock()
+	if len(c.objects) == 0 {
+		c.mu.Unlock()
+		return false
+	}
+	obj := c.objects[0]
+	c.objects = c.objects[1:]
+	if c.updated > 0 {
+		c.updated--
+	}
+	c.mu.Unlock()
+
+	start := time.Now()
+	err := c.r.Delete(ctx, obj)
+	c.result.record(OpDelete, time.Since(start), err)
+	if err != nil {
+		log.V(4).ErrorS(err, "Load churn delete failed", "name", obj.GetName())
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package load
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+)
+
+func TestChurn(t *testing.T) {
+	r, err := resources.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "churn", Namespace: namespace, Labels: map[string]string{"test": "churn"}},
+		Data:       map[string]string{"key": "value"},
+	}
+
+	// the updates and deletes run concurrently on the same objects, run with -race
+	result, err := Churn(context.TODO(), r, template,
+		WithDuration(3*time.Second),
+		WithCreateRate(20),
+		WithUpdateRate(40, func(obj k8s.Object) {
+			cm := obj.(*corev1.ConfigMap)
+			cm.Data["updated"] = time.Now().String()
+		}),
+		WithDeleteRate(10),
+		WithCleanup(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, op := range []string{OpCreate, OpUpdate, OpDelete} {
+		if result.Stats[op].Count == 0 {
+			t.Errorf("expected %s operations, got stats: %v", op, result.Stats)
+		}
+	}
+	if result.Stats[OpCreate].Errors != 0 || result.Stats[OpDelete].Errors != 0 {
+		t.Errorf("unexpected create or delete errors: %v", result.Stats)
+	}
+
+	var list corev1.ConfigMapList
+	if err := r.List(context.TODO(), &list, resources.WithLabelSelector("test=churn")); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Items) != 0 {
+		t.Errorf("expected the remaining objects to be cleaned up, found %d", len(list.Items))
+	}
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package load
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// HTTPOptions configures the requests sent by HTTP.
+type HTTPOptions struct {
+	// Duration is how long requests are sent
+	Duration time.Duration
+	// Rate is the number of requests sent per second
+	Rate float64
+	// Method is the HTTP method used, defaults to GET
+	Method string
+	// Client is the http.Client used to send the requests
+	Client *http.Client
+}
+
+type HTTPOption func(*HTTPOptions)
+
+// WithHTTPDuration sets how long requests are sent
+func WithHTTPDuration(d time.Duration) HTTPOption {
+	return func(o *HTTPOptions) {
+		o.Duration = d
+	}
+}
+
+// WithHTTPRate sets the number of requests sent per second
+func WithHTTPRate(rate float64) HTTPOption {
+	return func(o *HTTPOptions) {
+		o.Rate = rate
+	}
+}
+
+// WithHTTPMethod sets the HTTP method of the requests
+func WithHTTPMethod(method string) HTTPOption {
+	return func(o *HTTPOptions) {
+		o.Method = method
+	}
+}
+
+// WithHTTPClient sets the http.Client used to send the requests
+func WithHTTPClient(client *http.Client) HTTPOption {
+	return func(o *HTTPOptions) {
+		o.Client = client
+	}
+}
+
+// HTTP sends requests to url at the configured rate until the duration elapses
+// or ctx is done. Stats are recorded per response status code, and requests that
+// fail to complete are recorded as errors under the "error" key.
+func HTTP(ctx context.Context, url string, opts ...HTTPOption) (*Result, error) {
+	options := &HTTPOptions{
+		Duration: defaultDuration,
+		Rate:     10,
+		Method:   http.MethodGet,
+		Client:   &http.Client{Timeout: 10 * time.Second},
+	}
+	for _, fn := range opts {
+		fn(options)
+	}
+	if options.Rate <= 0 {
+		return nil, fmt.Errorf("load http: rate must be greater than zero")
+	}
+
+	result := newResult()
+	runCtx, cancel := context.WithTimeout(ctx, options.Duration)
+	defer cancel()
+
+	start := time.Now()
+	ticker := time.NewTicker(interval(options.Rate))
+	defer ticker.Stop()
+
+	var wg sync.WaitGroup
+	for {
+		select {
+		case <-runCtx.Done():
+			wg.Wait()
+			result.Duration = time.Since(start)
+			return result, nil
+		case <-ticker.C:
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				send(runCtx, options, url, result)
+			}()
+		}
+	}
+}
+
+func send(ctx context.Context, opts *HTTPOptions, url string, result *Result) {
+	req, err := http.NewRequestWithContext(ctx, opts.Method, url, nil)
+	if err != nil {
+		result.record("error", 0, err)
+		return
+	}
+	start := time.Now()
+	resp, err := opts.Client.Do(req)
+	if err != nil {
+		// requests cut short by the end of the run are not errors
+		if ctx.Err() == nil {
+			result.record("error", time.Since(start), err)
+		}
+		return
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	var statusErr error
+	if resp.StatusCode >= http.StatusBadRequest {
+		statusErr = fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	result.record(strconv.Itoa(resp.StatusCode), time.Since(start), statusErr)
+}
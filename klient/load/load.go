@@ -0,0 +1,105 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package load provides primitives to generate a configurable load against
+// the API server (object churn) or against a workload (HTTP requests) for a
+// period of time while capturing basic metrics about the operations.
+package load
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	defaultDuration = 30 * time.Second
+)
+
+// Stats captures the metrics of a single type of operation.
+type Stats struct {
+	// Count is the number of operations attempted
+	Count int
+	// Errors is the number of operations that failed
+	Errors int
+	// Total is the cumulative latency of all operations
+	Total time.Duration
+	// Max is the highest observed latency
+	Max time.Duration
+}
+
+// Mean returns the average latency of the operations
+func (s Stats) Mean() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Count)
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("count=%d errors=%d mean=%s max=%s", s.Count, s.Errors, s.Mean(), s.Max)
+}
+
+// Result is the outcome of a load run. Stats are keyed by operation
+// name (i.e. create, update, delete, or the HTTP status code).
+type Result struct {
+	// Duration is the actual time spent generating the load
+	Duration time.Duration
+	// Stats stores the metrics for each type of operation
+	Stats map[string]Stats
+
+	mu sync.Mutex
+}
+
+func newResult() *Result {
+	return &Result{Stats: make(map[string]Stats)}
+}
+
+// record stores the outcome of one operation
+func (r *Result) record(op string, latency time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.Stats[op]
+	s.Count++
+	s.Total += latency
+	if latency > s.Max {
+		s.Max = latency
+	}
+	if err != nil {
+		s.Errors++
+	}
+	r.Stats[op] = s
+}
+
+// Errors returns the total number of failed operations
+func (r *Result) Errors() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var errs int
+	for _, s := range r.Stats {
+		errs += s.Errors
+	}
+	return errs
+}
+
+// interval converts a rate, in operations per second, into the time
+// between two operations. A zero rate disables the operation.
+func interval(rate float64) time.Duration {
+	if rate <= 0 {
+		return 0
+	}
+	return time.Duration(float64(time.Second) / rate)
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package load
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	result, err := HTTP(context.TODO(), server.URL, WithHTTPRate(50), WithHTTPDuration(500*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Stats["200"].Count == 0 {
+		t.Errorf("expected successful requests, got stats: %v", result.Stats)
+	}
+	if result.Errors() != 0 {
+		t.Errorf("unexpected errors: %v", result.Stats)
+	}
+
+	result, err = HTTP(context.TODO(), server.URL+"/fail", WithHTTPRate(50), WithHTTPDuration(200*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Errors() == 0 || result.Errors() != result.Stats["500"].Count {
+		t.Errorf("expected all requests to be errors, got stats: %v", result.Stats)
+	}
+}
+
+func TestStats_Mean(t *testing.T) {
+	s := Stats{Count: 4, Total: 2 * time.Second}
+	if s.Mean() != 500*time.Millisecond {
+		t.Errorf("unexpected mean: %s", s.Mean())
+	}
+	if (Stats{}).Mean() != 0 {
+		t.Error("expected zero mean for empty stats")
+	}
+}
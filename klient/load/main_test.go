@@ -0,0 +1,67 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package load
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	log "k8s.io/klog/v2"
+
+	"sigs.k8s.io/e2e-framework/klient/internal/testutil"
+	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+)
+
+var (
+	tc        *testutil.TestCluster
+	cfg       *rest.Config
+	namespace = "load-test"
+)
+
+func TestMain(m *testing.M) {
+	tc = testutil.SetupTestCluster("")
+	cfg = tc.RESTConfig
+	setup()
+	exitCode := m.Run()
+	tearDown()
+	os.Exit(exitCode)
+}
+
+func setup() {
+	r, err := resources.New(cfg)
+	if err != nil {
+		log.Fatalln("failed to create a resource manager instance", err)
+	}
+	if err := r.Create(context.TODO(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}); err != nil {
+		log.Fatalln("failed to create the test namespace", err)
+	}
+}
+
+func tearDown() {
+	r, err := resources.New(cfg)
+	if err == nil {
+		err = r.Delete(context.TODO(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}})
+	}
+	if err != nil {
+		log.ErrorS(err, "ran into an error trying to delete the namespace as part of the cleanup")
+	}
+	tc.DestroyTestCluster()
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+type collectorsContextKey struct{}
+
+// collectors are the collectors registered in a context, by name
+type collectors struct {
+	mu     sync.Mutex
+	byName map[string]*Collector
+}
+
+// WithCollector registers the collector under name in the context, so that the steps using
+// the context can retrieve it with CollectorFrom. The collectors registered in the returned
+// context are shared with the contexts derived from it.
+func WithCollector(ctx context.Context, name string, c *Collector) context.Context {
+	registry, ok := ctx.Value(collectorsContextKey{}).(*collectors)
+	if !ok {
+		registry = &collectors{byName: make(map[string]*Collector)}
+		ctx = context.WithValue(ctx, collectorsContextKey{}, registry)
+	}
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.byName[name] = c
+	return ctx
+}
+
+// CollectorFrom returns the collector registered under name in the context
+func CollectorFrom(ctx context.Context, name string) (*Collector, bool) {
+	registry, ok := ctx.Value(collectorsContextKey{}).(*collectors)
+	if !ok {
+		return nil, false
+	}
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	c, ok := registry.byName[name]
+	return c, ok
+}
+
+// CollectorNames returns the sorted names of the collectors registered in the context
+func CollectorNames(ctx context.Context) []string {
+	registry, ok := ctx.Value(collectorsContextKey{}).(*collectors)
+	if !ok {
+		return nil
+	}
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	names := make([]string, 0, len(registry.byName))
+	for name := range registry.byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
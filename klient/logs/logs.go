@@ -0,0 +1,235 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package logs captures the logs of the containers of labeled pods, typically the
+// controllers under test, so that assessments can assert that specific lines were
+// logged and that the logs can be kept with the artifacts of a failed test.
+package logs
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	log "k8s.io/klog/v2"
+)
+
+// failureTail is the number of captured lines included in the WaitFor errors
+const failureTail = 20
+
+// Line is a line logged by a container
+type Line struct {
+	Pod       string
+	Container string
+	Text      string
+}
+
+func (l Line) String() string {
+	return fmt.Sprintf("%s/%s: %s", l.Pod, l.Container, l.Text)
+}
+
+// Collector follows the logs of the containers of the pods matching a label selector, including
+// the pods created and the containers restarted after it started, until Stop is called.
+type Collector struct {
+	cs        kubernetes.Interface
+	namespace string
+	cancel    context.CancelFunc
+	w         watch.Interface
+	wg        sync.WaitGroup
+
+	mu       sync.Mutex
+	lines    []Line
+	followed map[string]bool
+	// updated is closed, and replaced, when lines are captured
+	updated chan struct{}
+}
+
+// Collect starts capturing the logs of the containers of the pods in namespace matching the
+// label selector, i.e. "app.kubernetes.io/name=my-controller". The logs are captured from the
+// start of each container.
+func Collect(ctx context.Context, cs kubernetes.Interface, namespace, selector string) (*Collector, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	c := &Collector{
+		cs:        cs,
+		namespace: namespace,
+		cancel:    cancel,
+		followed:  make(map[string]bool),
+		updated:   make(chan struct{}),
+	}
+
+	pods, err := cs.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("logs collect: %w", err)
+	}
+	c.w, err = cs.CoreV1().Pods(namespace).Watch(ctx, metav1.ListOptions{LabelSelector: selector, ResourceVersion: pods.ResourceVersion})
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("logs collect: %w", err)
+	}
+	for i := range pods.Items {
+		c.follow(ctx, &pods.Items[i])
+	}
+
+	c.wg.Add(1)
+	go c.run(ctx)
+	return c, nil
+}
+
+func (c *Collector) run(ctx context.Context) {
+	defer c.wg.Done()
+	for event := range c.w.ResultChan() {
+		pod, ok := event.Object.(*v1.Pod)
+		if !ok {
+			log.V(4).Infof("logs collector: ignoring unexpected object %T", event.Object)
+			continue
+		}
+		if event.Type == watch.Added || event.Type == watch.Modified {
+			c.follow(ctx, pod)
+		}
+	}
+}
+
+// follow starts streaming the logs of the started containers of the pod not followed yet
+func (c *Collector) follow(ctx context.Context, pod *v1.Pod) {
+	statuses := append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if status.State.Running == nil && status.State.Terminated == nil {
+			continue
+		}
+		key := fmt.Sprintf("%s/%s/%d", pod.Name, status.Name, status.RestartCount)
+		c.mu.Lock()
+		followed := c.followed[key]
+		c.followed[key] = true
+		c.mu.Unlock()
+		if followed {
+			continue
+		}
+
+		c.wg.Add(1)
+		go func(pod, container string) {
+			defer c.wg.Done()
+			if err := c.stream(ctx, pod, container); err != nil && ctx.Err() == nil {
+				log.V(4).Infof("logs collector: streaming %s/%s: %s", pod, container, err)
+			}
+		}(pod.Name, status.Name)
+	}
+}
+
+func (c *Collector) stream(ctx context.Context, pod, container string) error {
+	log.V(4).Infof("logs collector: following %s/%s/%s", c.namespace, pod, container)
+	rc, err := c.cs.CoreV1().Pods(c.namespace).GetLogs(pod, &v1.PodLogOptions{Container: container, Follow: true}).Stream(ctx)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	scanner := bufio.NewScanner(rc)
+	for scanner.Scan() {
+		c.add(Line{Pod: pod, Container: container, Text: scanner.Text()})
+	}
+	return scanner.Err()
+}
+
+func (c *Collector) add(line Line) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lines = append(c.lines, line)
+	close(c.updated)
+	c.updated = make(chan struct{})
+}
+
+// Lines returns a copy of the lines captured so far
+func (c *Collector) Lines() []Line {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	lines := make([]Line, len(c.lines))
+	copy(lines, c.lines)
+	return lines
+}
+
+// WaitFor waits, up to timeout, for a line matching the regular expression to be captured
+// and returns the first one. The lines captured before WaitFor was called are also checked.
+// The returned error lists the last lines captured.
+func (c *Collector) WaitFor(re *regexp.Regexp, timeout time.Duration) (Line, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	next := 0
+	for {
+		c.mu.Lock()
+		lines := c.lines[next:]
+		updated := c.updated
+		c.mu.Unlock()
+
+		for _, line := range lines {
+			if re.MatchString(line.Text) {
+				return line, nil
+			}
+		}
+		next += len(lines)
+
+		select {
+		case <-updated:
+		case <-timer.C:
+			return Line{}, c.waitError(re, timeout)
+		}
+	}
+}
+
+func (c *Collector) waitError(re *regexp.Regexp, timeout time.Duration) error {
+	lines := c.Lines()
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "logs: no line matching %q within %s, captured %d line(s)", re, timeout, len(lines))
+	if len(lines) > failureTail {
+		fmt.Fprintf(&sb, ", last %d:", failureTail)
+		lines = lines[len(lines)-failureTail:]
+	}
+	for _, line := range lines {
+		fmt.Fprintf(&sb, "\n  %s", line)
+	}
+	return fmt.Errorf("%s", sb.String())
+}
+
+// WriteTo writes the lines captured so far to w, one per line, prefixed by their pod and container
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	var written int64
+	for _, line := range c.Lines() {
+		n, err := fmt.Fprintln(w, line)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// Stop stops capturing the logs and returns the captured lines
+func (c *Collector) Stop() []Line {
+	c.cancel()
+	c.w.Stop()
+	c.wg.Wait()
+	return c.Lines()
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func runningPod(name string, labels map[string]string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "system", Labels: labels},
+		Status: v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{
+			{Name: "manager", State: v1.ContainerState{Running: &v1.ContainerStateRunning{}}},
+		}},
+	}
+}
+
+func TestCollector(t *testing.T) {
+	controller := map[string]string{"app": "controller"}
+	cs := fake.NewSimpleClientset(
+		runningPod("controller-1", controller),
+		runningPod("other", map[string]string{"app": "other"}),
+	)
+
+	ctx := context.TODO()
+	c, err := Collect(ctx, cs, "system", "app=controller")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the fake client returns "fake logs" for any container
+	line, err := c.WaitFor(regexp.MustCompile("^fake"), time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := (Line{Pod: "controller-1", Container: "manager", Text: "fake logs"}); line != expected {
+		t.Errorf("expected line %v, got %v", expected, line)
+	}
+
+	// pods created after the collector started are followed too
+	if _, err := cs.CoreV1().Pods("system").Create(ctx, runningPod("controller-2", controller), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for len(c.Lines()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	_, err = c.WaitFor(regexp.MustCompile("reconciled"), 10*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), `no line matching "reconciled"`) || !strings.Contains(err.Error(), "controller-2/manager: fake logs") {
+		t.Errorf("expected an error listing the captured lines, got %v", err)
+	}
+
+	lines := c.Stop()
+	if len(lines) != 2 {
+		t.Fatalf("expected the lines of the 2 controller pods, got %v", lines)
+	}
+	var buf bytes.Buffer
+	if _, err := c.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "controller-1/manager: fake logs\ncontroller-2/manager: fake logs\n"; buf.String() != expected {
+		t.Errorf("expected written logs %q, got %q", expected, buf.String())
+	}
+}
+
+func TestWithCollector(t *testing.T) {
+	ctx := WithCollector(context.TODO(), "b", &Collector{})
+	ctx = WithCollector(ctx, "a", &Collector{})
+	if names := CollectorNames(ctx); len(names) != 2 || names[0] != "a" || names[1] != "b" {
+		t.Errorf("expected collectors [a b], got %v", names)
+	}
+	if _, ok := CollectorFrom(ctx, "a"); !ok {
+		t.Error("expected collector a to be found")
+	}
+	if _, ok := CollectorFrom(context.TODO(), "a"); ok {
+		t.Error("expected no collector in an empty context")
+	}
+}
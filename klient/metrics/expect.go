@@ -0,0 +1,157 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Matcher selects the samples an expectation applies to.
+type Matcher struct {
+	// Desc describes the matcher in failure messages
+	Desc  string
+	Match func(Sample) bool
+}
+
+// WithName matches samples of the metric with the given name
+func WithName(name string) Matcher {
+	return Matcher{Desc: name, Match: func(s Sample) bool { return s.Name == name }}
+}
+
+// WithLabel matches samples with the given label value, as in
+// Prometheus an empty value matches samples without the label
+func WithLabel(name, value string) Matcher {
+	return Matcher{
+		Desc:  fmt.Sprintf("%s=%q", name, value),
+		Match: func(s Sample) bool { return s.Labels[name] == value },
+	}
+}
+
+// All matches samples matching all the provided matchers
+func All(matchers ...Matcher) Matcher {
+	descs := make([]string, 0, len(matchers))
+	for _, m := range matchers {
+		descs = append(descs, m.Desc)
+	}
+	return Matcher{
+		Desc: strings.Join(descs, ","),
+		Match: func(s Sample) bool {
+			for _, m := range matchers {
+				if !m.Match(s) {
+					return false
+				}
+			}
+			return true
+		},
+	}
+}
+
+// Metric matches the samples of the metric with the given name and label
+// values, provided as pairs of label name and value:
+//
+//	metrics.Metric("workqueue_adds_total", "name", "my-controller")
+func Metric(name string, labels ...string) Matcher {
+	if len(labels)%2 != 0 {
+		panic("metrics: labels must be provided as name and value pairs")
+	}
+	matchers := []Matcher{WithName(name)}
+	for i := 0; i < len(labels); i += 2 {
+		matchers = append(matchers, WithLabel(labels[i], labels[i+1]))
+	}
+	return All(matchers...)
+}
+
+// Sum returns the sum of the values of the samples matching m and
+// whether any sample matched.
+func Sum(samples []Sample, m Matcher) (float64, bool) {
+	sum, found := 0.0, false
+	for _, s := range samples {
+		if m.Match(s) {
+			sum += s.Value
+			found = true
+		}
+	}
+	return sum, found
+}
+
+// Expectation checks the scraped samples and returns a descriptive
+// error when they do not satisfy it.
+type Expectation func([]Sample) error
+
+// Equal expects the sum of the samples matching m to be value
+func Equal(m Matcher, value float64) Expectation {
+	return compare(m, fmt.Sprintf("equal to %v", value), func(v float64) bool { return v == value })
+}
+
+// AtLeast expects the sum of the samples matching m to be value or more
+func AtLeast(m Matcher, value float64) Expectation {
+	return compare(m, fmt.Sprintf("at least %v", value), func(v float64) bool { return v >= value })
+}
+
+// AtMost expects the sum of the samples matching m to be value or less
+func AtMost(m Matcher, value float64) Expectation {
+	return compare(m, fmt.Sprintf("at most %v", value), func(v float64) bool { return v <= value })
+}
+
+// Present expects at least one sample matching m
+func Present(m Matcher) Expectation {
+	return func(samples []Sample) error {
+		if _, found := Sum(samples, m); !found {
+			return fmt.Errorf("expected samples matching %s, found none", m.Desc)
+		}
+		return nil
+	}
+}
+
+// Absent expects no sample matching m
+func Absent(m Matcher) Expectation {
+	return func(samples []Sample) error {
+		if _, found := Sum(samples, m); found {
+			return fmt.Errorf("expected no sample matching %s", m.Desc)
+		}
+		return nil
+	}
+}
+
+func compare(m Matcher, desc string, ok func(float64) bool) Expectation {
+	return func(samples []Sample) error {
+		sum, found := Sum(samples, m)
+		if !found {
+			return fmt.Errorf("expected %s %s, found no sample", m.Desc, desc)
+		}
+		if !ok(sum) {
+			return fmt.Errorf("expected %s %s, found %v", m.Desc, desc, sum)
+		}
+		return nil
+	}
+}
+
+// Verify checks the samples against all the expectations. The returned
+// error lists every unmet expectation.
+func Verify(samples []Sample, expectations ...Expectation) error {
+	var failures []string
+	for _, expect := range expectations {
+		if err := expect(samples); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s", strings.Join(failures, "\n"))
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	samples := []Sample{
+		{Name: "workqueue_adds_total", Labels: map[string]string{"name": "deployment"}, Value: 12},
+		{Name: "workqueue_adds_total", Labels: map[string]string{"name": "replicaset"}, Value: 30},
+		{Name: "workqueue_depth", Labels: map[string]string{"name": "deployment"}, Value: 0},
+	}
+
+	tests := []struct {
+		name         string
+		expectations []Expectation
+		failures     []string
+	}{
+		{
+			name: "met",
+			expectations: []Expectation{
+				Equal(Metric("workqueue_adds_total", "name", "deployment"), 12),
+				AtLeast(WithName("workqueue_adds_total"), 42),
+				AtMost(Metric("workqueue_depth"), 0),
+				Present(WithLabel("name", "replicaset")),
+				Absent(WithName("workqueue_retries_total")),
+			},
+		},
+		{
+			name: "unmet",
+			expectations: []Expectation{
+				AtLeast(Metric("workqueue_adds_total", "name", "deployment"), 20),
+				Equal(WithName("workqueue_retries_total"), 0),
+				Absent(WithName("workqueue_depth")),
+			},
+			failures: []string{
+				`expected workqueue_adds_total,name="deployment" at least 20, found 12`,
+				"expected workqueue_retries_total equal to 0, found no sample",
+				"expected no sample matching workqueue_depth",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := Verify(samples, test.expectations...)
+			if len(test.failures) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected failure: %s", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected failure")
+			}
+			for _, f := range test.failures {
+				if !strings.Contains(err.Error(), f) {
+					t.Errorf("expected failure to contain %q, got:\n%s", f, err)
+				}
+			}
+		})
+	}
+}
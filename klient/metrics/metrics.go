@@ -0,0 +1,208 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics scrapes the Prometheus metrics exposed by pods and services,
+// through the API server proxy, and provides expectations to assert on their
+// values, so that assessments can check the behavior reported by controllers.
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// Sample is a single value of a metric, identified by its name and labels
+type Sample struct {
+	Name   string
+	Labels map[string]string
+	Value  float64
+}
+
+func (s Sample) String() string {
+	if len(s.Labels) == 0 {
+		return fmt.Sprintf("%s %v", s.Name, s.Value)
+	}
+	keys := make([]string, 0, len(s.Labels))
+	for k := range s.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	labels := make([]string, 0, len(keys))
+	for _, k := range keys {
+		labels = append(labels, fmt.Sprintf("%s=%q", k, s.Labels[k]))
+	}
+	return fmt.Sprintf("%s{%s} %v", s.Name, strings.Join(labels, ","), s.Value)
+}
+
+// Options are the options used to scrape the metrics
+type Options struct {
+	// Scheme used by the proxy to reach the target, http or https
+	Scheme string
+	// Port of the target serving the metrics, a port number or name
+	Port string
+	// Path of the metrics endpoint
+	Path string
+}
+
+type Option func(*Options)
+
+// WithScheme sets the scheme, http (default) or https, used to reach the target
+func WithScheme(scheme string) Option {
+	return func(o *Options) { o.Scheme = scheme }
+}
+
+// WithPort sets the port, number or name, serving the metrics. Defaults to 8080.
+func WithPort(port string) Option {
+	return func(o *Options) { o.Port = port }
+}
+
+// WithPath sets the path of the metrics endpoint. Defaults to /metrics.
+func WithPath(path string) Option {
+	return func(o *Options) { o.Path = path }
+}
+
+// ScrapePod scrapes the metrics exposed by the pod through the API server proxy
+func ScrapePod(ctx context.Context, cfg *rest.Config, namespace, name string, opts ...Option) ([]Sample, error) {
+	return scrape(ctx, cfg, opts, func(cs kubernetes.Interface, o *Options) rest.ResponseWrapper {
+		return cs.CoreV1().Pods(namespace).ProxyGet(o.Scheme, name, o.Port, o.Path, nil)
+	})
+}
+
+// ScrapeService scrapes the metrics exposed by the service through the API server proxy
+func ScrapeService(ctx context.Context, cfg *rest.Config, namespace, name string, opts ...Option) ([]Sample, error) {
+	return scrape(ctx, cfg, opts, func(cs kubernetes.Interface, o *Options) rest.ResponseWrapper {
+		return cs.CoreV1().Services(namespace).ProxyGet(o.Scheme, name, o.Port, o.Path, nil)
+	})
+}
+
+func scrape(ctx context.Context, cfg *rest.Config, opts []Option, get func(kubernetes.Interface, *Options) rest.ResponseWrapper) ([]Sample, error) {
+	options := &Options{Scheme: "http", Port: "8080", Path: "/metrics"}
+	for _, fn := range opts {
+		fn(options)
+	}
+
+	cs, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("metrics scrape: %w", err)
+	}
+	data, err := get(cs, options).DoRaw(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("metrics scrape: %w", err)
+	}
+	return Parse(bytes.NewReader(data))
+}
+
+// Parse reads the samples of metrics in the Prometheus text exposition format.
+// Comments, including HELP and TYPE metadata, and timestamps are ignored.
+func Parse(r io.Reader) ([]Sample, error) {
+	var samples []Sample
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		sample, err := parseSample(text)
+		if err != nil {
+			return nil, fmt.Errorf("metrics parse: line %d: %w", line, err)
+		}
+		samples = append(samples, sample)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("metrics parse: %w", err)
+	}
+	return samples, nil
+}
+
+// parseSample parses a sample line: name{label="value",...} value [timestamp]
+func parseSample(text string) (Sample, error) {
+	end := strings.IndexAny(text, "{ \t")
+	if end <= 0 {
+		return Sample{}, fmt.Errorf("invalid sample %q", text)
+	}
+	sample := Sample{Name: text[:end], Labels: map[string]string{}}
+	rest := text[end:]
+
+	if strings.HasPrefix(rest, "{") {
+		var err error
+		if rest, err = parseLabels(rest[1:], sample.Labels); err != nil {
+			return Sample{}, fmt.Errorf("sample %s: %w", sample.Name, err)
+		}
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 || len(fields) > 2 {
+		return Sample{}, fmt.Errorf("sample %s: invalid value %q", sample.Name, rest)
+	}
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return Sample{}, fmt.Errorf("sample %s: %w", sample.Name, err)
+	}
+	sample.Value = value
+	return sample, nil
+}
+
+// parseLabels parses the labels following the opening brace into labels
+// and returns the remaining text after the closing brace.
+func parseLabels(text string, labels map[string]string) (string, error) {
+	for {
+		text = strings.TrimLeft(text, " \t,")
+		if strings.HasPrefix(text, "}") {
+			return text[1:], nil
+		}
+		eq := strings.Index(text, "=")
+		if eq <= 0 || len(text) < eq+2 || text[eq+1] != '"' {
+			return "", fmt.Errorf("invalid labels %q", text)
+		}
+		name := strings.TrimSpace(text[:eq])
+		text = text[eq+2:]
+
+		var value strings.Builder
+		closed := false
+		for i := 0; i < len(text); i++ {
+			c := text[i]
+			if c == '"' {
+				text = text[i+1:]
+				closed = true
+				break
+			}
+			if c == '\\' && i+1 < len(text) {
+				i++
+				switch text[i] {
+				case 'n':
+					c = '\n'
+				default:
+					c = text[i]
+				}
+			}
+			value.WriteByte(c)
+		}
+		if !closed {
+			return "", fmt.Errorf("unterminated value of label %s", name)
+		}
+		labels[name] = value.String()
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/rest"
+)
+
+const exposition = `# HELP workqueue_adds_total Total number of adds handled by workqueue
+# TYPE workqueue_adds_total counter
+workqueue_adds_total{name="deployment"} 12
+workqueue_adds_total{name="replicaset"} 30 1395066363000
+# TYPE process_start_time_seconds gauge
+process_start_time_seconds 1.6e+09
+rest_client_requests_total{code="200",host="10.0.0.1:443",method="GET"} 7
+escaped{path="C:\\dir\\",msg="say \"hi\"\n"} +Inf
+`
+
+func TestParse(t *testing.T) {
+	samples, err := Parse(strings.NewReader(exposition))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 5 {
+		t.Fatalf("expected 5 samples, got %d: %v", len(samples), samples)
+	}
+	if samples[1].Labels["name"] != "replicaset" || samples[1].Value != 30 {
+		t.Errorf("unexpected sample with timestamp: %s", samples[1])
+	}
+	if samples[2].Name != "process_start_time_seconds" || samples[2].Value != 1.6e9 {
+		t.Errorf("unexpected sample without labels: %s", samples[2])
+	}
+	if len(samples[3].Labels) != 3 || samples[3].Labels["host"] != "10.0.0.1:443" {
+		t.Errorf("unexpected sample labels: %s", samples[3])
+	}
+	if samples[4].Labels["path"] != `C:\dir\` || samples[4].Labels["msg"] != "say \"hi\"\n" || !math.IsInf(samples[4].Value, 1) {
+		t.Errorf("unexpected escaped sample: %s", samples[4])
+	}
+
+	for _, invalid := range []string{`metric{name="a} 1`, `metric{name=a} 1`, `metric`, `metric one`} {
+		if _, err := Parse(strings.NewReader(invalid)); err == nil {
+			t.Errorf("expected %q to fail parsing", invalid)
+		}
+	}
+}
+
+func TestScrapePod(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/namespaces/ns/pods/https:controller:8443/proxy/metrics" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(exposition))
+	}))
+	defer server.Close()
+
+	cfg := &rest.Config{Host: server.URL}
+	samples, err := ScrapePod(context.TODO(), cfg, "ns", "controller", WithScheme("https"), WithPort("8443"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 5 {
+		t.Errorf("expected 5 samples, got %d", len(samples))
+	}
+
+	if _, err := ScrapeService(context.TODO(), cfg, "ns", "controller"); err == nil {
+		t.Error("expected scraping an unknown endpoint to fail")
+	}
+}
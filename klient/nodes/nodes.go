@@ -0,0 +1,157 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package nodes detects the operating systems and architectures of the nodes of
+// heterogeneous clusters, and schedules pods on the nodes with the required ones.
+package nodes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+)
+
+// Platform is the operating system and architecture of a node, i.e. linux/arm64
+type Platform struct {
+	OS   string
+	Arch string
+}
+
+func (p Platform) String() string {
+	return fmt.Sprintf("%s/%s", p.OS, p.Arch)
+}
+
+// OSSelector returns the node selector of the nodes running the operating system, i.e. windows
+func OSSelector(os string) map[string]string {
+	return map[string]string{v1.LabelOSStable: os}
+}
+
+// ArchSelector returns the node selector of the nodes with the architecture, i.e. arm64
+func ArchSelector(arch string) map[string]string {
+	return map[string]string{v1.LabelArchStable: arch}
+}
+
+// PlatformOf returns the platform of the node, from its well-known labels or, when
+// they are not set, from the information reported by the node
+func PlatformOf(node *v1.Node) Platform {
+	p := Platform{OS: node.Labels[v1.LabelOSStable], Arch: node.Labels[v1.LabelArchStable]}
+	if p.OS == "" {
+		p.OS = node.Status.NodeInfo.OperatingSystem
+	}
+	if p.Arch == "" {
+		p.Arch = node.Status.NodeInfo.Architecture
+	}
+	return p
+}
+
+// Platforms returns the distinct platforms of the nodes of the cluster, sorted
+func Platforms(ctx context.Context, r *resources.Resources) ([]Platform, error) {
+	var list v1.NodeList
+	if err := r.List(ctx, &list); err != nil {
+		return nil, fmt.Errorf("nodes platforms: %w", err)
+	}
+	seen := make(map[Platform]bool)
+	var platforms []Platform
+	for i := range list.Items {
+		p := PlatformOf(&list.Items[i])
+		if !seen[p] {
+			seen[p] = true
+			platforms = append(platforms, p)
+		}
+	}
+	sort.Slice(platforms, func(i, j int) bool { return platforms[i].String() < platforms[j].String() })
+	return platforms, nil
+}
+
+// Matching returns the schedulable nodes whose labels match the node selector
+func Matching(nodes []v1.Node, selector map[string]string) []v1.Node {
+	sel := labels.SelectorFromSet(selector)
+	var matching []v1.Node
+	for _, node := range nodes {
+		if !node.Spec.Unschedulable && sel.Matches(labels.Set(node.Labels)) {
+			matching = append(matching, node)
+		}
+	}
+	return matching
+}
+
+// ScheduleOn updates the pod spec, i.e. the template of a Deployment, so that its pods are
+// scheduled on the nodes matching the node selector: the selector is added to the node selector
+// of the pods, and the taints of the matching nodes, i.e. os=windows:NoSchedule, are tolerated.
+// Returns an error when no schedulable node matches.
+func ScheduleOn(ctx context.Context, r *resources.Resources, spec *v1.PodSpec, selector map[string]string) error {
+	var list v1.NodeList
+	if err := r.List(ctx, &list); err != nil {
+		return fmt.Errorf("nodes schedule on: %w", err)
+	}
+	if err := scheduleOn(spec, selector, list.Items); err != nil {
+		return fmt.Errorf("nodes schedule on: %w", err)
+	}
+	return nil
+}
+
+// conditionTaints are the taints set by the node lifecycle, which are never tolerated
+var conditionTaints = map[string]bool{
+	v1.TaintNodeNotReady:           true,
+	v1.TaintNodeUnreachable:        true,
+	v1.TaintNodeUnschedulable:      true,
+	v1.TaintNodeMemoryPressure:     true,
+	v1.TaintNodeDiskPressure:       true,
+	v1.TaintNodeNetworkUnavailable: true,
+	v1.TaintNodePIDPressure:        true,
+}
+
+func scheduleOn(spec *v1.PodSpec, selector map[string]string, nodes []v1.Node) error {
+	matching := Matching(nodes, selector)
+	if len(matching) == 0 {
+		return fmt.Errorf("no schedulable node matches %s", labels.SelectorFromSet(selector))
+	}
+
+	if spec.NodeSelector == nil {
+		spec.NodeSelector = make(map[string]string, len(selector))
+	}
+	for k, v := range selector {
+		spec.NodeSelector[k] = v
+	}
+
+	for _, node := range matching {
+		for _, taint := range node.Spec.Taints {
+			if taint.Effect == v1.TaintEffectPreferNoSchedule || conditionTaints[taint.Key] || strings.HasPrefix(taint.Key, "node-role.kubernetes.io/") {
+				continue
+			}
+			toleration := v1.Toleration{Key: taint.Key, Operator: v1.TolerationOpEqual, Value: taint.Value, Effect: taint.Effect}
+			if !tolerated(spec.Tolerations, &taint) {
+				spec.Tolerations = append(spec.Tolerations, toleration)
+			}
+		}
+	}
+	return nil
+}
+
+func tolerated(tolerations []v1.Toleration, taint *v1.Taint) bool {
+	for i := range tolerations {
+		if tolerations[i].ToleratesTaint(taint) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodes
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func node(name, os, arch string, taints ...v1.Taint) v1.Node {
+	return v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{v1.LabelOSStable: os, v1.LabelArchStable: arch}},
+		Spec:       v1.NodeSpec{Taints: taints},
+	}
+}
+
+func TestPlatformOf(t *testing.T) {
+	n := node("n", "windows", "amd64")
+	if p := PlatformOf(&n); p.String() != "windows/amd64" {
+		t.Errorf("expected windows/amd64, got %s", p)
+	}
+	unlabeled := v1.Node{Status: v1.NodeStatus{NodeInfo: v1.NodeSystemInfo{OperatingSystem: "linux", Architecture: "arm64"}}}
+	if p := PlatformOf(&unlabeled); p.String() != "linux/arm64" {
+		t.Errorf("expected the platform reported by the node, got %s", p)
+	}
+}
+
+func TestScheduleOn(t *testing.T) {
+	windowsTaint := v1.Taint{Key: "os", Value: "windows", Effect: v1.TaintEffectNoSchedule}
+	nodes := []v1.Node{
+		node("linux", "linux", "amd64"),
+		node("windows", "windows", "amd64", windowsTaint,
+			v1.Taint{Key: v1.TaintNodeNotReady, Effect: v1.TaintEffectNoExecute},
+			v1.Taint{Key: "preferred", Effect: v1.TaintEffectPreferNoSchedule}),
+	}
+
+	spec := &v1.PodSpec{NodeSelector: map[string]string{"disk": "ssd"}}
+	if err := scheduleOn(spec, OSSelector("windows"), nodes); err != nil {
+		t.Fatal(err)
+	}
+	if expected := map[string]string{"disk": "ssd", v1.LabelOSStable: "windows"}; !reflect.DeepEqual(spec.NodeSelector, expected) {
+		t.Errorf("expected node selector %v, got %v", expected, spec.NodeSelector)
+	}
+	expected := []v1.Toleration{{Key: "os", Operator: v1.TolerationOpEqual, Value: "windows", Effect: v1.TaintEffectNoSchedule}}
+	if !reflect.DeepEqual(spec.Tolerations, expected) {
+		t.Errorf("expected tolerations %v, got %v", expected, spec.Tolerations)
+	}
+
+	// the taints already tolerated are not added twice
+	if err := scheduleOn(spec, OSSelector("windows"), nodes); err != nil {
+		t.Fatal(err)
+	}
+	if len(spec.Tolerations) != 1 {
+		t.Errorf("expected a single toleration, got %v", spec.Tolerations)
+	}
+
+	if err := scheduleOn(&v1.PodSpec{}, ArchSelector("arm64"), nodes); err == nil {
+		t.Error("expected an error when no node matches")
+	}
+}
+
+func TestMatching(t *testing.T) {
+	cordoned := node("cordoned", "linux", "arm64")
+	cordoned.Spec.Unschedulable = true
+	nodes := []v1.Node{node("amd", "linux", "amd64"), node("arm", "linux", "arm64"), cordoned}
+
+	matching := Matching(nodes, ArchSelector("arm64"))
+	if len(matching) != 1 || matching[0].Name != "arm" {
+		t.Errorf("expected the schedulable arm64 node, got %v", matching)
+	}
+	if matching := Matching(nodes, nil); len(matching) != 2 {
+		t.Errorf("expected all the schedulable nodes for an empty selector, got %d", len(matching))
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package klient
+
+import (
+	"time"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/transport"
+)
+
+// Option tunes the REST config of a client created with New
+type Option func(*rest.Config)
+
+// WithQPS sets the maximum queries per second sent by the client to the
+// API server, allowing bursts of burst queries. The defaults of client-go
+// (5 QPS, burst of 10) throttle large parallel suites.
+func WithQPS(qps float32, burst int) Option {
+	return func(cfg *rest.Config) {
+		cfg.QPS = qps
+		cfg.Burst = burst
+	}
+}
+
+// WithTimeout sets the timeout of the requests sent by the client. A zero
+// timeout means no timeout, besides the deadline of the request context.
+func WithTimeout(timeout time.Duration) Option {
+	return func(cfg *rest.Config) {
+		cfg.Timeout = timeout
+	}
+}
+
+// WithTransportWrapper installs a round tripper wrapping the transport of the
+// client, i.e. apicalls.Counter.Wrap to count the API calls. It is chained with
+// the wrappers already installed in the config.
+func WithTransportWrapper(wrap transport.WrapperFunc) Option {
+	return func(cfg *rest.Config) {
+		cfg.Wrap(wrap)
+	}
+}
+
+// applyOptions returns a copy of cfg tuned with opts, or cfg when no option is provided
+func applyOptions(cfg *rest.Config, opts []Option) *rest.Config {
+	if len(opts) == 0 {
+		return cfg
+	}
+	cfg = rest.CopyConfig(cfg)
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package proxy sends HTTP requests to Services and Pods through the proxy subresource of the
+// API server, so that assessments can reach in-cluster endpoints from runners where the cluster
+// network is not routable and port-forwarding is blocked.
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	log "k8s.io/klog/v2"
+)
+
+// Target is the Service or Pod the requests are proxied to
+type Target struct {
+	resource  string
+	namespace string
+	name      string
+	port      string
+	scheme    string
+}
+
+// Service targets the port, by name or number, of the Service. An empty port
+// targets the first port of the Service.
+func Service(namespace, name, port string) Target {
+	return Target{resource: "services", namespace: namespace, name: name, port: port}
+}
+
+// Pod targets the port, by name or number, of the Pod. An empty port targets
+// the default port of the Pod.
+func Pod(namespace, name, port string) Target {
+	return Target{resource: "pods", namespace: namespace, name: name, port: port}
+}
+
+// WithHTTPS returns the target with the requests proxied over HTTPS. The API
+// server does not verify the certificate of the target.
+func (t Target) WithHTTPS() Target {
+	t.scheme = "https"
+	return t
+}
+
+// nameSpec returns the [scheme:]name[:port] of the target in the proxy path
+func (t Target) nameSpec() string {
+	spec := t.name
+	if t.port != "" {
+		spec = fmt.Sprintf("%s:%s", spec, t.port)
+	}
+	if t.scheme != "" {
+		if t.port == "" {
+			spec += ":"
+		}
+		spec = fmt.Sprintf("%s:%s", t.scheme, spec)
+	}
+	return spec
+}
+
+func (t Target) String() string {
+	return fmt.Sprintf("%s/%s/%s", t.resource, t.namespace, t.nameSpec())
+}
+
+// Response is the response of the target. The responses with an error status
+// code are returned as such, not as errors.
+type Response struct {
+	StatusCode int
+	Body       []byte
+}
+
+type requestOptions struct {
+	query       map[string]string
+	headers     http.Header
+	contentType string
+}
+
+// RequestOption configures a proxied request
+type RequestOption func(*requestOptions)
+
+// WithQueryParam adds the query parameter to the request
+func WithQueryParam(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		o.query[key] = value
+	}
+}
+
+// WithHeader adds the header to the request
+func WithHeader(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		o.headers.Add(key, value)
+	}
+}
+
+// WithContentType sets the content type of the body of the request, application/json by default
+func WithContentType(contentType string) RequestOption {
+	return func(o *requestOptions) {
+		o.contentType = contentType
+	}
+}
+
+// Client sends requests through the proxy subresource of the API server
+type Client struct {
+	rc *rest.RESTClient
+}
+
+// New returns a client proxying the requests through the API server of cfg
+func New(cfg *rest.Config) (*Client, error) {
+	cs, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("proxy client: %w", err)
+	}
+	rc, ok := cs.CoreV1().RESTClient().(*rest.RESTClient)
+	if !ok {
+		return nil, fmt.Errorf("proxy client: unexpected REST client %T", cs.CoreV1().RESTClient())
+	}
+	return &Client{rc: rc}, nil
+}
+
+// Get sends a GET request for the path, i.e. /healthz, to the target
+func (c *Client) Get(ctx context.Context, target Target, path string, opts ...RequestOption) (*Response, error) {
+	return c.Do(ctx, http.MethodGet, target, path, nil, opts...)
+}
+
+// Post sends a POST request for the path, with the body, to the target
+func (c *Client) Post(ctx context.Context, target Target, path string, body []byte, opts ...RequestOption) (*Response, error) {
+	return c.Do(ctx, http.MethodPost, target, path, body, opts...)
+}
+
+// Do sends a request with the method for the path, with the body if not nil, to the target.
+// An error is returned when the request cannot be proxied, not when the target responds with
+// an error status code.
+func (c *Client) Do(ctx context.Context, method string, target Target, path string, body []byte, opts ...RequestOption) (*Response, error) {
+	options := &requestOptions{query: make(map[string]string), headers: make(http.Header), contentType: "application/json"}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	// the request is sent with the HTTP client of the REST client, so that the responses with
+	// an error status code are returned rather than decoded as API errors
+	req := c.rc.Verb(method).
+		Namespace(target.namespace).
+		Resource(target.resource).
+		Name(target.nameSpec()).
+		SubResource("proxy").
+		Suffix(strings.TrimPrefix(path, "/"))
+	for k, v := range options.query {
+		req = req.Param(k, v)
+	}
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, req.URL().String(), reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("proxy %s %s to %s: %w", method, path, target, err)
+	}
+	httpReq.Header = options.headers
+	if body != nil {
+		httpReq.Header.Set("Content-Type", options.contentType)
+	}
+
+	log.V(4).Infof("Proxying %s %s to %s", method, path, target)
+	resp, err := c.rc.Client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("proxy %s %s to %s: %w", method, path, target, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("proxy %s %s to %s: %w", method, path, target, err)
+	}
+	return &Response{StatusCode: resp.StatusCode, Body: data}, nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestTarget_NameSpec(t *testing.T) {
+	tests := []struct {
+		target   Target
+		expected string
+	}{
+		{target: Service("default", "web", ""), expected: "web"},
+		{target: Service("default", "web", "http"), expected: "web:http"},
+		{target: Pod("default", "web-0", "8443").WithHTTPS(), expected: "https:web-0:8443"},
+		{target: Service("default", "web", "").WithHTTPS(), expected: "https:web:"},
+	}
+	for _, test := range tests {
+		if spec := test.target.nameSpec(); spec != test.expected {
+			t.Errorf("expected %s, got %s", test.expected, spec)
+		}
+	}
+}
+
+func TestClient(t *testing.T) {
+	var got struct {
+		method, path, query, contentType, header, body string
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got.method, got.path, got.query = r.Method, r.URL.Path, r.URL.RawQuery
+		got.contentType, got.header, got.body = r.Header.Get("Content-Type"), r.Header.Get("X-Test"), string(body)
+		if r.URL.Path == "/api/v1/namespaces/apps/pods/web-0:8080/proxy/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		w.Write([]byte("reply"))
+	}))
+	defer server.Close()
+
+	c, err := New(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := c.Get(context.TODO(), Service("apps", "web", "http"), "/healthz", WithQueryParam("verbose", "1"), WithHeader("X-Test", "yes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || string(resp.Body) != "reply" {
+		t.Errorf("unexpected response: %d %s", resp.StatusCode, resp.Body)
+	}
+	if got.method != http.MethodGet || got.path != "/api/v1/namespaces/apps/services/web:http/proxy/healthz" || got.query != "verbose=1" || got.header != "yes" {
+		t.Errorf("unexpected request: %+v", got)
+	}
+
+	if _, err = c.Post(context.TODO(), Service("apps", "web", ""), "orders", []byte(`{"id":1}`)); err != nil {
+		t.Fatal(err)
+	}
+	if got.method != http.MethodPost || got.path != "/api/v1/namespaces/apps/services/web/proxy/orders" || got.body != `{"id":1}` || got.contentType != "application/json" {
+		t.Errorf("unexpected request: %+v", got)
+	}
+
+	resp, err = c.Get(context.TODO(), Pod("apps", "web-0", "8080"), "missing")
+	if err != nil {
+		t.Fatalf("expected the error status to be returned as a response, got %v", err)
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,216 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package store provides helpers to save structured values in, and load them from,
+// ConfigMaps and Secrets. This can be used to share state across steps or test
+// packages through the cluster.
+package store
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	log "k8s.io/klog/v2"
+
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+)
+
+const (
+	// DefaultKey is the ConfigMap or Secret data key used to store values
+	DefaultKey = "data"
+	// MaxSize is the maximum size of the data stored in a ConfigMap or Secret
+	MaxSize = v1.MaxSecretSize
+)
+
+// Options configures how values are stored
+type Options struct {
+	Codec Codec
+	Key   string
+	// EncryptionKey, when set, is used to encrypt the values with AES-GCM.
+	// It must be 16, 24, or 32 bytes long.
+	EncryptionKey []byte
+}
+
+type Option func(*Options)
+
+// WithCodec sets the codec used to encode values (defaults to JSON)
+func WithCodec(c Codec) Option {
+	return func(o *Options) {
+		o.Codec = c
+	}
+}
+
+// WithKey sets the data key used to store the value (defaults to DefaultKey),
+// allowing several values to be stored in the same object
+func WithKey(key string) Option {
+	return func(o *Options) {
+		o.Key = key
+	}
+}
+
+// WithEncryption encrypts the stored values with the AES key
+func WithEncryption(key []byte) Option {
+	return func(o *Options) {
+		o.EncryptionKey = key
+	}
+}
+
+func newOptions(opts []Option) *Options {
+	o := &Options{Codec: JSON, Key: DefaultKey}
+	for _, fn := range opts {
+		fn(o)
+	}
+	return o
+}
+
+// binary reports whether the stored data is not text
+func (o *Options) binary() bool {
+	return o.Codec.Binary() || o.EncryptionKey != nil
+}
+
+// Encode encodes v, encrypting it if configured, and checks that it fits in a ConfigMap or Secret
+func Encode(v interface{}, opts ...Option) ([]byte, error) {
+	o := newOptions(opts)
+	data, err := o.Codec.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("store encode: %w", err)
+	}
+	if o.EncryptionKey != nil {
+		if data, err = encrypt(o.EncryptionKey, data); err != nil {
+			return nil, fmt.Errorf("store encode: encrypt: %w", err)
+		}
+	}
+	if len(data) > MaxSize {
+		return nil, fmt.Errorf("store encode: encoded size %d exceeds the maximum size of %d bytes", len(data), MaxSize)
+	}
+	return data, nil
+}
+
+// Decode decodes data previously encoded with Encode into v
+func Decode(data []byte, v interface{}, opts ...Option) error {
+	o := newOptions(opts)
+	var err error
+	if o.EncryptionKey != nil {
+		if data, err = decrypt(o.EncryptionKey, data); err != nil {
+			return fmt.Errorf("store decode: decrypt: %w", err)
+		}
+	}
+	if err := o.Codec.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("store decode: %w", err)
+	}
+	return nil
+}
+
+// SaveConfigMap encodes v and stores it in the named ConfigMap, which is
+// created if it does not exist. Text encoded values are stored in the
+// ConfigMap data, binary or encrypted values in its binary data.
+func SaveConfigMap(ctx context.Context, r *resources.Resources, name, namespace string, v interface{}, opts ...Option) error {
+	o := newOptions(opts)
+	data, err := Encode(v, opts...)
+	if err != nil {
+		return err
+	}
+
+	cm := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	return createOrUpdate(ctx, r, cm, func() {
+		if o.binary() {
+			if cm.BinaryData == nil {
+				cm.BinaryData = make(map[string][]byte)
+			}
+			cm.BinaryData[o.Key] = data
+			delete(cm.Data, o.Key)
+			return
+		}
+		if cm.Data == nil {
+			cm.Data = make(map[string]string)
+		}
+		cm.Data[o.Key] = string(data)
+		delete(cm.BinaryData, o.Key)
+	})
+}
+
+// LoadConfigMap decodes the value stored in the named ConfigMap into v
+func LoadConfigMap(ctx context.Context, r *resources.Resources, name, namespace string, v interface{}, opts ...Option) error {
+	o := newOptions(opts)
+	cm := &v1.ConfigMap{}
+	if err := r.Get(ctx, name, namespace, cm); err != nil {
+		return fmt.Errorf("store load configmap: %w", err)
+	}
+	if data, ok := cm.BinaryData[o.Key]; ok {
+		return Decode(data, v, opts...)
+	}
+	if data, ok := cm.Data[o.Key]; ok {
+		return Decode([]byte(data), v, opts...)
+	}
+	return fmt.Errorf("store load configmap: key %q not found in %s/%s", o.Key, namespace, name)
+}
+
+// SaveSecret encodes v and stores it in the named Secret, which is created if it does not exist
+func SaveSecret(ctx context.Context, r *resources.Resources, name, namespace string, v interface{}, opts ...Option) error {
+	o := newOptions(opts)
+	data, err := Encode(v, opts...)
+	if err != nil {
+		return err
+	}
+
+	secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	return createOrUpdate(ctx, r, secret, func() {
+		if secret.Data == nil {
+			secret.Data = make(map[string][]byte)
+		}
+		secret.Data[o.Key] = data
+	})
+}
+
+// LoadSecret decodes the value stored in the named Secret into v
+func LoadSecret(ctx context.Context, r *resources.Resources, name, namespace string, v interface{}, opts ...Option) error {
+	o := newOptions(opts)
+	secret := &v1.Secret{}
+	if err := r.Get(ctx, name, namespace, secret); err != nil {
+		return fmt.Errorf("store load secret: %w", err)
+	}
+	data, ok := secret.Data[o.Key]
+	if !ok {
+		return fmt.Errorf("store load secret: key %q not found in %s/%s", o.Key, namespace, name)
+	}
+	return Decode(data, v, opts...)
+}
+
+// createOrUpdate gets the current state of obj, applies mutate and updates it,
+// or creates obj with mutate applied if it does not exist.
+func createOrUpdate(ctx context.Context, r *resources.Resources, obj k8s.Object, mutate func()) error {
+	err := r.Get(ctx, obj.GetName(), obj.GetNamespace(), obj)
+	if apierrors.IsNotFound(err) {
+		log.V(4).Infof("store: creating %s/%s", obj.GetNamespace(), obj.GetName())
+		mutate()
+		if err := r.Create(ctx, obj); err != nil {
+			return fmt.Errorf("store create: %w", err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("store get: %w", err)
+	}
+	mutate()
+	if err := r.Update(ctx, obj); err != nil {
+		return fmt.Errorf("store update: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type state struct {
+	Name    string            `json:"name"`
+	Count   int               `json:"count"`
+	Targets map[string]string `json:"targets"`
+}
+
+func TestEncodeDecode(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	in := state{Name: "cluster-a", Count: 3, Targets: map[string]string{"a": "b"}}
+
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "json"},
+		{name: "yaml", opts: []Option{WithCodec(YAML)}},
+		{name: "gob", opts: []Option{WithCodec(Gob)}},
+		{name: "encrypted", opts: []Option{WithCodec(YAML), WithEncryption(key)}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := Encode(in, test.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out state
+			if err := Decode(data, &out, test.opts...); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(in, out) {
+				t.Errorf("round trip mismatch: expected %+v, got %+v", in, out)
+			}
+		})
+	}
+}
+
+func TestEncode_Encrypted(t *testing.T) {
+	data, err := Encode(state{Name: "secret-name"}, WithEncryption(bytes.Repeat([]byte{1}, 16)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("secret-name")) {
+		t.Error("expected encrypted data not to contain the plain value")
+	}
+	if err := Decode(data, &state{}, WithEncryption(bytes.Repeat([]byte{2}, 16))); err == nil {
+		t.Error("expected decode with the wrong key to fail")
+	}
+}
+
+func TestEncode_MaxSize(t *testing.T) {
+	_, err := Encode(strings.Repeat("a", MaxSize))
+	if err == nil || !strings.Contains(err.Error(), "exceeds the maximum size") {
+		t.Errorf("expected size error, got: %v", err)
+	}
+}
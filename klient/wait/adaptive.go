@@ -0,0 +1,118 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wait
+
+import (
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimachinerywait "k8s.io/apimachinery/pkg/util/wait"
+	log "k8s.io/klog/v2"
+)
+
+// latencyFactor is the ratio between the poll interval and the observed duration
+// of the condition checks, so that polling uses a bounded share of the API server time
+const latencyFactor = 10
+
+// Adaptive configures the poll interval to adapt to the API server load
+type Adaptive struct {
+	// MinInterval is the shortest interval used when the API server is responsive
+	MinInterval time.Duration
+	// MaxInterval is the longest interval used when the API server is under pressure
+	MaxInterval time.Duration
+}
+
+// WithAdaptiveInterval configures the Wait checks to adapt the poll interval, between min and max,
+// to the API server: the interval grows with the observed duration of the condition checks and
+// doubles when the API server throttles requests (429) or times out, then shrinks back when the
+// API server is responsive again. Throttling and timeout errors do not fail the wait.
+//
+// The interval configured by WithInterval is used as the starting interval. This option is
+// ignored when WithWatch is used.
+func WithAdaptiveInterval(min, max time.Duration) Option {
+	return func(options *Options) {
+		options.Adaptive = &Adaptive{MinInterval: min, MaxInterval: max}
+	}
+}
+
+// next returns the interval to wait for after a condition check which took latency
+// and returned err, given the current interval.
+func (a *Adaptive) next(current, latency time.Duration, err error) time.Duration {
+	var next time.Duration
+	if isThrottled(err) {
+		next = 2 * current
+		if delay, ok := apierrors.SuggestsClientDelay(err); ok && time.Duration(delay)*time.Second > next {
+			next = time.Duration(delay) * time.Second
+		}
+	} else {
+		// move half way towards the target to smooth latency spikes
+		next = (current + latencyFactor*latency) / 2
+	}
+
+	if next < a.MinInterval {
+		return a.MinInterval
+	}
+	if next > a.MaxInterval {
+		return a.MaxInterval
+	}
+	return next
+}
+
+// isThrottled reports whether err indicates the API server is under pressure
+func isThrottled(err error) bool {
+	return apierrors.IsTooManyRequests(err) || apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err)
+}
+
+// forAdaptive evaluates conditionFunc with an interval adapted after each check, until the
+// condition is met, the timeout elapses, or StopChan is closed.
+func forAdaptive(conditionFunc apimachinerywait.ConditionFunc, options *Options) error {
+	var timeout <-chan time.Time
+	if options.StopChan == nil {
+		timer := time.NewTimer(options.Timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	interval := options.Interval
+	wait := interval
+	if options.Immediate {
+		wait = 0
+	}
+	for {
+		select {
+		case <-time.After(wait):
+		case <-timeout:
+			return apimachinerywait.ErrWaitTimeout
+		case <-options.StopChan:
+			return apimachinerywait.ErrWaitTimeout
+		}
+
+		start := time.Now()
+		done, err := conditionFunc()
+		latency := time.Since(start)
+		if err != nil && !isThrottled(err) {
+			return err
+		}
+		if done {
+			return nil
+		}
+
+		interval = options.Adaptive.next(interval, latency, err)
+		log.V(4).Infof("wait: condition check took %s, next check in %s", latency, interval)
+		wait = interval
+	}
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wait
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+func TestAdaptiveNext(t *testing.T) {
+	a := &Adaptive{MinInterval: 100 * time.Millisecond, MaxInterval: 10 * time.Second}
+
+	tests := []struct {
+		name     string
+		current  time.Duration
+		latency  time.Duration
+		err      error
+		expected time.Duration
+	}{
+		{name: "fast api server", current: time.Second, latency: 10 * time.Millisecond, expected: 550 * time.Millisecond},
+		{name: "fast api server at min", current: 100 * time.Millisecond, latency: time.Millisecond, expected: 100 * time.Millisecond},
+		{name: "slow api server", current: time.Second, latency: 500 * time.Millisecond, expected: 3 * time.Second},
+		{name: "throttled", current: time.Second, err: apierrors.NewTooManyRequests("slow down", 0), expected: 2 * time.Second},
+		{name: "throttled with retry after", current: time.Second, err: apierrors.NewTooManyRequests("slow down", 5), expected: 5 * time.Second},
+		{name: "throttled at max", current: 8 * time.Second, err: apierrors.NewTooManyRequests("slow down", 0), expected: 10 * time.Second},
+		{name: "other error", current: time.Second, latency: 100 * time.Millisecond, err: errors.New("boom"), expected: time.Second},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := a.next(test.current, test.latency, test.err); got != test.expected {
+				t.Errorf("expected next interval %s, got %s", test.expected, got)
+			}
+		})
+	}
+}
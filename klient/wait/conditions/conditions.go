@@ -29,18 +29,30 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	apimachinerywait "k8s.io/apimachinery/pkg/util/wait"
 
+	"sigs.k8s.io/e2e-framework/klient/events"
 	"sigs.k8s.io/e2e-framework/klient/k8s"
 	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
 )
 
 type Condition struct {
 	resources *resources.Resources
+	ctx       context.Context
 }
 
 // New is used to create a new Condition that can be used to perform a series of pre-defined wait checks
 // against a resource in question
 func New(r *resources.Resources) *Condition {
-	return &Condition{resources: r}
+	return &Condition{resources: r, ctx: context.TODO()}
+}
+
+// WithContext returns a copy of the Condition sending its requests with ctx, i.e. the context of a feature
+// assessment, so that they are cancelled with ctx and counted under its scope (see the apicalls package).
+// Argument ctx cannot be nil.
+func (c *Condition) WithContext(ctx context.Context) *Condition {
+	if ctx == nil {
+		panic("nil context")
+	}
+	return &Condition{resources: c.resources, ctx: ctx}
 }
 
 func (c *Condition) namespacedName(obj k8s.Object) string {
@@ -53,7 +65,7 @@ func (c *Condition) namespacedName(obj k8s.Object) string {
 func (c *Condition) ResourceScaled(obj k8s.Object, scaleFetcher func(object k8s.Object) int32, replica int32) apimachinerywait.ConditionFunc {
 	return func() (done bool, err error) {
 		log.V(4).InfoS("Checking for resource to be scaled", "resource", c.namespacedName(obj), "replica", replica)
-		if err := c.resources.Get(context.TODO(), obj.GetName(), obj.GetNamespace(), obj); err != nil {
+		if err := c.resources.Get(c.ctx, obj.GetName(), obj.GetNamespace(), obj); err != nil {
 			return false, nil
 		}
 		return scaleFetcher(obj) == replica, nil
@@ -64,7 +76,7 @@ func (c *Condition) ResourceScaled(obj k8s.Object, scaleFetcher func(object k8s.
 // be leveraged for checking fields on a resource that may not be immediately present upon creation.
 func (c *Condition) ResourceMatch(obj k8s.Object, matchFetcher func(object k8s.Object) bool) apimachinerywait.ConditionFunc {
 	return func() (done bool, err error) {
-		if err := c.resources.Get(context.TODO(), obj.GetName(), obj.GetNamespace(), obj); err != nil {
+		if err := c.resources.Get(c.ctx, obj.GetName(), obj.GetNamespace(), obj); err != nil {
 			return false, nil
 		}
 		return matchFetcher(obj), nil
@@ -81,7 +93,7 @@ func (c *Condition) ResourceListN(list k8s.ObjectList, n int, listOptions ...res
 // accepts list options and a match function that can be used to adjust the set of objects queried for in the List resource operation.
 func (c *Condition) ResourceListMatchN(list k8s.ObjectList, n int, matchFetcher func(object k8s.Object) bool, listOptions ...resources.ListOption) apimachinerywait.ConditionFunc {
 	return func() (done bool, err error) {
-		if err := c.resources.List(context.TODO(), list, listOptions...); err != nil {
+		if err := c.resources.List(c.ctx, list, listOptions...); err != nil {
 			return false, nil
 		}
 		var found int
@@ -129,7 +141,7 @@ func (c *Condition) ResourcesMatch(list k8s.ObjectList, matchFetcher func(object
 		found := 0
 		for obj, created := range objects {
 			if !created {
-				if err := c.resources.Get(context.TODO(), obj.GetName(), obj.GetNamespace(), obj); errors.IsNotFound(err) {
+				if err := c.resources.Get(c.ctx, obj.GetName(), obj.GetNamespace(), obj); errors.IsNotFound(err) {
 					continue
 				} else if err != nil {
 					return false, err
@@ -167,7 +179,7 @@ func (c *Condition) ResourcesDeleted(list k8s.ObjectList) apimachinerywait.Condi
 	return func() (done bool, err error) {
 		for obj, created := range objects {
 			if created {
-				if err := c.resources.Get(context.TODO(), obj.GetName(), obj.GetNamespace(), obj); errors.IsNotFound(err) {
+				if err := c.resources.Get(c.ctx, obj.GetName(), obj.GetNamespace(), obj); errors.IsNotFound(err) {
 					delete(objects, obj)
 				} else if err != nil {
 					return false, err
@@ -187,7 +199,7 @@ func (c *Condition) ResourcesDeleted(list k8s.ObjectList) apimachinerywait.Condi
 func (c *Condition) ResourceDeleted(obj k8s.Object) apimachinerywait.ConditionFunc {
 	return func() (done bool, err error) {
 		log.V(4).InfoS("Checking for resource to be garbage collected", "resource", c.namespacedName(obj))
-		if err := c.resources.Get(context.Background(), obj.GetName(), obj.GetNamespace(), obj); err != nil {
+		if err := c.resources.Get(c.ctx, obj.GetName(), obj.GetNamespace(), obj); err != nil {
 			if errors.IsNotFound(err) {
 				return true, nil
 			}
@@ -203,7 +215,7 @@ func (c *Condition) ResourceDeleted(obj k8s.Object) apimachinerywait.ConditionFu
 func (c *Condition) JobConditionMatch(job k8s.Object, conditionType batchv1.JobConditionType, conditionState v1.ConditionStatus) apimachinerywait.ConditionFunc {
 	return func() (done bool, err error) {
 		log.V(4).InfoS("Checking for condition match", "resource", c.namespacedName(job), "state", conditionState, "conditionType", conditionType)
-		if err := c.resources.Get(context.TODO(), job.GetName(), job.GetNamespace(), job); err != nil {
+		if err := c.resources.Get(c.ctx, job.GetName(), job.GetNamespace(), job); err != nil {
 			return false, err
 		}
 		status := job.(*batchv1.Job).Status
@@ -220,7 +232,7 @@ func (c *Condition) JobConditionMatch(job k8s.Object, conditionType batchv1.JobC
 // DeploymentConditionMatch is a helper function that can be used to check a specific condition match for the Deployment in question.
 func (c *Condition) DeploymentConditionMatch(deployment k8s.Object, conditionType appsv1.DeploymentConditionType, conditionState v1.ConditionStatus) apimachinerywait.ConditionFunc {
 	return func() (done bool, err error) {
-		if err := c.resources.Get(context.TODO(), deployment.GetName(), deployment.GetNamespace(), deployment); err != nil {
+		if err := c.resources.Get(c.ctx, deployment.GetName(), deployment.GetNamespace(), deployment); err != nil {
 			return false, err
 		}
 		for _, cond := range deployment.(*appsv1.Deployment).Status.Conditions {
@@ -237,7 +249,7 @@ func (c *Condition) DeploymentConditionMatch(deployment k8s.Object, conditionTyp
 func (c *Condition) PodConditionMatch(pod k8s.Object, conditionType v1.PodConditionType, conditionState v1.ConditionStatus) apimachinerywait.ConditionFunc {
 	return func() (done bool, err error) {
 		log.V(4).InfoS("Checking for condition match", "resource", c.namespacedName(pod), "state", conditionState, "conditionType", conditionType)
-		if err := c.resources.Get(context.TODO(), pod.GetName(), pod.GetNamespace(), pod); err != nil {
+		if err := c.resources.Get(c.ctx, pod.GetName(), pod.GetNamespace(), pod); err != nil {
 			return false, err
 		}
 		status := pod.(*v1.Pod).Status
@@ -257,7 +269,7 @@ func (c *Condition) PodConditionMatch(pod k8s.Object, conditionType v1.PodCondit
 func (c *Condition) PodPhaseMatch(pod k8s.Object, phase v1.PodPhase) apimachinerywait.ConditionFunc {
 	return func() (done bool, err error) {
 		log.V(4).InfoS("Checking for phase match", "resource", c.namespacedName(pod), "phase", phase)
-		if err := c.resources.Get(context.Background(), pod.GetName(), pod.GetNamespace(), pod); err != nil {
+		if err := c.resources.Get(c.ctx, pod.GetName(), pod.GetNamespace(), pod); err != nil {
 			return false, err
 		}
 		log.V(4).InfoS("Current phase", "phase", pod.(*v1.Pod).Status.Phase)
@@ -291,3 +303,48 @@ func (c *Condition) JobCompleted(job k8s.Object) apimachinerywait.ConditionFunc
 func (c *Condition) JobFailed(job k8s.Object) apimachinerywait.ConditionFunc {
 	return c.JobConditionMatch(job, batchv1.JobFailed, v1.ConditionTrue)
 }
+
+// NodesReady is a helper function used to check if at least n nodes have the v1.NodeReady
+// condition in the v1.ConditionTrue state
+func (c *Condition) NodesReady(n int) apimachinerywait.ConditionFunc {
+	return c.ResourceListMatchN(&v1.NodeList{}, n, func(object k8s.Object) bool {
+		for _, cond := range object.(*v1.Node).Status.Conditions {
+			if cond.Type == v1.NodeReady {
+				return cond.Status == v1.ConditionTrue
+			}
+		}
+		return false
+	})
+}
+
+// EventEmitted is a helper function used to check if an event matching all the matchers, i.e.
+// events.WithReason("Pulled") and events.WithType("Normal"), has been emitted for the object
+func (c *Condition) EventEmitted(obj k8s.Object, matchers ...events.Matcher) apimachinerywait.ConditionFunc {
+	m := events.All(matchers...)
+	return func() (done bool, err error) {
+		log.V(4).InfoS("Checking for event", "resource", c.namespacedName(obj), "event", m.Desc)
+		records, err := events.ListFor(c.ctx, c.resources, obj)
+		if err != nil {
+			return false, nil
+		}
+		for _, r := range records {
+			if m.Match(r) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+}
+
+// EventsMatch is a helper function used to check if the events emitted for the object satisfy
+// all the expectations, i.e. events.InOrder(events.WithReason("Created"), events.WithReason("Started"))
+func (c *Condition) EventsMatch(obj k8s.Object, expectations ...events.Expectation) apimachinerywait.ConditionFunc {
+	return func() (done bool, err error) {
+		log.V(4).InfoS("Checking for events to match expectations", "resource", c.namespacedName(obj))
+		records, err := events.ListFor(c.ctx, c.resources, obj)
+		if err != nil {
+			return false, nil
+		}
+		return events.Verify(records, expectations...) == nil, nil
+	}
+}
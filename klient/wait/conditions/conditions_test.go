@@ -0,0 +1,76 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conditions
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+
+	"sigs.k8s.io/e2e-framework/klient/apicalls"
+	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+)
+
+func TestCondition_WithContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api":
+			w.Write([]byte(`{"kind":"APIVersions","versions":["v1"]}`))
+		case "/api/v1":
+			w.Write([]byte(`{"kind":"APIResourceList","groupVersion":"v1","resources":[
+				{"name":"pods","namespaced":true,"kind":"Pod","verbs":["get"]}]}`))
+		case "/apis":
+			w.Write([]byte(`{"kind":"APIGroupList","groups":[]}`))
+		case "/api/v1/namespaces/ns/pods/p1":
+			w.Write([]byte(`{"kind":"Pod","apiVersion":"v1","metadata":{"name":"p1","namespace":"ns"},"status":{"phase":"Running"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	counter := apicalls.NewCounter()
+	r, err := resources.New(&rest.Config{Host: server.URL, WrapTransport: counter.Wrap})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p1", Namespace: "ns"}}
+
+	ctx := apicalls.WithScope(context.Background(), "feature")
+	done, err := New(r).WithContext(ctx).PodRunning(pod)()
+	if err != nil || !done {
+		t.Fatalf("expected pod to be running, got done=%t err=%v", done, err)
+	}
+	if count, _ := counter.Total("feature"); count != 1 {
+		t.Errorf("expected the request to be counted under the scope of the context, got %d", count)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if done, _ := New(r).WithContext(cancelled).PodRunning(pod)(); done {
+		t.Error("expected no request to be sent with a cancelled context")
+	}
+	if count, _ := counter.Total("feature"); count != 1 {
+		t.Errorf("unexpected requests sent with a cancelled context: %d", count)
+	}
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conditions
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	apimachinerywait "k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/jsonpath"
+	log "k8s.io/klog/v2"
+
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+)
+
+// ResourceJSONPathMatch is a helper function used to check if the field of the resource under question, selected
+// by the JSONPath expression, has reached the expected value. This is the equivalent of
+// `kubectl wait --for=jsonpath='{.status.phase}'=Running`. The expression can be provided with or without the
+// surrounding braces.
+//
+// Arbitrary resources can be checked by passing an *unstructured.Unstructured object with its
+// GroupVersionKind, name, and namespace set.
+func (c *Condition) ResourceJSONPathMatch(obj k8s.Object, jsonPathExpr, value string) apimachinerywait.ConditionFunc {
+	return c.resourceJSONPathMatchFunc(obj, jsonPathExpr, func(v string) bool { return v == value })
+}
+
+// ResourceJSONPathMatchRegex is a helper function used to check if the field of the resource under question, selected
+// by the JSONPath expression, matches the provided regular expression.
+func (c *Condition) ResourceJSONPathMatchRegex(obj k8s.Object, jsonPathExpr string, re *regexp.Regexp) apimachinerywait.ConditionFunc {
+	return c.resourceJSONPathMatchFunc(obj, jsonPathExpr, re.MatchString)
+}
+
+func (c *Condition) resourceJSONPathMatchFunc(obj k8s.Object, jsonPathExpr string, match func(string) bool) apimachinerywait.ConditionFunc {
+	parser, err := newJSONPathParser(jsonPathExpr)
+	if err != nil {
+		return func() (done bool, err error) { return false, err }
+	}
+	return func() (done bool, err error) {
+		log.V(4).InfoS("Checking for jsonpath match", "resource", c.namespacedName(obj), "jsonpath", jsonPathExpr)
+		if err := c.resources.Get(c.ctx, obj.GetName(), obj.GetNamespace(), obj); err != nil {
+			return false, nil
+		}
+		values, err := jsonPathValues(parser, obj)
+		if err != nil {
+			return false, err
+		}
+		log.V(4).InfoS("Current jsonpath values", "values", values)
+		for _, v := range values {
+			if match(v) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+}
+
+// ResourceJSONPathMatchGVR is a helper function used to check if the field of the resource identified by its
+// GroupVersionResource, namespace (empty for cluster scoped resources), and name, selected by the JSONPath
+// expression, has reached the expected value. The resource is fetched with the dynamic client, so that neither
+// a Go type nor the kind of the resource is needed. This is the equivalent of
+// `kubectl wait --for=jsonpath='{.status.phase}'=Running <resource>/<name>`. The requests are sent with the context
+// of the Condition, see WithContext.
+func (c *Condition) ResourceJSONPathMatchGVR(client dynamic.Interface, gvr schema.GroupVersionResource, namespace, name, jsonPathExpr, value string) apimachinerywait.ConditionFunc {
+	return c.resourceJSONPathMatchGVRFunc(client, gvr, namespace, name, jsonPathExpr, func(v string) bool { return v == value })
+}
+
+// ResourceJSONPathMatchRegexGVR is the equivalent of ResourceJSONPathMatchGVR checking if the selected field
+// matches the provided regular expression.
+func (c *Condition) ResourceJSONPathMatchRegexGVR(client dynamic.Interface, gvr schema.GroupVersionResource, namespace, name, jsonPathExpr string, re *regexp.Regexp) apimachinerywait.ConditionFunc {
+	return c.resourceJSONPathMatchGVRFunc(client, gvr, namespace, name, jsonPathExpr, re.MatchString)
+}
+
+func (c *Condition) resourceJSONPathMatchGVRFunc(client dynamic.Interface, gvr schema.GroupVersionResource, namespace, name, jsonPathExpr string, match func(string) bool) apimachinerywait.ConditionFunc {
+	parser, err := newJSONPathParser(jsonPathExpr)
+	if err != nil {
+		return func() (done bool, err error) { return false, err }
+	}
+	return func() (done bool, err error) {
+		log.V(4).InfoS("Checking for jsonpath match", "resource", fmt.Sprintf("%s [%s/%s]", gvr.String(), namespace, name), "jsonpath", jsonPathExpr)
+		obj, err := client.Resource(gvr).Namespace(namespace).Get(c.ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, nil
+		}
+		values, err := jsonPathValues(parser, obj)
+		if err != nil {
+			return false, err
+		}
+		log.V(4).InfoS("Current jsonpath values", "values", values)
+		for _, v := range values {
+			if match(v) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+}
+
+// newJSONPathParser parses the JSONPath expression, adding the braces expected by
+// the parser when they are omitted (i.e. .status.phase)
+func newJSONPathParser(expr string) (*jsonpath.JSONPath, error) {
+	expr = strings.TrimSpace(expr)
+	if !strings.HasPrefix(expr, "{") {
+		expr = fmt.Sprintf("{%s}", expr)
+	}
+	parser := jsonpath.New("condition").AllowMissingKeys(true)
+	if err := parser.Parse(expr); err != nil {
+		return nil, fmt.Errorf("condition: invalid jsonpath expression %s: %w", expr, err)
+	}
+	return parser, nil
+}
+
+// jsonPathValues returns the string representation of the values selected by the parser on obj
+func jsonPathValues(parser *jsonpath.JSONPath, obj k8s.Object) ([]string, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, fmt.Errorf("condition: converting %T to unstructured: %w", obj, err)
+	}
+	results, err := parser.FindResults(content)
+	if err != nil {
+		return nil, fmt.Errorf("condition: jsonpath lookup: %w", err)
+	}
+	var values []string
+	for _, result := range results {
+		for _, r := range result {
+			values = append(values, fmt.Sprintf("%v", r.Interface()))
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conditions
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+)
+
+func TestJSONPathValues(t *testing.T) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Labels: map[string]string{"app": "e2e"}},
+		Status:     v1.PodStatus{Phase: v1.PodRunning, Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}}},
+	}
+	tests := []struct {
+		name     string
+		expr     string
+		expected []string
+	}{
+		{name: "without braces", expr: ".status.phase", expected: []string{"Running"}},
+		{name: "with braces", expr: "{.metadata.labels.app}", expected: []string{"e2e"}},
+		{name: "filter", expr: `{.status.conditions[?(@.type=="Ready")].status}`, expected: []string{"True"}},
+		{name: "missing key", expr: ".status.podIP", expected: nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parser, err := newJSONPathParser(test.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			values, err := jsonPathValues(parser, pod)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(values) != len(test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, values)
+			}
+			for i := range values {
+				if values[i] != test.expected[i] {
+					t.Errorf("expected %v, got %v", test.expected, values)
+				}
+			}
+		})
+	}
+
+	if _, err := newJSONPathParser("{.status[}"); err == nil {
+		t.Error("expected invalid expression to fail parsing")
+	}
+}
+
+func TestResourceJSONPathMatchGVR(t *testing.T) {
+	widget := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata":   map[string]interface{}{"name": "w1", "namespace": "ns"},
+		"status":     map[string]interface{}{"phase": "Ready"},
+	}}
+	gvr := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+	client := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "WidgetList"}, widget)
+	cond := New(nil).WithContext(context.Background())
+
+	tests := []struct {
+		name      string
+		condition func() (bool, error)
+		done      bool
+	}{
+		{name: "match", condition: cond.ResourceJSONPathMatchGVR(client, gvr, "ns", "w1", ".status.phase", "Ready"), done: true},
+		{name: "no match", condition: cond.ResourceJSONPathMatchGVR(client, gvr, "ns", "w1", ".status.phase", "Pending")},
+		{name: "missing object", condition: cond.ResourceJSONPathMatchGVR(client, gvr, "ns", "w2", ".status.phase", "Ready")},
+		{name: "regex", condition: cond.ResourceJSONPathMatchRegexGVR(client, gvr, "ns", "w1", "{.status.phase}", regexp.MustCompile("^Rea")), done: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			done, err := test.condition()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if done != test.done {
+				t.Errorf("expected done=%t, got %t", test.done, done)
+			}
+		})
+	}
+}
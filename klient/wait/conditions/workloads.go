@@ -0,0 +1,161 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conditions
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	apimachinerywait "k8s.io/apimachinery/pkg/util/wait"
+	log "k8s.io/klog/v2"
+
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+)
+
+// DeploymentRolledOut is a helper function used to check if the rollout of the Deployment is complete, the equivalent
+// of `kubectl rollout status`: the latest generation is observed, all the replicas are updated and available, and no
+// old replicas remain. The wait stops with an error if the rollout exceeded its progress deadline.
+func (c *Condition) DeploymentRolledOut(deployment k8s.Object) apimachinerywait.ConditionFunc {
+	return c.workloadMatch(deployment, "deployment rollout", func(obj k8s.Object) (bool, error) {
+		d, ok := obj.(*appsv1.Deployment)
+		if !ok {
+			return false, fmt.Errorf("condition: expected *appsv1.Deployment, got %T", obj)
+		}
+		return deploymentRolledOut(d)
+	})
+}
+
+// StatefulSetReady is a helper function used to check if the StatefulSet has all its replicas ready and updated
+// to its latest revision, taking the partition of a rolling update into account.
+func (c *Condition) StatefulSetReady(statefulSet k8s.Object) apimachinerywait.ConditionFunc {
+	return c.workloadMatch(statefulSet, "statefulset readiness", func(obj k8s.Object) (bool, error) {
+		sts, ok := obj.(*appsv1.StatefulSet)
+		if !ok {
+			return false, fmt.Errorf("condition: expected *appsv1.StatefulSet, got %T", obj)
+		}
+		return statefulSetReady(sts), nil
+	})
+}
+
+// DaemonSetReady is a helper function used to check if the DaemonSet pods are updated and ready on all the
+// nodes they are scheduled on.
+func (c *Condition) DaemonSetReady(daemonSet k8s.Object) apimachinerywait.ConditionFunc {
+	return c.workloadMatch(daemonSet, "daemonset readiness", func(obj k8s.Object) (bool, error) {
+		ds, ok := obj.(*appsv1.DaemonSet)
+		if !ok {
+			return false, fmt.Errorf("condition: expected *appsv1.DaemonSet, got %T", obj)
+		}
+		return daemonSetReady(ds), nil
+	})
+}
+
+// PersistentVolumeClaimBound is a helper function used to check if the PersistentVolumeClaim has reached
+// the v1.ClaimBound phase. The wait stops with an error if the claim is lost.
+func (c *Condition) PersistentVolumeClaimBound(pvc k8s.Object) apimachinerywait.ConditionFunc {
+	return c.workloadMatch(pvc, "persistentvolumeclaim bound", func(obj k8s.Object) (bool, error) {
+		claim, ok := obj.(*v1.PersistentVolumeClaim)
+		if !ok {
+			return false, fmt.Errorf("condition: expected *v1.PersistentVolumeClaim, got %T", obj)
+		}
+		if claim.Status.Phase == v1.ClaimLost {
+			return false, fmt.Errorf("condition: persistentvolumeclaim %s/%s lost its volume", claim.Namespace, claim.Name)
+		}
+		return claim.Status.Phase == v1.ClaimBound, nil
+	})
+}
+
+// CronJobScheduled is a helper function used to check if the CronJob has scheduled at least one Job
+func (c *Condition) CronJobScheduled(cronJob k8s.Object) apimachinerywait.ConditionFunc {
+	return c.workloadMatch(cronJob, "cronjob schedule", func(obj k8s.Object) (bool, error) {
+		cj, ok := obj.(*batchv1.CronJob)
+		if !ok {
+			return false, fmt.Errorf("condition: expected *batchv1.CronJob, got %T", obj)
+		}
+		return cj.Status.LastScheduleTime != nil, nil
+	})
+}
+
+// CronJobCompleted is a helper function used to check if a Job scheduled by the CronJob has completed successfully
+func (c *Condition) CronJobCompleted(cronJob k8s.Object) apimachinerywait.ConditionFunc {
+	return c.workloadMatch(cronJob, "cronjob completion", func(obj k8s.Object) (bool, error) {
+		cj, ok := obj.(*batchv1.CronJob)
+		if !ok {
+			return false, fmt.Errorf("condition: expected *batchv1.CronJob, got %T", obj)
+		}
+		return cj.Status.LastSuccessfulTime != nil, nil
+	})
+}
+
+// workloadMatch fetches the object and checks it with match. Errors fetching the object are ignored,
+// so that the wait covers objects being created, but errors returned by match stop the wait.
+func (c *Condition) workloadMatch(obj k8s.Object, desc string, match func(obj k8s.Object) (bool, error)) apimachinerywait.ConditionFunc {
+	return func() (done bool, err error) {
+		log.V(4).InfoS("Checking for "+desc, "resource", c.namespacedName(obj))
+		if err := c.resources.Get(c.ctx, obj.GetName(), obj.GetNamespace(), obj); err != nil {
+			return false, nil
+		}
+		return match(obj)
+	}
+}
+
+func deploymentRolledOut(d *appsv1.Deployment) (bool, error) {
+	if d.Generation > d.Status.ObservedGeneration {
+		return false, nil
+	}
+	for _, cond := range d.Status.Conditions {
+		if cond.Type == appsv1.DeploymentProgressing && cond.Reason == "ProgressDeadlineExceeded" {
+			return false, fmt.Errorf("condition: deployment %s/%s exceeded its progress deadline", d.Namespace, d.Name)
+		}
+	}
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	return d.Status.UpdatedReplicas >= replicas &&
+		d.Status.Replicas == d.Status.UpdatedReplicas &&
+		d.Status.AvailableReplicas >= d.Status.UpdatedReplicas, nil
+}
+
+func statefulSetReady(sts *appsv1.StatefulSet) bool {
+	if sts.Generation > sts.Status.ObservedGeneration {
+		return false
+	}
+	replicas := int32(1)
+	if sts.Spec.Replicas != nil {
+		replicas = *sts.Spec.Replicas
+	}
+	if sts.Status.ReadyReplicas < replicas {
+		return false
+	}
+	if sts.Spec.UpdateStrategy.Type != appsv1.RollingUpdateStatefulSetStrategyType {
+		return true
+	}
+	if ru := sts.Spec.UpdateStrategy.RollingUpdate; ru != nil && ru.Partition != nil && *ru.Partition > 0 {
+		return sts.Status.UpdatedReplicas >= replicas-*ru.Partition
+	}
+	return sts.Status.UpdateRevision == sts.Status.CurrentRevision
+}
+
+func daemonSetReady(ds *appsv1.DaemonSet) bool {
+	if ds.Generation > ds.Status.ObservedGeneration {
+		return false
+	}
+	desired := ds.Status.DesiredNumberScheduled
+	return ds.Status.UpdatedNumberScheduled >= desired && ds.Status.NumberReady >= desired
+}
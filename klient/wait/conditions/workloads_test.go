@@ -0,0 +1,152 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conditions
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func int32Ptr(i int32) *int32 { return &i }
+
+func TestDeploymentRolledOut(t *testing.T) {
+	deployment := func(generation int64, status appsv1.DeploymentStatus) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Generation: generation},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+			Status:     status,
+		}
+	}
+	tests := []struct {
+		name       string
+		deployment *appsv1.Deployment
+		expected   bool
+		shouldFail bool
+	}{
+		{
+			name:       "rolled out",
+			deployment: deployment(2, appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2}),
+			expected:   true,
+		},
+		{
+			name:       "generation not observed",
+			deployment: deployment(3, appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2}),
+		},
+		{
+			name:       "replicas not updated",
+			deployment: deployment(2, appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 1, AvailableReplicas: 2}),
+		},
+		{
+			name:       "old replicas remaining",
+			deployment: deployment(2, appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 2, AvailableReplicas: 3}),
+		},
+		{
+			name:       "updated replicas not available",
+			deployment: deployment(2, appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 1}),
+		},
+		{
+			name: "progress deadline exceeded",
+			deployment: deployment(2, appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 1, Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentProgressing, Status: v1.ConditionFalse, Reason: "ProgressDeadlineExceeded"},
+			}}),
+			shouldFail: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			done, err := deploymentRolledOut(test.deployment)
+			if test.shouldFail != (err != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if done != test.expected {
+				t.Errorf("expected %t, got %t", test.expected, done)
+			}
+		})
+	}
+}
+
+func TestStatefulSetReady(t *testing.T) {
+	statefulSet := func(partition *int32, status appsv1.StatefulSetStatus) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Generation: 1},
+			Spec: appsv1.StatefulSetSpec{Replicas: int32Ptr(3), UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+				Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: partition},
+			}},
+			Status: status,
+		}
+	}
+	tests := []struct {
+		name        string
+		statefulSet *appsv1.StatefulSet
+		expected    bool
+	}{
+		{
+			name:        "ready",
+			statefulSet: statefulSet(nil, appsv1.StatefulSetStatus{ObservedGeneration: 1, ReadyReplicas: 3, CurrentRevision: "r2", UpdateRevision: "r2"}),
+			expected:    true,
+		},
+		{
+			name:        "replicas not ready",
+			statefulSet: statefulSet(nil, appsv1.StatefulSetStatus{ObservedGeneration: 1, ReadyReplicas: 2, CurrentRevision: "r2", UpdateRevision: "r2"}),
+		},
+		{
+			name:        "update in progress",
+			statefulSet: statefulSet(nil, appsv1.StatefulSetStatus{ObservedGeneration: 1, ReadyReplicas: 3, CurrentRevision: "r1", UpdateRevision: "r2"}),
+		},
+		{
+			name:        "partition updated",
+			statefulSet: statefulSet(int32Ptr(2), appsv1.StatefulSetStatus{ObservedGeneration: 1, ReadyReplicas: 3, UpdatedReplicas: 1, CurrentRevision: "r1", UpdateRevision: "r2"}),
+			expected:    true,
+		},
+		{
+			name:        "generation not observed",
+			statefulSet: statefulSet(nil, appsv1.StatefulSetStatus{ReadyReplicas: 3, CurrentRevision: "r2", UpdateRevision: "r2"}),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := statefulSetReady(test.statefulSet); got != test.expected {
+				t.Errorf("expected %t, got %t", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestDaemonSetReady(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   appsv1.DaemonSetStatus
+		expected bool
+	}{
+		{name: "ready", status: appsv1.DaemonSetStatus{ObservedGeneration: 1, DesiredNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberReady: 3}, expected: true},
+		{name: "not updated", status: appsv1.DaemonSetStatus{ObservedGeneration: 1, DesiredNumberScheduled: 3, UpdatedNumberScheduled: 2, NumberReady: 3}},
+		{name: "not ready", status: appsv1.DaemonSetStatus{ObservedGeneration: 1, DesiredNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberReady: 2}},
+		{name: "generation not observed", status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberReady: 3}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ds := &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "test", Generation: 1}, Status: test.status}
+			if got := daemonSetReady(ds); got != test.expected {
+				t.Errorf("expected %t, got %t", test.expected, got)
+			}
+		})
+	}
+}
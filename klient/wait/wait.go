@@ -20,6 +20,7 @@ import (
 	"time"
 
 	apimachinerywait "k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
 )
 
 const (
@@ -38,6 +39,11 @@ type Options struct {
 	// Immediate is used to indicate if the apimachinerywait's immediate wait method are to be
 	// called instead of the regular one
 	Immediate bool
+	// Watch is used to evaluate the condition whenever an event is received from the watch
+	// rather than only at every poll interval
+	Watch watch.Interface
+	// Adaptive is used to adapt the poll interval to the API server load
+	Adaptive *Adaptive
 }
 
 type Option func(*Options)
@@ -78,6 +84,19 @@ func WithImmediate() Option {
 	}
 }
 
+// WithWatch configures the Wait checks to be driven by the events received from w instead of
+// only polling. The condition is evaluated right away, then each time an event is received,
+// and at every poll interval as a safety net for missed events. The watch is stopped when the
+// wait returns. A watch can be created using resources.Resources.Watch:
+//
+//   w, err := r.Watch(ctx, &v1.PodList{}, resources.WithFieldSelector("metadata.name=my-pod"))
+//   err = wait.For(conditions.New(r).PodRunning(pod), wait.WithWatch(w))
+func WithWatch(w watch.Interface) Option {
+	return func(options *Options) {
+		options.Watch = w
+	}
+}
+
 // For provides a way to perform poll checks against the kubernetes resource to make sure the resource under
 // test has reached a suitable state before moving to the next action or fail with an error message.
 //
@@ -96,6 +115,14 @@ func For(conditionFunc apimachinerywait.ConditionFunc, opts ...Option) error {
 		fn(options)
 	}
 
+	if options.Watch != nil {
+		return forWatch(conditionFunc, options)
+	}
+
+	if options.Adaptive != nil {
+		return forAdaptive(conditionFunc, options)
+	}
+
 	// Setting the options.StopChan will force the usage of `PollUntil`
 	if options.StopChan != nil {
 		if options.Immediate {
@@ -109,3 +136,40 @@ func For(conditionFunc apimachinerywait.ConditionFunc, opts ...Option) error {
 	}
 	return apimachinerywait.Poll(options.Interval, options.Timeout, conditionFunc)
 }
+
+// forWatch evaluates conditionFunc whenever an event is received from options.Watch or the
+// poll interval elapses, until the condition is met, the timeout elapses, or StopChan is closed.
+func forWatch(conditionFunc apimachinerywait.ConditionFunc, options *Options) error {
+	defer options.Watch.Stop()
+
+	var timeout <-chan time.Time
+	if options.StopChan == nil {
+		timer := time.NewTimer(options.Timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	ticker := time.NewTicker(options.Interval)
+	defer ticker.Stop()
+
+	events := options.Watch.ResultChan()
+	for {
+		if done, err := conditionFunc(); err != nil {
+			return err
+		} else if done {
+			return nil
+		}
+
+		select {
+		case _, ok := <-events:
+			if !ok {
+				// the watch was closed by the server, fallback to polling
+				events = nil
+			}
+		case <-ticker.C:
+		case <-timeout:
+			return apimachinerywait.ErrWaitTimeout
+		case <-options.StopChan:
+			return apimachinerywait.ErrWaitTimeout
+		}
+	}
+}
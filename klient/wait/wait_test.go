@@ -28,6 +28,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 
+	"sigs.k8s.io/e2e-framework/klient/events"
 	"sigs.k8s.io/e2e-framework/klient/k8s"
 	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
 	"sigs.k8s.io/e2e-framework/klient/wait/conditions"
@@ -42,6 +43,38 @@ func TestPodRunning(t *testing.T) {
 	}
 }
 
+func TestPodRunningWithWatch(t *testing.T) {
+	pod := createPod("p-watch", t)
+	w, err := getResourceManager().Watch(context.TODO(), &v1.PodList{}, resources.WithFieldSelector("metadata.name=p-watch"))
+	if err != nil {
+		t.Fatal("failed to watch pod", err)
+	}
+	err = For(conditions.New(getResourceManager()).PodRunning(pod), WithWatch(w))
+	if err != nil {
+		t.Error("failed to wait for pod to reach running condition using a watch", err)
+	}
+}
+
+func TestNodesReady(t *testing.T) {
+	err := For(conditions.New(getResourceManager()).NodesReady(1), WithImmediate())
+	if err != nil {
+		t.Error("failed to wait for node to reach Ready condition", err)
+	}
+}
+
+func TestEventEmitted(t *testing.T) {
+	pod := createPod("events-pod", t)
+	cond := conditions.New(getResourceManager())
+	err := For(cond.EventEmitted(pod, events.WithReason("Scheduled"), events.WithType(v1.EventTypeNormal), events.WithMessageRegex("assigned .*/events-pod")))
+	if err != nil {
+		t.Error("failed to wait for pod scheduled event", err)
+	}
+	err = For(cond.EventsMatch(pod, events.InOrder(events.WithReason("Scheduled"), events.WithReason("Started"))))
+	if err != nil {
+		t.Error("failed to wait for pod events in order", err)
+	}
+}
+
 func TestPodPhaseMatch(t *testing.T) {
 	var err error
 	pod := createPod("p2", t)
@@ -0,0 +1,216 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package checks provides ready-made verifications of the cluster under test
+// that can be run as environment Setup funcs (preflight checks) or as features.
+package checks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	log "k8s.io/klog/v2"
+
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/klient/wait/conditions"
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+)
+
+const (
+	defaultDNSImage   = "busybox:1.35"
+	defaultDNSTimeout = 2 * time.Minute
+)
+
+// DNSOptions configures the cluster DNS verification
+type DNSOptions struct {
+	// Image of the probe pod, it must provide sh, tr, hostname, and nslookup
+	Image string
+	// Namespace where the probe pod is created, defaults to the env config namespace or default
+	Namespace string
+	// Services are the service names that must resolve, defaults to kubernetes.default
+	Services []string
+	// ExternalHosts are the external host names that must resolve, none by default
+	ExternalHosts []string
+	// SkipPodLookup disables the verification of the probe pod DNS record
+	SkipPodLookup bool
+	// Timeout for the probe pod to complete
+	Timeout time.Duration
+}
+
+type DNSOption func(*DNSOptions)
+
+// WithDNSImage sets the image of the probe pod
+func WithDNSImage(image string) DNSOption {
+	return func(o *DNSOptions) {
+		o.Image = image
+	}
+}
+
+// WithDNSNamespace sets the namespace of the probe pod
+func WithDNSNamespace(ns string) DNSOption {
+	return func(o *DNSOptions) {
+		o.Namespace = ns
+	}
+}
+
+// WithServiceLookups sets the service names, i.e. my-svc.my-namespace, that must resolve
+func WithServiceLookups(services ...string) DNSOption {
+	return func(o *DNSOptions) {
+		o.Services = services
+	}
+}
+
+// WithExternalLookups sets external host names that must resolve,
+// for clusters where external resolution is allowed
+func WithExternalLookups(hosts ...string) DNSOption {
+	return func(o *DNSOptions) {
+		o.ExternalHosts = hosts
+	}
+}
+
+// WithoutPodLookup disables the verification of the pod DNS record,
+// for clusters where pod records are disabled
+func WithoutPodLookup() DNSOption {
+	return func(o *DNSOptions) {
+		o.SkipPodLookup = true
+	}
+}
+
+// WithDNSTimeout sets how long to wait for the probe pod to complete
+func WithDNSTimeout(timeout time.Duration) DNSOption {
+	return func(o *DNSOptions) {
+		o.Timeout = timeout
+	}
+}
+
+func newDNSOptions(opts []DNSOption) *DNSOptions {
+	o := &DNSOptions{
+		Image:    defaultDNSImage,
+		Services: []string{"kubernetes.default"},
+		Timeout:  defaultDNSTimeout,
+	}
+	for _, fn := range opts {
+		fn(o)
+	}
+	return o
+}
+
+// dnsProbeScript returns the shell script run by the probe pod, which exits
+// with an error at the first lookup failing.
+func dnsProbeScript(o *DNSOptions) string {
+	lines := []string{"set -e"}
+	for _, svc := range o.Services {
+		lines = append(lines, fmt.Sprintf("echo lookup service %[1]s; nslookup %[1]s", svc))
+	}
+	if !o.SkipPodLookup {
+		// pod A records are named after the dashed pod IP
+		lines = append(lines, `POD_RECORD="$(hostname -i | tr . -).${POD_NAMESPACE}.pod"`,
+			`echo lookup pod ${POD_RECORD}; nslookup ${POD_RECORD}`)
+	}
+	for _, host := range o.ExternalHosts {
+		lines = append(lines, fmt.Sprintf("echo lookup external %[1]s; nslookup %[1]s", host))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// VerifyDNS runs a probe pod resolving the configured service, pod, and external names
+// and returns an error, including the probe output, if any lookup fails.
+func VerifyDNS(ctx context.Context, cfg *envconf.Config, opts ...DNSOption) error {
+	o := newDNSOptions(opts)
+	if o.Namespace == "" {
+		o.Namespace = cfg.Namespace()
+	}
+	if o.Namespace == "" {
+		o.Namespace = "default"
+	}
+
+	client, err := cfg.NewClient()
+	if err != nil {
+		return fmt.Errorf("verify dns: %w", err)
+	}
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: envconf.RandomName("dns-probe", 20), Namespace: o.Namespace},
+		Spec: v1.PodSpec{
+			RestartPolicy: v1.RestartPolicyNever,
+			Containers: []v1.Container{{
+				Name:    "probe",
+				Image:   o.Image,
+				Command: []string{"sh", "-c", dnsProbeScript(o)},
+				Env: []v1.EnvVar{{
+					Name:      "POD_NAMESPACE",
+					ValueFrom: &v1.EnvVarSource{FieldRef: &v1.ObjectFieldSelector{FieldPath: "metadata.namespace"}},
+				}},
+			}},
+		},
+	}
+	log.V(4).Infof("Verifying cluster DNS with probe pod %s/%s", pod.Namespace, pod.Name)
+	if err := client.Resources().Create(ctx, pod); err != nil {
+		return fmt.Errorf("verify dns: create probe pod: %w", err)
+	}
+	defer func() {
+		if err := client.Resources().Delete(context.Background(), pod); err != nil {
+			log.V(4).Infof("verify dns: delete probe pod %s: %s", pod.Name, err)
+		}
+	}()
+
+	completed := func(obj k8s.Object) bool {
+		phase := obj.(*v1.Pod).Status.Phase
+		return phase == v1.PodSucceeded || phase == v1.PodFailed
+	}
+	if err := wait.For(conditions.New(client.Resources()).WithContext(ctx).ResourceMatch(pod, completed), wait.WithTimeout(o.Timeout)); err != nil {
+		return fmt.Errorf("verify dns: probe pod did not complete: %w", err)
+	}
+	if pod.Status.Phase == v1.PodSucceeded {
+		return nil
+	}
+
+	output := "<unavailable>"
+	if cs, err := kubernetes.NewForConfig(client.RESTConfig()); err == nil {
+		if logs, err := cs.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &v1.PodLogOptions{}).DoRaw(ctx); err == nil {
+			output = string(logs)
+		}
+	}
+	return fmt.Errorf("verify dns: lookup failed, probe output:\n%s", output)
+}
+
+// DNS provides an Environment.Func that verifies the cluster DNS,
+// to be used as a preflight check in the environment Setup.
+func DNS(opts ...DNSOption) env.Func {
+	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		return ctx, VerifyDNS(ctx, cfg, opts...)
+	}
+}
+
+// DNSFeature returns a feature that verifies the cluster DNS
+func DNSFeature(opts ...DNSOption) features.Feature {
+	return features.New("cluster DNS").
+		Assess("names resolve from a pod", func(ctx context.Context, t *testing.T, cfg *envconf.Config) context.Context {
+			if err := VerifyDNS(ctx, cfg, opts...); err != nil {
+				t.Fatal(err)
+			}
+			return ctx
+		}).Feature()
+}
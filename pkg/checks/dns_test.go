@@ -0,0 +1,62 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checks
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDNSProbeScript(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []DNSOption
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "defaults",
+			contains: []string{"nslookup kubernetes.default", "nslookup ${POD_RECORD}"},
+			excludes: []string{"lookup external"},
+		},
+		{
+			name:     "custom lookups",
+			opts:     []DNSOption{WithServiceLookups("web.apps"), WithExternalLookups("example.com"), WithoutPodLookup()},
+			contains: []string{"nslookup web.apps", "nslookup example.com"},
+			excludes: []string{"kubernetes.default", "POD_RECORD"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			script := dnsProbeScript(newDNSOptions(test.opts))
+			if !strings.HasPrefix(script, "set -e\n") {
+				t.Errorf("expected script to stop on the first failure:\n%s", script)
+			}
+			for _, s := range test.contains {
+				if !strings.Contains(script, s) {
+					t.Errorf("expected script to contain %q:\n%s", s, script)
+				}
+			}
+			for _, s := range test.excludes {
+				if strings.Contains(script, s) {
+					t.Errorf("expected script not to contain %q:\n%s", s, script)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package codegen generates skeleton features from CustomResourceDefinitions.
+// For each kind, the generated feature creates, reads, updates, and deletes an
+// object, waiting for its Ready status condition when the kind has a status
+// subresource. The generated code is a starting point meant to be edited.
+package codegen
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"io"
+	"strings"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/util/yaml"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// crd holds the fields of a CustomResourceDefinition used by the generator
+type crd struct {
+	Kind string `json:"kind"`
+	Spec struct {
+		Group string `json:"group"`
+		Names struct {
+			Kind string `json:"kind"`
+		} `json:"names"`
+		Scope    string `json:"scope"`
+		Versions []struct {
+			Name         string `json:"name"`
+			Served       bool   `json:"served"`
+			Storage      bool   `json:"storage"`
+			Subresources struct {
+				Status *struct{} `json:"status"`
+			} `json:"subresources"`
+		} `json:"versions"`
+	} `json:"spec"`
+}
+
+// Kind describes a custom resource kind to generate a feature for
+type Kind struct {
+	Group      string
+	Version    string
+	Kind       string
+	Namespaced bool
+	HasStatus  bool
+}
+
+// ParseCRDs reads the CustomResourceDefinitions from a stream of YAML or JSON documents.
+// Documents of other kinds are ignored. For each CRD, the storage version is used.
+func ParseCRDs(r io.Reader) ([]Kind, error) {
+	var kinds []Kind
+	reader := yaml.NewYAMLReader(bufio.NewReader(r))
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("codegen: read crd: %w", err)
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+
+		var def crd
+		if err := sigsyaml.Unmarshal(doc, &def); err != nil {
+			return nil, fmt.Errorf("codegen: decode crd: %w", err)
+		}
+		if def.Kind != "CustomResourceDefinition" {
+			continue
+		}
+
+		kind := Kind{
+			Group:      def.Spec.Group,
+			Kind:       def.Spec.Names.Kind,
+			Namespaced: def.Spec.Scope != "Cluster",
+		}
+		for _, v := range def.Spec.Versions {
+			if v.Storage || (kind.Version == "" && v.Served) {
+				kind.Version = v.Name
+				kind.HasStatus = v.Subresources.Status != nil
+			}
+		}
+		if kind.Kind == "" || kind.Version == "" {
+			return nil, fmt.Errorf("codegen: crd for group %q has no kind or version", kind.Group)
+		}
+		kinds = append(kinds, kind)
+	}
+	return kinds, nil
+}
+
+// Generate returns the formatted Go source of a file, in package pkg,
+// defining a <Kind>Feature function for each of the kinds.
+func Generate(pkg string, kinds []Kind) ([]byte, error) {
+	seen := make(map[string]bool)
+	for _, k := range kinds {
+		if seen[k.Kind] {
+			return nil, fmt.Errorf("codegen: duplicate kind %s", k.Kind)
+		}
+		seen[k.Kind] = true
+	}
+
+	var buf bytes.Buffer
+	data := struct {
+		Package string
+		Kinds   []Kind
+	}{Package: pkg, Kinds: kinds}
+	if err := featuresTemplate.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("codegen: generate: %w", err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("codegen: format: %w", err)
+	}
+	return src, nil
+}
+
+var featuresTemplate = template.Must(template.New("features").Funcs(template.FuncMap{
+	"lower": strings.ToLower,
+}).Parse(`// Code generated by crd-features as a starting point, edit as needed.
+
+package {{.Package}}
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+	"sigs.k8s.io/e2e-framework/pkg/utils"
+)
+{{range .Kinds}}
+// {{.Kind}}GVK is the group, version, and kind of {{.Kind}}
+var {{.Kind}}GVK = schema.GroupVersionKind{Group: "{{.Group}}", Version: "{{.Version}}", Kind: "{{.Kind}}"}
+
+// new{{.Kind}} returns the {{.Kind}} used by {{.Kind}}Feature.
+// TODO: fill in the spec of the object.
+func new{{.Kind}}(cfg *envconf.Config) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{},
+	}}
+	obj.SetGroupVersionKind({{.Kind}}GVK)
+	obj.SetName("e2e-{{lower .Kind}}")
+	{{- if .Namespaced}}
+	obj.SetNamespace(cfg.Namespace())
+	{{- end}}
+	return obj
+}
+
+// {{.Kind}}Feature returns a feature covering the lifecycle of a {{.Kind}}
+func {{.Kind}}Feature() features.Feature {
+	return features.New("{{.Kind}}").
+		WithLabel("kind", "{{.Kind}}").
+		Assess("create", func(ctx context.Context, t *testing.T, cfg *envconf.Config) context.Context {
+			if err := cfg.Client().Resources().Create(ctx, new{{.Kind}}(cfg)); err != nil {
+				t.Fatal(err)
+			}
+			return ctx
+		}).
+		Assess("read", func(ctx context.Context, t *testing.T, cfg *envconf.Config) context.Context {
+			obj := new{{.Kind}}(cfg)
+			if err := cfg.Client().Resources().Get(ctx, obj.GetName(), obj.GetNamespace(), obj); err != nil {
+				t.Fatal(err)
+			}
+			return ctx
+		}).
+		Assess("update", func(ctx context.Context, t *testing.T, cfg *envconf.Config) context.Context {
+			obj := new{{.Kind}}(cfg)
+			if err := cfg.Client().Resources().Get(ctx, obj.GetName(), obj.GetNamespace(), obj); err != nil {
+				t.Fatal(err)
+			}
+			// TODO: update the spec of the object
+			obj.SetLabels(map[string]string{"e2e-framework/updated": "true"})
+			if err := cfg.Client().Resources().Update(ctx, obj); err != nil {
+				t.Fatal(err)
+			}
+			return ctx
+		}).
+		{{- if .HasStatus}}
+		Assess("ready", func(ctx context.Context, t *testing.T, cfg *envconf.Config) context.Context {
+			// TODO: adjust the condition reported by the controller
+			err := utils.WaitForCondition(cfg.Client().Resources(), []k8s.Object{new{{.Kind}}(cfg)}, "Ready", "True", wait.WithTimeout(time.Minute))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return ctx
+		}).
+		{{- end}}
+		Assess("delete", func(ctx context.Context, t *testing.T, cfg *envconf.Config) context.Context {
+			obj := new{{.Kind}}(cfg)
+			if err := cfg.Client().Resources().Delete(ctx, obj); err != nil {
+				t.Fatal(err)
+			}
+			if err := utils.WaitForDeletion(cfg.Client().Resources(), []k8s.Object{obj}, wait.WithTimeout(time.Minute)); err != nil {
+				t.Fatal(err)
+			}
+			return ctx
+		}).
+		Feature()
+}
+{{end}}`))
@@ -0,0 +1,62 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package codegen
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCRDsAndGenerate(t *testing.T) {
+	f, err := os.Open("testdata/crds.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	kinds, err := ParseCRDs(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Kind{
+		{Group: "example.com", Version: "v1", Kind: "Widget", Namespaced: true, HasStatus: true},
+		{Group: "example.com", Version: "v1beta1", Kind: "Gadget"},
+	}
+	if !reflect.DeepEqual(kinds, expected) {
+		t.Fatalf("expected kinds %+v, got %+v", expected, kinds)
+	}
+
+	src, err := Generate("e2e", kinds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"package e2e", "func WidgetFeature() features.Feature", "func GadgetFeature() features.Feature", `Assess("ready"`} {
+		if !strings.Contains(string(src), s) {
+			t.Errorf("expected generated source to contain %q", s)
+		}
+	}
+	// only Widget has a status subresource
+	if strings.Count(string(src), `Assess("ready"`) != 1 {
+		t.Error("expected a single ready assessment")
+	}
+
+	if _, err := Generate("e2e", append(kinds, kinds[0])); err == nil {
+		t.Error("expected duplicate kinds to fail")
+	}
+}
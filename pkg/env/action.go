@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"sigs.k8s.io/e2e-framework/pkg/envconf"
 	"sigs.k8s.io/e2e-framework/pkg/internal/types"
@@ -29,11 +30,36 @@ const (
 	roleSetup = iota
 	roleBeforeTest
 	roleBeforeFeature
+	roleBeforeAssessment
+	roleAfterAssessment
 	roleAfterFeature
 	roleAfterTest
 	roleFinish
 )
 
+func (r actionRole) String() string {
+	switch r {
+	case roleSetup:
+		return "Setup"
+	case roleBeforeTest:
+		return "BeforeEachTest"
+	case roleBeforeFeature:
+		return "BeforeEachFeature"
+	case roleBeforeAssessment:
+		return "BeforeEachAssessment"
+	case roleAfterAssessment:
+		return "AfterEachAssessment"
+	case roleAfterFeature:
+		return "AfterEachFeature"
+	case roleAfterTest:
+		return "AfterEachTest"
+	case roleFinish:
+		return "Finish"
+	default:
+		return fmt.Sprintf("actionRole(%d)", uint8(r))
+	}
+}
+
 // action a group env functions
 type action struct {
 	role actionRole
@@ -46,10 +72,14 @@ type action struct {
 
 	// testFuncs store the TestEnvFunc for before/after feature.
 	testFuncs []types.TestEnvFunc
+
+	// assessmentFuncs store the AssessmentEnvFunc for before/after assessment.
+	assessmentFuncs []types.AssessmentEnvFunc
 }
 
 // runWithT will run the action and inject *testing.T into the callback function.
 func (a *action) runWithT(ctx context.Context, cfg *envconf.Config, t *testing.T) (context.Context, error) {
+	defer a.trace(cfg)()
 	switch a.role {
 	case roleBeforeTest, roleAfterTest:
 		for _, f := range a.testFuncs {
@@ -72,6 +102,7 @@ func (a *action) runWithT(ctx context.Context, cfg *envconf.Config, t *testing.T
 
 // runWithFeature will run the action and inject a FeatureInfo object into the callback function.
 func (a *action) runWithFeature(ctx context.Context, cfg *envconf.Config, t *testing.T, fi types.Feature) (context.Context, error) {
+	defer a.trace(cfg, "feature", fi.Name())()
 	switch a.role {
 	case roleBeforeFeature, roleAfterFeature:
 		for _, f := range a.featureFuncs {
@@ -91,8 +122,33 @@ func (a *action) runWithFeature(ctx context.Context, cfg *envconf.Config, t *tes
 	return ctx, nil
 }
 
+// runWithAssessment will run the action and inject the assessment name into the callback function.
+func (a *action) runWithAssessment(ctx context.Context, cfg *envconf.Config, t *testing.T, name string) (context.Context, error) {
+	defer a.trace(cfg, "assessment", name)()
+	switch a.role {
+	case roleBeforeAssessment, roleAfterAssessment:
+		for _, f := range a.assessmentFuncs {
+			if f == nil {
+				continue
+			}
+
+			var err error
+			ctx, err = f(ctx, cfg, t, name)
+			if err != nil {
+				return ctx, err
+			}
+		}
+	default:
+		return ctx, fmt.Errorf("runWithAssessment() is only valid for actions roleBeforeAssessment and roleAfterAssessment")
+	}
+	return ctx, nil
+}
+
+// run will run the action funcs, returning an *ActionError attributing the error
+// to the func that failed, by its index within the action.
 func (a *action) run(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
-	for _, f := range a.funcs {
+	defer a.trace(cfg)()
+	for i, f := range a.funcs {
 		if f == nil {
 			continue
 		}
@@ -100,9 +156,22 @@ func (a *action) run(ctx context.Context, cfg *envconf.Config) (context.Context,
 		var err error
 		ctx, err = f(ctx, cfg)
 		if err != nil {
-			return ctx, err
+			return ctx, &ActionError{Role: a.role.String(), Index: i, Name: funcName(f), Err: err}
 		}
 	}
 
 	return ctx, nil
 }
+
+// trace logs the start of the action and returns a func logging its finish
+func (a *action) trace(cfg *envconf.Config, keysAndValues ...interface{}) func() {
+	if cfg == nil {
+		return func() {}
+	}
+	logger := cfg.Logger().WithValues(append([]interface{}{"action", a.role.String()}, keysAndValues...)...)
+	logger.V(1).Info("Starting action")
+	start := time.Now()
+	return func() {
+		logger.V(1).Info("Finished action", "duration", time.Since(start))
+	}
+}
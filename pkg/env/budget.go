@@ -0,0 +1,118 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package env
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// runBudget tracks the time elapsed since the environment started running
+// to decide whether a feature can still be started within the run budget.
+type runBudget struct {
+	mu      sync.Mutex
+	start   time.Time
+	longest time.Duration
+}
+
+// begin records the start of the run, unless already started, i.e. for
+// the features tested outside of Environment.Run
+func (b *runBudget) begin() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.start.IsZero() {
+		b.start = time.Now()
+	}
+}
+
+// reset starts measuring a new run, forgetting the features of the previous runs
+func (b *runBudget) reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.start = time.Now()
+	b.longest = 0
+}
+
+// observe records the duration of a feature
+func (b *runBudget) observe(d time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if d > b.longest {
+		b.longest = d
+	}
+}
+
+// exhausted reports whether a new feature should not be started with the given budget:
+// either the budget is already spent or the time left is shorter than the longest
+// feature run so far, in which case the budget is likely to be exceeded.
+// A budget of zero means no limit.
+func (b *runBudget) exhausted(budget time.Duration) bool {
+	if budget <= 0 {
+		return false
+	}
+	b.begin()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return budget-time.Since(b.start) <= b.longest
+}
+
+// suiteTimedOut reports whether the suite timeout of the current run is exceeded
+func (e *testEnv) suiteTimedOut() bool {
+	return !e.deadline.IsZero() && !time.Now().Before(e.deadline)
+}
+
+// budgetSkipped collects the tests of the features skipped because the
+// run budget or the suite timeout was exceeded
+type budgetSkipped struct {
+	mu    sync.Mutex
+	tests []string
+}
+
+func (b *budgetSkipped) add(test string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tests = append(b.tests, test)
+}
+
+// reset clears the tests collected and returns them
+func (b *budgetSkipped) reset() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	tests := b.tests
+	b.tests = nil
+	return tests
+}
+
+// detachedContext keeps the values of its parent context without its
+// deadline and cancellation, so that the Finish funcs and teardowns
+// still run once the suite timeout cancelled the tests
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+
+// detach returns ctx without its deadline and cancellation, if it is done
+func detach(ctx context.Context) context.Context {
+	if ctx.Err() == nil {
+		return ctx
+	}
+	return detachedContext{ctx}
+}
@@ -20,23 +20,35 @@ package env
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
+	"runtime/debug"
 	"sync"
 	"testing"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/flowcontrol"
 	log "k8s.io/klog/v2"
 
+	"sigs.k8s.io/e2e-framework/klient/apicalls"
+	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
 	"sigs.k8s.io/e2e-framework/pkg/envconf"
 	"sigs.k8s.io/e2e-framework/pkg/features"
 	"sigs.k8s.io/e2e-framework/pkg/internal/types"
+	"sigs.k8s.io/e2e-framework/pkg/tracing"
 )
 
 type (
 	Environment = types.Environment
 	Func        = types.EnvFunc
 	FeatureFunc = types.FeatureEnvFunc
+	// AssessmentFunc is the type of the funcs run before and after each assessment
+	AssessmentFunc = types.AssessmentEnvFunc
 
 	actionRole uint8
 )
@@ -46,6 +58,29 @@ type testEnv struct {
 	cfg     *envconf.Config
 	actions []action
 	rnd     rand.Source
+
+	// base is the environment extended by this one, if any
+	base *testEnv
+	// shared tracks the setup of this environment when extended
+	shared     *sharedSetup
+	sharedOnce sync.Once
+
+	// budget tracks the elapsed time against the configured run budget
+	budget runBudget
+	// cluster caches the cluster details used to check feature requirements
+	cluster clusterInfo
+	// state records the features that passed, to resume the run
+	state runState
+	// result records the outcome of the last run
+	result *RunResult
+	// failures tracks whether a feature failed, to keep the resources on failure
+	failures runFailures
+	// optional collects the failures of the optional assessments
+	optional optionalFailures
+	// deadline is the time the suite timeout of the current run is exceeded, if any
+	deadline time.Time
+	// skipped collects the features skipped once the budget or timeout is exceeded
+	skipped budgetSkipped
 }
 
 // New creates a test environment with no config attached.
@@ -115,8 +150,9 @@ func (e *testEnv) WithContext(ctx context.Context) types.Environment {
 		panic("nil context") // this should never happen
 	}
 	env := &testEnv{
-		ctx: ctx,
-		cfg: e.cfg,
+		ctx:  ctx,
+		cfg:  e.cfg,
+		base: e.base,
 	}
 	env.actions = append(env.actions, e.actions...)
 	return env
@@ -162,6 +198,28 @@ func (e *testEnv) AfterEachFeature(funcs ...FeatureFunc) types.Environment {
 	return e
 }
 
+// BeforeEachAssessment registers funcs that are executed before each
+// assessment of the features tested, with the name of the assessment.
+// They are not run for the assessments that are skipped.
+func (e *testEnv) BeforeEachAssessment(funcs ...AssessmentFunc) types.Environment {
+	if len(funcs) == 0 {
+		return e
+	}
+	e.actions = append(e.actions, action{role: roleBeforeAssessment, assessmentFuncs: funcs})
+	return e
+}
+
+// AfterEachAssessment registers funcs that are executed after each assessment
+// of the features tested, with the name of the assessment. They are run even
+// when the assessment fails, unless a BeforeEachAssessment func failed.
+func (e *testEnv) AfterEachAssessment(funcs ...AssessmentFunc) types.Environment {
+	if len(funcs) == 0 {
+		return e
+	}
+	e.actions = append(e.actions, action{role: roleAfterAssessment, assessmentFuncs: funcs})
+	return e
+}
+
 // AfterEachTest registers environment funcs that are executed
 // after each Env.Test(...).
 func (e *testEnv) AfterEachTest(funcs ...types.TestEnvFunc) types.Environment {
@@ -193,8 +251,8 @@ func (e *testEnv) processTestActions(t *testing.T, actions []action) {
 
 // processTestFeature is used to trigger the execution of the actual feature. This function wraps the entire
 // workflow of orchestrating the feature execution be running the action configured by BeforeEachFeature /
-// AfterEachFeature.
-func (e *testEnv) processTestFeature(t *testing.T, featureName string, feature types.Feature) {
+// AfterEachFeature. It returns the context as updated by the actions and the feature steps.
+func (e *testEnv) processTestFeature(ctx context.Context, t *testing.T, featureName string, feature types.Feature) context.Context {
 	var err error
 
 	// execute each feature
@@ -202,20 +260,24 @@ func (e *testEnv) processTestFeature(t *testing.T, featureName string, feature t
 	afterFeatureActions := e.getAfterFeatureActions()
 
 	for _, action := range beforeFeatureActions {
-		if e.ctx, err = action.runWithFeature(e.ctx, e.cfg, t, deepCopyFeature(feature)); err != nil {
+		if ctx, err = action.runWithFeature(ctx, e.cfg, t, deepCopyFeature(feature)); err != nil {
 			t.Fatalf("BeforeEachTest failure: %s", err)
 		}
 	}
 
 	// execute feature test
-	e.ctx = e.execFeature(e.ctx, t, featureName, feature)
+	start := time.Now()
+	ctx = e.execFeature(ctx, t, featureName, feature)
+	e.budget.observe(time.Since(start))
+	ctx = e.withTeardownState(ctx)
 
 	// execute beforeFeature actions
 	for _, action := range afterFeatureActions {
-		if e.ctx, err = action.runWithFeature(e.ctx, e.cfg, t, deepCopyFeature(feature)); err != nil {
+		if ctx, err = action.runWithFeature(ctx, e.cfg, t, deepCopyFeature(feature)); err != nil {
 			t.Fatalf("BeforeEachTest failure: %s", err)
 		}
 	}
+	return ctx
 }
 
 // processTests is a wrapper function that can be invoked by either Test or TestInParallel methods.
@@ -223,9 +285,14 @@ func (e *testEnv) processTestFeature(t *testing.T, featureName string, feature t
 // nature of how the test gets executed.
 //
 // In case if the parallel run of test features are enabled, this function will invoke the processTestFeature
-// as a go-routine to get them to run in parallel
+// as a go-routine to get them to run in parallel. Otherwise, only the features marked with
+// features.FeatureBuilder.WithParallel are run in parallel, once all other features have been run in sequence.
+//
+// Features run in parallel start with the context available at that point and their changes
+// to the context are not propagated back to the environment.
 func (e *testEnv) processTests(t *testing.T, enableParallelRun bool, testFeatures ...types.Feature) {
 	e.panicOnMissingContext()
+	e.budget.begin()
 	if len(testFeatures) == 0 {
 		t.Log("No test testFeatures provided, skipping test")
 		return
@@ -241,25 +308,34 @@ func (e *testEnv) processTests(t *testing.T, enableParallelRun bool, testFeature
 		log.V(4).Info("Running test features in parallel")
 	}
 
-	var wg sync.WaitGroup
+	type namedFeature struct {
+		name    string
+		feature types.Feature
+	}
+	var parallelFeatures []namedFeature
 	for i, feature := range testFeatures {
 		featName := feature.Name()
 		if featName == "" {
 			featName = fmt.Sprintf("Feature-%d", i+1)
 		}
-		if runInParallel {
-			wg.Add(1)
-			go func(w *sync.WaitGroup) {
-				defer w.Done()
-				e.processTestFeature(t, featName, feature)
-			}(&wg)
-		} else {
-			e.processTestFeature(t, featName, feature)
+		if runInParallel || features.IsParallel(feature) {
+			parallelFeatures = append(parallelFeatures, namedFeature{name: featName, feature: feature})
+			continue
 		}
+		e.ctx = e.processTestFeature(e.ctx, t, featName, feature)
 	}
-	if runInParallel {
-		wg.Wait()
+
+	var wg sync.WaitGroup
+	for _, f := range parallelFeatures {
+		wg.Add(1)
+		go func(ctx context.Context, name string, feature types.Feature) {
+			defer wg.Done()
+			e.processTestFeature(ctx, t, name, feature)
+		}(e.ctx, f.name, f.feature)
 	}
+	wg.Wait()
+
+	e.ctx = e.withTeardownState(e.ctx)
 	e.processTestActions(t, afterTestActions)
 }
 
@@ -316,36 +392,165 @@ func (e *testEnv) Finish(funcs ...Func) types.Environment {
 // It will run m.Run() and exercise all test functions in the
 // package.  This method will all Env.Setup operations prior to
 // starting the tests and run all Env.Finish operations after
-// before completing the suite.
-//
+// before completing the suite. When a Setup func fails, the tests
+// and the Finish funcs are not run. The outcome of the run, including
+// the failed Setup or Finish funcs, is available with Result.
 func (e *testEnv) Run(m *testing.M) int {
+	return e.run(m.Run)
+}
+
+// run executes the setup actions, the provided test suite function, then the
+// finish actions. All state is kept in the environment value so that several
+// environments can be run in the same process. The outcome is recorded in the
+// result of the environment.
+func (e *testEnv) run(runTests func() int) int {
 	if e.ctx == nil {
 		panic("context not set") // something is terribly wrong.
 	}
+	e.result = &RunResult{}
+	e.failures = runFailures{}
+	e.optional.reset()
+	e.skipped.reset()
+	e.budget.reset()
+	if timeout := e.cfg.SuiteTimeout(); timeout > 0 {
+		e.deadline = time.Now().Add(timeout)
+		defer func() { e.deadline = time.Time{} }()
+	}
 
-	setups := e.getSetupActions()
-	// fail fast on setup, upon err exit
+	// flush the spans of the run once done, after the finish funcs of the base environment
+	defer e.shutdownTracing()
+
+	// an extended environment first runs the setup of its base, once
+	if e.base != nil {
+		ctx, err := e.base.acquireSetup()
+		defer func() {
+			e.result.FinishErrors = append(e.result.FinishErrors, e.base.releaseSetup(e.skipTeardown())...)
+		}()
+		if err != nil {
+			return e.setupFailed(err)
+		}
+		e.ctx = extendedContext{Context: e.ctx, base: ctx}
+	}
+
+	var runSpan trace.Span
+	var endRun func(context.Context) context.Context
+	e.ctx, runSpan, endRun = e.startSpan(e.ctx, "run")
+
+	// fail fast on setup, upon err skip the tests
+	ctx, setupSpan, endSetup := e.startSpan(e.ctx, "setup")
+	ctx, err := e.runSetups(ctx)
+	setErrorStatus(setupSpan, err)
+	e.ctx = endSetup(ctx)
+	if err != nil {
+		setErrorStatus(runSpan, err)
+		endRun(e.ctx)
+		return e.setupFailed(err)
+	}
+	// the setup funcs may have created the cluster or installed CRDs
+	e.cluster.reset()
+
+	exitCode := e.runTestsWithTimeout(runTests) // exec test suite
+	e.result.ExitCode = exitCode
+	e.result.BudgetSkipped = e.skipped.reset()
+	if n := len(e.result.BudgetSkipped); n > 0 {
+		log.Infof("%d feature(s) skipped, the run budget or the suite timeout being exceeded: %v", n, e.result.BudgetSkipped)
+	}
+	e.result.OptionalFailures = e.optional.reset()
+	if n := len(e.result.OptionalFailures); n > 0 {
+		log.Infof("%d optional assessment(s) failed without failing their feature", n)
+	}
+
+	// keep the resources of a failed run, when configured to, by skipping the finish funcs
+	if e.skipTeardown() {
+		e.logPreserved("Finish funcs")
+	} else {
+		ctx, _, endFinish := e.startSpan(e.ctx, "finish")
+		ctx, e.result.FinishErrors = e.runFinishes(ctx)
+		e.ctx = endFinish(ctx)
+	}
+
+	runSpan.SetAttributes(attribute.Int("e2e.exit_code", exitCode))
+	if exitCode != 0 {
+		runSpan.SetStatus(codes.Error, "tests failed")
+	}
+	e.ctx = endRun(e.ctx)
+
+	return exitCode
+}
+
+// runTestsWithTimeout runs the tests with a context cancelled once the suite timeout is exceeded,
+// then detaches the context of the environment from it so that the Finish funcs can run
+func (e *testEnv) runTestsWithTimeout(runTests func() int) int {
+	if e.deadline.IsZero() {
+		return runTests()
+	}
+	ctx, cancel := context.WithDeadline(e.ctx, e.deadline)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			log.Infof("Suite timeout of %s exceeded, cancelling the running features and skipping the remaining ones", e.cfg.SuiteTimeout())
+		}
+	}()
+
+	e.ctx = ctx
+	exitCode := runTests()
+	// the deadline context is cancelled on return, even when the tests completed
+	// in time, so the context of the Finish funcs is always detached from it
+	e.ctx = detachedContext{e.ctx}
+	return exitCode
+}
+
+// setupFailed records the error of the failed setup func and returns the exit code of the run
+func (e *testEnv) setupFailed(err error) int {
+	log.ErrorS(err, "Setup failed, the tests and finish actions are not run")
+	e.result.SetupError = asActionError(roleSetup, err)
+	e.result.ExitCode = 1
+	return e.result.ExitCode
+}
+
+// shutdownTracing flushes the spans exported to the configured trace endpoint
+func (e *testEnv) shutdownTracing() {
+	if err := e.cfg.ShutdownTracing(context.Background()); err != nil {
+		log.V(2).ErrorS(err, "Tracing shutdown")
+	}
+}
+
+// runSetups runs the setup actions, stopping at the first error. The error
+// is an *ActionError attributed to the setup func that failed.
+func (e *testEnv) runSetups(ctx context.Context) (context.Context, error) {
 	var err error
-	for _, setup := range setups {
+	offset := 0
+	for _, setup := range e.getSetupActions() {
 		// context passed down to each setup
-		if e.ctx, err = setup.run(e.ctx, e.cfg); err != nil {
-			log.Fatal(err)
+		if ctx, err = setup.run(ctx, e.cfg); err != nil {
+			actionErr := asActionError(roleSetup, err)
+			actionErr.Index += offset
+			return ctx, actionErr
 		}
+		offset += len(setup.funcs)
 	}
+	return ctx, nil
+}
 
-	exitCode := m.Run() // exec test suite
-
-	finishes := e.getFinishActions()
+// runFinishes runs the finish actions, and returns the errors of the finish funcs that failed.
+func (e *testEnv) runFinishes(ctx context.Context) (context.Context, []*ActionError) {
+	var errs []*ActionError
+	offset := 0
 	// attempt to gracefully clean up.
 	// Upon error, log and continue.
-	for _, fin := range finishes {
+	for _, fin := range e.getFinishActions() {
 		// context passed down to each finish step
-		if e.ctx, err = fin.run(e.ctx, e.cfg); err != nil {
+		var err error
+		if ctx, err = fin.run(ctx, e.cfg); err != nil {
 			log.V(2).ErrorS(err, "Finish action handlers")
+			actionErr := asActionError(roleFinish, err)
+			actionErr.Index += offset
+			errs = append(errs, actionErr)
 		}
+		offset += len(fin.funcs)
 	}
-
-	return exitCode
+	return ctx, errs
 }
 
 func (e *testEnv) getActionsByRole(r actionRole) []action {
@@ -379,6 +584,14 @@ func (e *testEnv) getAfterFeatureActions() []action {
 	return e.getActionsByRole(roleAfterFeature)
 }
 
+func (e *testEnv) getBeforeAssessmentActions() []action {
+	return e.getActionsByRole(roleBeforeAssessment)
+}
+
+func (e *testEnv) getAfterAssessmentActions() []action {
+	return e.getActionsByRole(roleAfterAssessment)
+}
+
 func (e *testEnv) getAfterTestActions() []action {
 	return e.getActionsByRole(roleAfterTest)
 }
@@ -390,6 +603,43 @@ func (e *testEnv) getFinishActions() []action {
 func (e *testEnv) execFeature(ctx context.Context, t *testing.T, featName string, f types.Feature) context.Context {
 	// feature-level subtest
 	t.Run(featName, func(t *testing.T) {
+		logger := e.cfg.Logger().WithValues("feature", featName)
+		logger.V(1).Info("Starting feature")
+		start := time.Now()
+		results := suiteResultsFrom(ctx)
+		defer func() {
+			results.record(t)
+			logger.V(1).Info("Finished feature", "duration", time.Since(start), "failed", t.Failed(), "skipped", t.Skipped())
+		}()
+
+		// the feature span ends once the teardowns ran, with the status of the feature
+		var span trace.Span
+		var endSpan func(context.Context) context.Context
+		ctx, span, endSpan = e.startSpan(ctx, featName, tracing.FeatureKey.String(featName), tracing.TestKey.String(t.Name()))
+		defer func() {
+			setTestStatus(span, t)
+			ctx = endSpan(ctx)
+		}()
+
+		// skip remaining features once the run budget is spent, so that the
+		// Finish funcs still get to run before the job is killed
+		if e.budget.exhausted(e.cfg.RunBudget()) {
+			e.skipped.add(t.Name())
+			t.Skipf(`Skipping feature "%s": budget-skipped, run budget of %s exceeded`, featName, e.cfg.RunBudget())
+		}
+		if e.suiteTimedOut() {
+			e.skipped.add(t.Name())
+			t.Skipf(`Skipping feature "%s": budget-skipped, suite timeout of %s exceeded`, featName, e.cfg.SuiteTimeout())
+		}
+
+		// skip feature which passed in the run being resumed
+		if err := e.state.load(e.cfg); err != nil {
+			t.Fatalf(`Feature "%s": %s`, featName, err)
+		}
+		if e.cfg.Resume() && e.state.hasPassed(t.Name()) {
+			t.Skipf(`Skipping feature "%s": passed in a previous run`, featName)
+		}
+
 		// skip feature which matches with --skip-feature
 		if e.cfg.SkipFeatureRegex() != nil && e.cfg.SkipFeatureRegex().MatchString(featName) {
 			t.Skipf(`Skipping feature "%s": name matched`, featName)
@@ -415,14 +665,76 @@ func (e *testEnv) execFeature(ctx context.Context, t *testing.T, featName string
 			}
 		}
 
+		// skip feature whose labels do not satisfy --label-filter
+		if filter := e.cfg.LabelFilter(); filter != nil && !filter.Matches(f.Labels()) {
+			t.Skipf(`Skipping feature "%s": labels not matched by filter "%s"`, featName, filter)
+		}
+
+		// skip feature whose requirements are not satisfied by the cluster
+		unmet, err := e.cluster.unmet(e.cfg, features.GetRequirements(f))
+		if err != nil {
+			t.Fatalf(`Feature "%s": checking requirements: %s`, featName, err)
+		}
+		if unmet != "" {
+			t.Skipf(`Skipping feature "%s": unmet requirement: %s`, featName, unmet)
+		}
+
+		// override the rate limit of the resources for the steps of the feature only
+		if limit := features.GetRateLimit(f); limit.QPS > 0 {
+			prev := resources.RateLimiterFrom(ctx)
+			ctx = resources.WithRateLimiter(ctx, flowcontrol.NewTokenBucketRateLimiter(limit.QPS, limit.Burst))
+			defer func() { ctx = resources.WithRateLimiter(ctx, prev) }()
+		}
+
+		// count the API calls of the feature, teardowns included, under the name of its test
+		if counter := e.cfg.APICalls(); counter != nil {
+			prev := apicalls.ScopeFrom(ctx)
+			ctx = apicalls.WithScope(ctx, t.Name())
+			defer func() {
+				ctx = apicalls.WithScope(ctx, prev)
+				count, throttled := counter.Total(t.Name())
+				logger.V(1).Info("Feature API calls", "count", count, "throttled", throttled)
+			}()
+		}
+
+		// record the feature once its teardowns ran, so that it can be skipped when resuming
+		defer func() {
+			if !t.Failed() && !t.Skipped() {
+				if err := e.state.recordPassed(e.cfg, t.Name()); err != nil {
+					t.Errorf(`Feature "%s": %s`, featName, err)
+				}
+			}
+		}()
+
+		// teardowns run at feature-level, even when a setup stops the feature
+		// with t.FailNow or an assessment panics, so that resources do not leak,
+		// unless the resources of a failed run are kept for debugging
+		defer func() {
+			if t.Failed() {
+				e.failures.record()
+			}
+			if e.skipTeardown() {
+				t.Logf(`Skipping teardown of feature "%s": resources kept on failure, namespace %q`, featName, e.cfg.Namespace())
+				return
+			}
+			// the teardowns run even when the suite timeout cancelled the feature
+			ctx = detach(ctx)
+			teardowns := features.GetStepsByLevel(f.Steps(), types.LevelTeardown)
+			for _, teardown := range teardowns {
+				ctx = e.runStep(ctx, t, teardown)
+			}
+		}()
+
 		// setups run at feature-level
 		setups := features.GetStepsByLevel(f.Steps(), types.LevelSetup)
 		for _, setup := range setups {
-			ctx = setup.Func()(ctx, t, e.cfg)
+			ctx = e.runStep(ctx, t, setup)
 		}
 
 		// assessments run as feature/assessment sub level
 		assessments := features.GetStepsByLevel(f.Steps(), types.LevelAssess)
+		beforeAssessmentActions := e.getBeforeAssessmentActions()
+		afterAssessmentActions := e.getAfterAssessmentActions()
 
 		for i, assess := range assessments {
 			assessName := assess.Name()
@@ -430,6 +742,15 @@ func (e *testEnv) execFeature(ctx context.Context, t *testing.T, featName string
 				assessName = fmt.Sprintf("Assessment-%d", i+1)
 			}
 			t.Run(assessName, func(t *testing.T) {
+				var span trace.Span
+				var endSpan func(context.Context) context.Context
+				ctx, span, endSpan = e.startSpan(ctx, assessName,
+					tracing.FeatureKey.String(featName), tracing.AssessmentKey.String(assessName), tracing.TestKey.String(t.Name()))
+				defer func() {
+					setTestStatus(span, t)
+					ctx = endSpan(ctx)
+				}()
+
 				// skip assessments which matches with --skip-assessments
 				if e.cfg.SkipAssessmentRegex() != nil && e.cfg.SkipAssessmentRegex().MatchString(assess.Name()) {
 					t.Skipf(`Skipping assessment "%s": name matched`, assess.Name())
@@ -439,30 +760,127 @@ func (e *testEnv) execFeature(ctx context.Context, t *testing.T, featName string
 				if e.cfg.AssessmentRegex() != nil && !e.cfg.AssessmentRegex().MatchString(assess.Name()) {
 					t.Skipf(`Skipping assessment "%s": name not matched`, assess.Name())
 				}
-				ctx = assess.Func()(ctx, t, e.cfg)
+
+				var err error
+				for _, action := range beforeAssessmentActions {
+					if ctx, err = action.runWithAssessment(ctx, e.cfg, t, assessName); err != nil {
+						t.Fatalf("BeforeEachAssessment failure: %s", err)
+					}
+				}
+				// after assessment actions run even if the assessment stops with t.FailNow
+				defer func() {
+					for _, action := range afterAssessmentActions {
+						if ctx, err = action.runWithAssessment(ctx, e.cfg, t, assessName); err != nil {
+							t.Errorf("AfterEachAssessment failure: %s", err)
+							return
+						}
+					}
+				}()
+				ctx = e.runStep(ctx, t, assess)
 			})
 		}
+	})
 
-		// teardowns run at feature-level
-		teardowns := features.GetStepsByLevel(f.Steps(), types.LevelTeardown)
-		for _, teardown := range teardowns {
-			ctx = teardown.Func()(ctx, t, e.cfg)
+	return ctx
+}
+
+// runStep executes the step function and, if the step fails the test, reports
+// the location where the step was defined to ease locating failures. A panic in
+// the step is recovered and fails the test, the context is then returned unchanged.
+// Since the following steps depend on the setups, a panic in a setup stops the feature.
+func (e *testEnv) runStep(ctx context.Context, t *testing.T, step types.Step) (result context.Context) {
+	logger := e.cfg.Logger().WithValues("step", step.Name(), "level", step.Level().String())
+	logger.V(2).Info("Starting step")
+	start := time.Now()
+
+	failed := t.Failed()
+	result = ctx
+	defer func() {
+		r := recover()
+		if r != nil {
+			t.Errorf("step %q panicked: %v\n%s", step.Name(), r, debug.Stack())
 		}
-	})
+		logger.V(2).Info("Finished step", "duration", time.Since(start), "failed", t.Failed())
+		if !failed && t.Failed() {
+			if location := features.StepLocation(step); location != "" {
+				t.Logf("step %q defined at %s", step.Name(), location)
+			}
+		}
+		if r != nil && step.Level() == types.LevelSetup {
+			t.FailNow()
+		}
+	}()
+	if optional, ok := step.(types.OptionalStep); ok {
+		return e.runOptionalStep(ctx, t, step.Name(), optional.OptionalFunc())
+	}
+	return step.Func()(ctx, t, e.cfg)
+}
 
+// runOptionalStep executes the function of an optional assessment, recording
+// its failures in the result of the run instead of failing the test
+func (e *testEnv) runOptionalStep(ctx context.Context, t *testing.T, name string, fn types.OptionalStepFunc) context.Context {
+	ctx, failures := features.RunOptional(ctx, t, e.cfg, fn)
+	if len(failures) > 0 {
+		e.optional.record(t.Name(), failures)
+		t.Logf(`Optional assessment "%s" failed, not failing the feature: %d failure(s) recorded`, name, len(failures))
+	}
 	return ctx
 }
 
+// featureInfo is a read-only copy of a feature without step functions.
+type featureInfo struct {
+	name         string
+	labels       types.Labels
+	steps        []types.Step
+	parallel     bool
+	requirements types.Requirements
+	rateLimit    types.RateLimit
+}
+
+func (f *featureInfo) Name() string                     { return f.name }
+func (f *featureInfo) Labels() types.Labels             { return f.labels }
+func (f *featureInfo) Steps() []types.Step              { return f.steps }
+func (f *featureInfo) Parallel() bool                   { return f.parallel }
+func (f *featureInfo) Requirements() types.Requirements { return f.requirements }
+func (f *featureInfo) RateLimit() types.RateLimit       { return f.rateLimit }
+
+// stepInfo is a copy of a step without its function.
+type stepInfo struct {
+	name     string
+	level    types.Level
+	location string
+}
+
+func (s *stepInfo) Name() string         { return s.name }
+func (s *stepInfo) Level() types.Level   { return s.level }
+func (s *stepInfo) Func() types.StepFunc { return nil }
+func (s *stepInfo) Location() string     { return s.location }
+
 // deepCopyFeature just copies the values from the Feature but creates a deep
 // copy to avoid mutation when we just want an informational copy.
 func deepCopyFeature(f types.Feature) types.Feature {
-	fcopy := features.New(f.Name())
+	req := features.GetRequirements(f)
+	fcopy := &featureInfo{
+		name:     f.Name(),
+		labels:   make(types.Labels),
+		parallel: features.IsParallel(f),
+		requirements: types.Requirements{
+			MinKubeVersion: req.MinKubeVersion,
+			APIResources:   append([]schema.GroupVersionResource(nil), req.APIResources...),
+		},
+		rateLimit: features.GetRateLimit(f),
+	}
 	for k, v := range f.Labels() {
-		fcopy = fcopy.WithLabel(k, v)
+		fcopy.labels[k] = v
+	}
+	if req.NodeSelector != nil {
+		fcopy.requirements.NodeSelector = make(map[string]string, len(req.NodeSelector))
+		for k, v := range req.NodeSelector {
+			fcopy.requirements.NodeSelector[k] = v
+		}
 	}
-	f.Steps()
 	for _, step := range f.Steps() {
-		fcopy = fcopy.WithStep(step.Name(), step.Level(), nil)
+		fcopy.steps = append(fcopy.steps, &stepInfo{name: step.Name(), level: step.Level(), location: features.StepLocation(step)})
 	}
-	return fcopy.Feature()
+	return fcopy
 }
@@ -18,13 +18,33 @@ package env
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+
+	"sigs.k8s.io/e2e-framework/klient/apicalls"
+	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
 	"sigs.k8s.io/e2e-framework/pkg/internal/types"
 
 	"sigs.k8s.io/e2e-framework/pkg/envconf"
 	"sigs.k8s.io/e2e-framework/pkg/features"
+	"sigs.k8s.io/e2e-framework/pkg/tracing"
 )
 
 func TestEnv_New(t *testing.T) {
@@ -535,29 +555,26 @@ func TestEnv_Context_Propagation(t *testing.T) {
 
 func TestTestEnv_TestInParallel(t *testing.T) {
 	env := NewParallel()
-	beforeEachCallCount := 0
-	afterEachCallCount := 0
-	beforeFeatureCount := 0
-	afterFeatureCount := 0
+	var beforeEachCallCount, afterEachCallCount, beforeFeatureCount, afterFeatureCount int32
 	env.BeforeEachTest(func(ctx context.Context, config *envconf.Config, t *testing.T) (context.Context, error) {
-		beforeEachCallCount++
+		atomic.AddInt32(&beforeEachCallCount, 1)
 		return ctx, nil
 	})
 
 	env.AfterEachTest(func(ctx context.Context, config *envconf.Config, t *testing.T) (context.Context, error) {
-		afterEachCallCount++
+		atomic.AddInt32(&afterEachCallCount, 1)
 		return ctx, nil
 	})
 
 	env.BeforeEachFeature(func(ctx context.Context, config *envconf.Config, _ *testing.T, feature types.Feature) (context.Context, error) {
 		t.Logf("Running before each feature for feature %s", feature.Name())
-		beforeFeatureCount++
+		atomic.AddInt32(&beforeFeatureCount, 1)
 		return ctx, nil
 	})
 
 	env.AfterEachFeature(func(ctx context.Context, config *envconf.Config, _ *testing.T, feature types.Feature) (context.Context, error) {
 		t.Logf("Running after each feature for feature %s", feature.Name())
-		afterFeatureCount++
+		atomic.AddInt32(&afterFeatureCount, 1)
 		return ctx, nil
 	})
 
@@ -580,7 +597,824 @@ func TestTestEnv_TestInParallel(t *testing.T) {
 		})
 
 	env.TestInParallel(t, f1.Feature(), f2.Feature())
-	if beforeEachCallCount > 1 {
+	if atomic.LoadInt32(&beforeEachCallCount) > 1 {
 		t.Fatal("BeforeEachTest handler should be invoked only once")
 	}
 }
+
+func TestEnv_ConcurrentRun(t *testing.T) {
+	const count = 4
+	results := make([][]string, count)
+
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("env-%d", i)
+			env, err := NewWithContext(context.WithValue(context.Background(), &ctxTestKeyString{}, []string{}), envconf.New().WithNamespace(name))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			appendVal := func(ctx context.Context, val string) context.Context {
+				return context.WithValue(ctx, &ctxTestKeyString{}, append(ctx.Value(&ctxTestKeyString{}).([]string), val))
+			}
+			env.Setup(func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+				return appendVal(ctx, "setup-"+cfg.Namespace()), nil
+			}).Finish(func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+				ctx = appendVal(ctx, "finish-"+cfg.Namespace())
+				results[i] = ctx.Value(&ctxTestKeyString{}).([]string)
+				return ctx, nil
+			})
+
+			env.(*testEnv).run(func() int {
+				f := features.New(name).Assess("assess", func(ctx context.Context, t *testing.T, cfg *envconf.Config) context.Context {
+					return appendVal(ctx, "assess-"+cfg.Namespace())
+				})
+				env.Test(t, f.Feature())
+				return 0
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	for i, result := range results {
+		name := fmt.Sprintf("env-%d", i)
+		expected := []string{"setup-" + name, "assess-" + name, "finish-" + name}
+		if len(result) != len(expected) {
+			t.Fatalf("Expected:\n%v but got result:\n%v", expected, result)
+		}
+		for j := range expected {
+			if result[j] != expected[j] {
+				t.Errorf("Expected:\n%v but got result:\n%v", expected, result)
+				break
+			}
+		}
+	}
+}
+
+func TestEnv_Test_WithParallelFeatures(t *testing.T) {
+	env := newTestEnv()
+
+	var mu sync.Mutex
+	var order []string
+	record := func(val string) {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, val)
+	}
+
+	// both parallel features wait for each other, which only
+	// completes if they are run concurrently
+	var barrier sync.WaitGroup
+	barrier.Add(2)
+	parallelStep := func(name string) features.Func {
+		return func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+			barrier.Done()
+			done := make(chan struct{})
+			go func() { barrier.Wait(); close(done) }()
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Error("parallel features were not run concurrently")
+			}
+			record(name)
+			return ctx
+		}
+	}
+
+	p1 := features.New("parallel-1").WithParallel().Assess("assess", parallelStep("parallel-1"))
+	serial := features.New("serial").Assess("assess", func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+		record("serial")
+		return ctx
+	})
+	p2 := features.New("parallel-2").WithParallel().Assess("assess", parallelStep("parallel-2"))
+
+	env.Test(t, p1.Feature(), serial.Feature(), p2.Feature())
+
+	if len(order) != 3 || order[0] != "serial" {
+		t.Errorf("expected serial feature to run before parallel features, got: %v", order)
+	}
+}
+
+func TestEnv_Test_WithRunBudget(t *testing.T) {
+	env := NewWithConfig(envconf.New().WithRunBudget(100 * time.Millisecond))
+
+	var ran []string
+	step := func(name string, d time.Duration) features.Func {
+		return func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+			time.Sleep(d)
+			ran = append(ran, name)
+			return ctx
+		}
+	}
+	finished := false
+	env.Finish(func(ctx context.Context, _ *envconf.Config) (context.Context, error) {
+		finished = true
+		return ctx, nil
+	})
+
+	env.(*testEnv).run(func() int {
+		env.Test(t,
+			features.New("within-budget").Assess("assess", step("within-budget", 60*time.Millisecond)).Feature(),
+			features.New("over-budget").Assess("assess", step("over-budget", 0)).Feature(),
+		)
+		return 0
+	})
+
+	if len(ran) != 1 || ran[0] != "within-budget" {
+		t.Errorf("expected only the first feature to run within budget, got: %v", ran)
+	}
+	if !finished {
+		t.Error("expected finish funcs to run after budget was exceeded")
+	}
+}
+
+func TestEnv_Run_RunBudgetPerRun(t *testing.T) {
+	env := newTestEnv()
+	env.cfg = envconf.New().WithRunBudget(100 * time.Millisecond)
+
+	var ran int
+	feature := features.New("feature").Assess("assess", func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+		time.Sleep(30 * time.Millisecond)
+		ran++
+		return ctx
+	}).Feature()
+
+	// each run gets the whole budget, whatever the time spent by the previous runs
+	for i := 0; i < 3; i++ {
+		env.run(func() int {
+			env.Test(t, feature)
+			return 0
+		})
+		time.Sleep(50 * time.Millisecond)
+	}
+	if ran != 3 {
+		t.Errorf("expected the feature to run in each run, ran %d times", ran)
+	}
+}
+
+type fakeClusterClient struct {
+	cfg *rest.Config
+}
+
+func (f *fakeClusterClient) RESTConfig() *rest.Config { return f.cfg }
+
+func (f *fakeClusterClient) Resources(...string) *resources.Resources { return nil }
+
+func TestEnv_Test_WithRequirements(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/version":
+			w.Write([]byte(`{"major":"1","minor":"23","gitVersion":"v1.23.1"}`))
+		case "/apis/apps/v1":
+			w.Write([]byte(`{"kind":"APIResourceList","groupVersion":"apps/v1","resources":[{"name":"deployments","namespaced":true,"kind":"Deployment","verbs":["get"]}]}`))
+		case "/api/v1/nodes":
+			w.Write([]byte(`{"kind":"NodeList","apiVersion":"v1","items":[` +
+				`{"metadata":{"name":"linux","labels":{"kubernetes.io/os":"linux","kubernetes.io/arch":"amd64"}}},` +
+				`{"metadata":{"name":"arm","labels":{"kubernetes.io/os":"linux","kubernetes.io/arch":"arm64"}},"spec":{"unschedulable":true}}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	env := NewWithConfig(envconf.New().WithClient(&fakeClusterClient{cfg: &rest.Config{Host: server.URL}}))
+
+	var ran []string
+	feature := func(name string) *features.FeatureBuilder {
+		return features.New(name).Assess("assess", func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+			ran = append(ran, name)
+			return ctx
+		})
+	}
+	env.Test(t,
+		feature("satisfied").WithMinKubeVersion("1.22").RequireAPIResource(schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}).Feature(),
+		feature("too-old").WithMinKubeVersion("1.27").Feature(),
+		feature("missing-resource").RequireAPIResource(schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "widgets"}).Feature(),
+		feature("missing-group").RequireAPIResource(schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}).Feature(),
+		feature("linux").RequireOS("linux").RequireArch("amd64").Feature(),
+		feature("windows").RequireOS("windows").Feature(),
+		feature("unschedulable-arm").RequireArch("arm64").Feature(),
+		feature("no-requirements").Feature(),
+	)
+
+	if expected := []string{"satisfied", "linux", "no-requirements"}; !reflect.DeepEqual(ran, expected) {
+		t.Errorf("expected only features with satisfied requirements to run, got: %v", ran)
+	}
+}
+
+func TestEnv_Run_RequirementsAfterSetup(t *testing.T) {
+	var installed int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/apis/example.com/v1" && atomic.LoadInt32(&installed) == 1 {
+			w.Write([]byte(`{"kind":"APIResourceList","groupVersion":"example.com/v1","resources":[{"name":"widgets","namespaced":true,"kind":"Widget","verbs":["get"]}]}`))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	env := NewWithConfig(envconf.New().WithClient(&fakeClusterClient{cfg: &rest.Config{Host: server.URL}}))
+	env.Setup(func(ctx context.Context, _ *envconf.Config) (context.Context, error) {
+		atomic.StoreInt32(&installed, 1)
+		return ctx, nil
+	})
+
+	ran := 0
+	feature := features.New("widgets").
+		RequireAPIResource(schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}).
+		Assess("assess", func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+			ran++
+			return ctx
+		}).Feature()
+
+	// the resource is not served yet, which is cached
+	env.Test(t, feature)
+	env.(*testEnv).run(func() int {
+		env.Test(t, feature)
+		return 0
+	})
+	if ran != 1 {
+		t.Errorf("expected the feature to run once the setup installed the resource, ran %d time(s)", ran)
+	}
+}
+
+func TestEnv_Test_WithRateLimit(t *testing.T) {
+	env := NewWithConfig(envconf.New())
+
+	limits := map[string]float32{}
+	assess := func(name string) features.Func {
+		return func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+			if limiter := resources.RateLimiterFrom(ctx); limiter != nil {
+				limits[name] = limiter.QPS()
+			}
+			return ctx
+		}
+	}
+
+	env.Test(t,
+		features.New("limited").WithRateLimit(5, 1).Assess("assess", assess("limited")).Feature(),
+		features.New("unlimited").Assess("assess", assess("unlimited")).Feature(),
+	)
+
+	if limits["limited"] != 5 {
+		t.Errorf("expected feature rate limit of 5 qps, got: %v", limits["limited"])
+	}
+	if _, ok := limits["unlimited"]; ok {
+		t.Error("expected feature rate limit not to leak to the next feature")
+	}
+}
+
+// TestEnv_Test_TeardownAlwaysRuns runs failing features in a child process,
+// since their failures would otherwise fail the test itself.
+func TestEnv_Test_TeardownAlwaysRuns(t *testing.T) {
+	if os.Getenv("E2E_FRAMEWORK_FAILING_FEATURES") == "1" {
+		env := NewWithConfig(envconf.New())
+		env.AfterEachFeature(func(ctx context.Context, _ *envconf.Config, t *testing.T, f features.Feature) (context.Context, error) {
+			t.Logf("after feature %s", f.Name())
+			return ctx, nil
+		})
+		teardown := func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+			t.Log("teardown ran")
+			return ctx
+		}
+		env.Test(t,
+			features.New("assess-panic").
+				Assess("panics", func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context { panic("boom") }).
+				Assess("next", func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+					t.Log("next assessment ran")
+					return ctx
+				}).
+				Teardown(teardown).Feature(),
+			features.New("setup-fatal").
+				Setup(func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+					t.Fatal("setup failed")
+					return ctx
+				}).
+				Assess("skipped", func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+					t.Log("assessment after failed setup ran")
+					return ctx
+				}).
+				Teardown(teardown).Feature(),
+		)
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestEnv_Test_TeardownAlwaysRuns$", "-test.v")
+	cmd.Env = append(os.Environ(), "E2E_FRAMEWORK_FAILING_FEATURES=1")
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected failing features to fail the test:\n%s", out)
+	}
+	output := string(out)
+	for _, expected := range []string{
+		`step "panics" panicked: boom`,
+		"--- FAIL: TestEnv_Test_TeardownAlwaysRuns/assess-panic/panics",
+		"next assessment ran",
+		"after feature assess-panic",
+		"setup failed",
+		"after feature setup-fatal",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected output to contain %q:\n%s", expected, output)
+		}
+	}
+	if n := strings.Count(output, "teardown ran"); n != 2 {
+		t.Errorf("expected teardowns of both features to run, ran %d time(s):\n%s", n, output)
+	}
+	if strings.Contains(output, "assessment after failed setup ran") {
+		t.Errorf("expected assessments not to run after a failed setup:\n%s", output)
+	}
+}
+
+// TestEnv_Test_SkipTeardownOnFailure runs a failing feature in a child process,
+// since its failure would otherwise fail the test itself.
+func TestEnv_Test_SkipTeardownOnFailure(t *testing.T) {
+	if os.Getenv("E2E_FRAMEWORK_SKIP_TEARDOWN") == "1" {
+		env := NewWithConfig(envconf.New().WithNamespace("kept").WithSkipTeardownOnFailure())
+		env.AfterEachFeature(func(ctx context.Context, _ *envconf.Config, _ *testing.T, f features.Feature) (context.Context, error) {
+			fmt.Printf("after feature %s: teardown skipped %t\n", f.Name(), TeardownSkipped(ctx))
+			return ctx, nil
+		}).AfterEachTest(func(ctx context.Context, _ *envconf.Config, _ *testing.T) (context.Context, error) {
+			fmt.Printf("after test: teardown skipped %t\n", TeardownSkipped(ctx))
+			return ctx, nil
+		})
+		env.Finish(func(ctx context.Context, _ *envconf.Config) (context.Context, error) {
+			fmt.Println("finish ran")
+			return ctx, nil
+		})
+		teardown := func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+			t.Log("teardown ran")
+			return ctx
+		}
+		pass := func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context { return ctx }
+		env.(*testEnv).run(func() int {
+			env.Test(t,
+				features.New("passes").Assess("passes", pass).Teardown(teardown).Feature(),
+				features.New("fails").Assess("fails", func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+					t.Error("assessment failed")
+					return ctx
+				}).Teardown(teardown).Feature(),
+				features.New("passes-after").Assess("passes", pass).Teardown(teardown).Feature(),
+			)
+			return 0
+		})
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestEnv_Test_SkipTeardownOnFailure$", "-test.v")
+	cmd.Env = append(os.Environ(), "E2E_FRAMEWORK_SKIP_TEARDOWN=1")
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected the failing feature to fail the test:\n%s", out)
+	}
+	output := string(out)
+	if n := strings.Count(output, "teardown ran"); n != 1 {
+		t.Errorf("expected only the teardown of the feature before the failure to run, ran %d time(s):\n%s", n, output)
+	}
+	for _, expected := range []string{
+		`Skipping teardown of feature "fails": resources kept on failure, namespace "kept"`,
+		`Skipping teardown of feature "passes-after"`,
+		"Skipping Finish funcs after a test failure",
+		"after feature passes: teardown skipped false",
+		"after feature fails: teardown skipped true",
+		"after feature passes-after: teardown skipped true",
+		"after test: teardown skipped true",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected output to contain %q:\n%s", expected, output)
+		}
+	}
+	if strings.Contains(output, "finish ran") {
+		t.Errorf("expected the finish funcs to be skipped:\n%s", output)
+	}
+}
+
+func TestEnv_Test_Resume(t *testing.T) {
+	prev := packageScope
+	packageScope = func() (string, error) { return "/src/pkg", nil }
+	defer func() { packageScope = prev }()
+
+	stateFile := filepath.Join(t.TempDir(), "state")
+	// the same test of another package passed as well
+	other := "/src/other\t" + t.Name() + "/new\n"
+	previous := "/src/pkg\t" + t.Name() + "/passed-before\n" + other + "/src/pkg\t" + t.Name() + "/removed\n"
+	if err := os.WriteFile(stateFile, []byte(previous), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var ran []string
+	feature := func(name string) features.Feature {
+		return features.New(name).Assess("assess", func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+			ran = append(ran, name)
+			return ctx
+		}).Feature()
+	}
+
+	env := NewWithConfig(envconf.New().WithStateFile(stateFile).WithResume(true))
+	env.Test(t, feature("passed-before"), feature("new"))
+
+	if len(ran) != 1 || ran[0] != "new" {
+		t.Errorf("expected only the new feature to run when resuming, got: %v", ran)
+	}
+	data, err := os.ReadFile(stateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := previous + "/src/pkg\t" + t.Name() + "/new\n"; string(data) != expected {
+		t.Errorf("expected state file:\n%s\ngot:\n%s", expected, data)
+	}
+
+	// without resuming, the state file records a new run of the package
+	ran = nil
+	env = NewWithConfig(envconf.New().WithStateFile(stateFile))
+	env.Test(t, feature("passed-before"))
+
+	if len(ran) != 1 {
+		t.Errorf("expected the feature to run when not resuming, got: %v", ran)
+	}
+	data, err = os.ReadFile(stateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := other + "/src/pkg\t" + t.Name() + "/passed-before#01\n"; string(data) != expected {
+		t.Errorf("expected state file:\n%s\ngot:\n%s", expected, data)
+	}
+}
+
+func TestEnv_Test_AssessmentHooks(t *testing.T) {
+	type assessKey struct{}
+	var calls []string
+
+	env := NewWithConfig(envconf.New().WithSkipAssessmentRegex("skipped"))
+	env.BeforeEachAssessment(func(ctx context.Context, _ *envconf.Config, _ *testing.T, name string) (context.Context, error) {
+		calls = append(calls, "before "+name)
+		return context.WithValue(ctx, assessKey{}, name), nil
+	})
+	env.AfterEachAssessment(func(ctx context.Context, _ *envconf.Config, _ *testing.T, name string) (context.Context, error) {
+		calls = append(calls, "after "+name)
+		return ctx, nil
+	})
+
+	assess := func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+		calls = append(calls, fmt.Sprintf("assess %v", ctx.Value(assessKey{})))
+		return ctx
+	}
+	env.Test(t, features.New("hooks").
+		Assess("first", assess).
+		Assess("skipped", assess).
+		Assess("second", assess).
+		Feature())
+
+	expected := []string{
+		"before first", "assess first", "after first",
+		"before second", "assess second", "after second",
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected calls %v, got %v", expected, calls)
+	}
+}
+
+func TestEnv_Test_Tracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	env := NewWithConfig(envconf.New().WithTracerProvider(tp).WithSkipAssessmentRegex("skipped"))
+
+	assess := func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context { return ctx }
+	env.Test(t,
+		features.New("first").Assess("passes", assess).Assess("skipped", assess).Feature(),
+		features.New("second").Assess("passes", assess).Feature(),
+	)
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, s := range recorder.Ended() {
+		spans[s.Parent().SpanID().String()+"/"+s.Name()] = s
+	}
+	if len(spans) != 5 {
+		t.Fatalf("expected 5 spans, got %d", len(spans))
+	}
+
+	status := func(s sdktrace.ReadOnlySpan) string {
+		for _, attr := range s.Attributes() {
+			if attr.Key == tracing.StatusKey {
+				return attr.Value.AsString()
+			}
+		}
+		return ""
+	}
+	for _, feature := range []string{"first", "second"} {
+		fs, ok := spans[trace.SpanID{}.String()+"/"+feature]
+		if !ok {
+			t.Fatalf("expected a root span for feature %s", feature)
+		}
+		if status(fs) != "passed" {
+			t.Errorf("unexpected status of feature %s: %s", feature, status(fs))
+		}
+		as, ok := spans[fs.SpanContext().SpanID().String()+"/passes"]
+		if !ok {
+			t.Fatalf("expected an assessment span nested in feature %s", feature)
+		}
+		if status(as) != "passed" {
+			t.Errorf("unexpected status of assessment %s/passes: %s", feature, status(as))
+		}
+	}
+	if s := spans[spans[trace.SpanID{}.String()+"/first"].SpanContext().SpanID().String()+"/skipped"]; s == nil || status(s) != "skipped" {
+		t.Error("expected the skipped assessment span to be recorded as skipped")
+	}
+}
+
+func TestEnv_Test_WithLabelFilter(t *testing.T) {
+	var ran []string
+	feature := func(name string, lbls map[string]string) features.Feature {
+		builder := features.New(name)
+		for k, v := range lbls {
+			builder = builder.WithLabel(k, v)
+		}
+		return builder.Assess("assess", func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+			ran = append(ran, name)
+			return ctx
+		}).Feature()
+	}
+
+	env := NewWithConfig(envconf.New().WithLabelFilter("conformance && !slow || gpu"))
+	env.Test(t,
+		feature("conformance", map[string]string{"type": "conformance"}),
+		feature("slow-conformance", map[string]string{"type": "conformance", "slow": "true"}),
+		feature("gpu", map[string]string{"gpu": "nvidia", "slow": "true"}),
+		feature("unlabeled", nil),
+	)
+
+	if expected := []string{"conformance", "gpu"}; !reflect.DeepEqual(ran, expected) {
+		t.Errorf("expected features %v to run, got %v", expected, ran)
+	}
+}
+
+func TestEnv_Run_Result(t *testing.T) {
+	setupErr := errors.New("setup failed")
+	finishErr := errors.New("finish failed")
+	noop := func(ctx context.Context, _ *envconf.Config) (context.Context, error) { return ctx, nil }
+
+	t.Run("setup failure", func(t *testing.T) {
+		env := newTestEnv()
+		env.Setup(noop).Setup(noop, func(ctx context.Context, _ *envconf.Config) (context.Context, error) {
+			return ctx, setupErr
+		}).Finish(func(ctx context.Context, _ *envconf.Config) (context.Context, error) {
+			t.Error("finish func should not run after a setup failure")
+			return ctx, nil
+		})
+
+		exitCode := env.run(func() int {
+			t.Error("tests should not run after a setup failure")
+			return 0
+		})
+		result := Result(env)
+		if exitCode != 1 || result.ExitCode != 1 {
+			t.Errorf("expected exit code 1, got %d (result %d)", exitCode, result.ExitCode)
+		}
+		if result.SetupError == nil {
+			t.Fatal("expected a setup error")
+		}
+		if result.SetupError.Role != "Setup" || result.SetupError.Index != 2 || !errors.Is(result.SetupError, setupErr) {
+			t.Errorf("unexpected setup error: %v", result.SetupError)
+		}
+		if !strings.Contains(result.SetupError.Name, "TestEnv_Run_Result") {
+			t.Errorf("expected the name of the failed func, got %s", result.SetupError.Name)
+		}
+	})
+
+	t.Run("finish failures", func(t *testing.T) {
+		env := newTestEnv()
+		fail := func(ctx context.Context, _ *envconf.Config) (context.Context, error) { return ctx, finishErr }
+		env.Setup(noop).Finish(fail, noop).Finish(fail)
+
+		exitCode := env.run(func() int { return 3 })
+		result := Result(env)
+		if exitCode != 3 || result.ExitCode != 3 || result.SetupError != nil {
+			t.Errorf("unexpected result: exit code %d, %+v", exitCode, result)
+		}
+		if len(result.FinishErrors) != 2 {
+			t.Fatalf("expected 2 finish errors, got %v", result.FinishErrors)
+		}
+		for i, index := range []int{0, 2} {
+			if err := result.FinishErrors[i]; err.Index != index || !errors.Is(err, finishErr) {
+				t.Errorf("unexpected finish error %d: %v", i, err)
+			}
+		}
+		if !result.Failed() {
+			t.Error("expected the run to have failed")
+		}
+	})
+}
+
+func TestEnv_TestSuite(t *testing.T) {
+	var order []string
+	record := func(val string) features.Func {
+		return func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+			order = append(order, val)
+			return ctx
+		}
+	}
+	suite := features.NewSuite("storage").
+		WithLabel("tier", "1").
+		Setup(func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+			order = append(order, "suite-setup")
+			return context.WithValue(ctx, &ctxTestKeyString{}, "shared")
+		}).
+		Teardown(record("suite-teardown")).
+		WithFeatures(
+			features.New("volumes").Assess("assess", func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+				order = append(order, "volumes-"+ctx.Value(&ctxTestKeyString{}).(string))
+				return ctx
+			}).Feature(),
+			features.New("slow-snapshots").WithLabel("slow", "true").Assess("assess", record("slow-snapshots")).Feature(),
+			features.New("other-tier").WithLabel("tier", "2").Assess("assess", record("other-tier")).Feature(),
+		).Suite()
+
+	env := NewWithConfig(envconf.New().WithLabelFilter("tier=1 && !slow"))
+	env.TestSuite(t, suite)
+
+	if expected := []string{"suite-setup", "volumes-shared", "suite-teardown"}; !reflect.DeepEqual(order, expected) {
+		t.Errorf("expected %v, got %v", expected, order)
+	}
+}
+
+func TestEnv_TestSuite_SetupFailure(t *testing.T) {
+	if os.Getenv("E2E_FRAMEWORK_FAILING_SUITE") == "1" {
+		suite := features.NewSuite("failing").
+			Setup(func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+				t.Error("setup failed")
+				return ctx
+			}).
+			Teardown(func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+				fmt.Println("suite teardown ran")
+				return ctx
+			}).
+			WithFeatures(features.New("feature").Assess("assess", func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+				fmt.Println("feature ran")
+				return ctx
+			}).Feature()).Suite()
+		NewWithConfig(envconf.New()).TestSuite(t, suite)
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestEnv_TestSuite_SetupFailure$", "-test.v")
+	cmd.Env = append(os.Environ(), "E2E_FRAMEWORK_FAILING_SUITE=1")
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected the suite to fail, output:\n%s", out)
+	}
+	if strings.Contains(string(out), "feature ran") {
+		t.Errorf("expected the features to be skipped after a setup failure, output:\n%s", out)
+	}
+	if !strings.Contains(string(out), "suite teardown ran") {
+		t.Errorf("expected the suite teardown to run after a setup failure, output:\n%s", out)
+	}
+	if !strings.Contains(string(out), `Suite "failing": 0 passed, 0 failed, 0 skipped features`) {
+		t.Errorf("expected the suite summary, output:\n%s", out)
+	}
+}
+
+func TestEnv_Test_OptionalAssessment(t *testing.T) {
+	env := newTestEnv()
+	env.cfg = envconf.New()
+
+	var ran []string
+	feature := features.New("canary").
+		AssessOptional("flaky probe", func(ctx context.Context, t testing.TB, _ *envconf.Config) context.Context {
+			t.Errorf("probe returned %d", 503)
+			t.Fatal("giving up")
+			ran = append(ran, "unreachable")
+			return ctx
+		}).
+		AssessOptional("stable probe", func(ctx context.Context, t testing.TB, _ *envconf.Config) context.Context {
+			ran = append(ran, "stable")
+			return ctx
+		}).
+		Assess("required", func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+			ran = append(ran, "required")
+			return ctx
+		}).Feature()
+
+	env.run(func() int {
+		env.Test(t, feature)
+		return 0
+	})
+
+	if expected := []string{"stable", "required"}; !reflect.DeepEqual(ran, expected) {
+		t.Errorf("expected assessments %v to run, got %v", expected, ran)
+	}
+	result := Result(env)
+	if result.Failed() {
+		t.Errorf("expected the optional failure not to fail the run: %+v", result)
+	}
+	expected := []OptionalFailure{{
+		Test:     "TestEnv_Test_OptionalAssessment/canary/flaky_probe",
+		Messages: []string{"probe returned 503", "giving up"},
+	}}
+	if !reflect.DeepEqual(result.OptionalFailures, expected) {
+		t.Errorf("expected optional failures %+v, got %+v", expected, result.OptionalFailures)
+	}
+}
+
+func TestEnv_Run_SuiteTimeout(t *testing.T) {
+	env := newTestEnv()
+	env.cfg = envconf.New().WithSuiteTimeout(100 * time.Millisecond)
+
+	var finishErr error
+	env.Finish(func(ctx context.Context, _ *envconf.Config) (context.Context, error) {
+		finishErr = ctx.Err()
+		return ctx, nil
+	})
+
+	var teardownErr error
+	slow := features.New("slow").
+		Assess("wait for cancellation", func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+			select {
+			case <-ctx.Done():
+			case <-time.After(5 * time.Second):
+				t.Error("expected the context to be cancelled by the suite timeout")
+			}
+			return ctx
+		}).
+		Teardown(func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+			teardownErr = ctx.Err()
+			return ctx
+		}).Feature()
+	remaining := features.New("remaining").Assess("assess", func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+		t.Error("expected the feature to be skipped after the suite timeout")
+		return ctx
+	}).Feature()
+
+	exitCode := env.run(func() int {
+		env.Test(t, slow, remaining)
+		return 0
+	})
+
+	if exitCode != 0 {
+		t.Errorf("expected exit code 0, got %d", exitCode)
+	}
+	if teardownErr != nil || finishErr != nil {
+		t.Errorf("expected the teardowns and finish funcs to run with a live context, got %v and %v", teardownErr, finishErr)
+	}
+	if expected := []string{"TestEnv_Run_SuiteTimeout/remaining"}; !reflect.DeepEqual(Result(env).BudgetSkipped, expected) {
+		t.Errorf("expected budget-skipped features %v, got %v", expected, Result(env).BudgetSkipped)
+	}
+}
+
+func TestEnv_Run_SuiteTimeout_CompletedInTime(t *testing.T) {
+	env := newTestEnv()
+	env.cfg = envconf.New().WithSuiteTimeout(time.Hour)
+
+	finishErr := errors.New("finish func not run")
+	env.Finish(func(ctx context.Context, _ *envconf.Config) (context.Context, error) {
+		finishErr = ctx.Err()
+		return ctx, nil
+	})
+
+	env.run(func() int {
+		env.Test(t, features.New("fast").Assess("assess", func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+			return ctx
+		}).Feature())
+		return 0
+	})
+
+	if finishErr != nil {
+		t.Errorf("expected the finish funcs to run with a live context, got %v", finishErr)
+	}
+}
+
+func TestEnv_Test_APICallCounting(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+
+	env := newTestEnv()
+	env.cfg = envconf.New().WithAPICallCounting()
+	client := &http.Client{Transport: env.cfg.APICalls().Wrap(http.DefaultTransport)}
+	list := func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/v1/namespaces/default/pods", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return ctx
+	}
+	feature := features.New("pods").Assess("list", list).Teardown(list).Feature()
+
+	env.run(func() int {
+		env.Test(t, feature)
+		return 0
+	})
+
+	expected := []apicalls.Call{{Scope: "TestEnv_Test_APICallCounting/pods", Verb: "list", Resource: "pods", Count: 2}}
+	if calls := env.cfg.APICalls().Calls(); !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected calls %+v, got %+v", expected, calls)
+	}
+}
@@ -0,0 +1,393 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package env
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	log "k8s.io/klog/v2"
+
+	"sigs.k8s.io/e2e-framework/pkg/internal/types"
+)
+
+// sharedSetup tracks the setup of a base environment shared by
+// the environments extending it.
+type sharedSetup struct {
+	mu   sync.Mutex
+	refs int
+	ran  bool
+	ctx  context.Context
+	err  error
+	// stateFile shares the setup across processes when set, see WithSharedStateFile
+	stateFile string
+	// lockTimeout is how long to wait for the lock of the state file
+	lockTimeout time.Duration
+	// pid identifies the process in the state file
+	pid int
+}
+
+// DefaultSharedStateLockTimeout is how long a process waits for another process to release
+// the lock of the shared state file, which is held while the Setup funcs of the base run
+const DefaultSharedStateLockTimeout = 15 * time.Minute
+
+// ExtendOption configures how the base environment is shared, see Extend
+type ExtendOption func(*sharedSetup)
+
+// WithSharedStateFile shares the base environment with the other processes, i.e. the other
+// test packages run by go test, extending it with the same state file.
+//
+// The Setup funcs of base are run by the first process only. The kubeconfig file and namespace
+// of the configuration they produce are recorded in the state file, and set on the configuration
+// of the processes started while base is in use, which skip the Setup funcs. The values the Setup
+// funcs put in the context are only available to the process that ran them.
+//
+// The state file records the PIDs of the processes using base, and the Finish funcs of base are
+// run by the last one done with it, in a context without the values of the Setup funcs, i.e.
+// envfuncs.DestroyKindCluster then destroys the cluster by its name. Processes that exited without
+// releasing base, i.e. killed, are ignored, and when none is left the Setup funcs are run again.
+//
+// The state file is guarded by a lock file, named after it with a .lock suffix. A lock left behind
+// by a killed process is removed, and waiting for the lock fails after the timeout set with
+// WithSharedStateLockTimeout.
+func WithSharedStateFile(path string) ExtendOption {
+	return func(s *sharedSetup) {
+		s.stateFile = path
+	}
+}
+
+// WithSharedStateLockTimeout sets how long to wait for the lock of the shared state file held by
+// another process, i.e. running the Setup funcs of base, DefaultSharedStateLockTimeout by default
+func WithSharedStateLockTimeout(timeout time.Duration) ExtendOption {
+	return func(s *sharedSetup) {
+		s.lockTimeout = timeout
+	}
+}
+
+// Extend creates a new environment from base. The new environment shares the
+// configuration of base and inherits its BeforeEachTest, BeforeEachFeature,
+// AfterEachFeature, and AfterEachTest funcs. Additional funcs, including Setup and
+// Finish funcs, can be registered on the new environment without altering base.
+//
+// When the new environment is Run, the Setup funcs of base are run first, and only
+// once for all the environments extending base that are running at that time, with
+// the resulting context passed to the new environment. The Finish funcs of base are
+// run once the last of these environments is done.
+//
+// Since each Go test package runs as its own process, the Setup funcs of base are still
+// run once per package, and its Finish funcs once all the environments of the package are
+// done. Use WithSharedStateFile to share base across packages.
+//
+// The context values of the new environment, i.e. set with WithContext, remain visible
+// to its funcs along with the values set by the Setup funcs of base.
+func Extend(base types.Environment, opts ...ExtendOption) types.Environment {
+	parent, ok := base.(*testEnv)
+	if !ok {
+		panic("env: Extend requires an environment created by the env package")
+	}
+	parent.sharedOnce.Do(func() {
+		parent.shared = &sharedSetup{lockTimeout: DefaultSharedStateLockTimeout, pid: os.Getpid()}
+	})
+	parent.shared.mu.Lock()
+	for _, opt := range opts {
+		opt(parent.shared)
+	}
+	parent.shared.mu.Unlock()
+
+	child := &testEnv{
+		ctx:  parent.ctx,
+		cfg:  parent.cfg,
+		rnd:  parent.rnd,
+		base: parent,
+	}
+	for _, a := range parent.actions {
+		if a.role != roleSetup && a.role != roleFinish {
+			child.actions = append(child.actions, a)
+		}
+	}
+	return child
+}
+
+// RunTests runs the Setup funcs of the environment, then runTests, then its Finish funcs,
+// and returns the result of runTests. It is the equivalent of Environment.Run for test
+// runners other than testing.M, i.e. a Ginkgo suite run with RunSpecs from a TestXxx func.
+// Panics if e was not created by the env package.
+func RunTests(e types.Environment, runTests func() int) int {
+	te, ok := e.(*testEnv)
+	if !ok {
+		panic("env: RunTests requires an environment created by the env package")
+	}
+	return te.run(runTests)
+}
+
+// acquireSetup runs the setup of the environment, and of its own base if any, unless
+// already done for another extending environment, and returns the resulting context.
+func (e *testEnv) acquireSetup() (context.Context, error) {
+	e.shared.mu.Lock()
+	defer e.shared.mu.Unlock()
+
+	e.shared.refs++
+	if !e.shared.ran {
+		ctx := e.ctx
+		var err error
+		if e.base != nil {
+			ctx, err = e.base.acquireSetup()
+		}
+		if err == nil {
+			if e.shared.stateFile != "" {
+				ctx, err = e.acquireSharedState(ctx)
+			} else {
+				ctx, err = e.runSetups(ctx)
+			}
+		}
+		e.shared.ctx, e.shared.err, e.shared.ran = ctx, err, true
+	}
+	return e.shared.ctx, e.shared.err
+}
+
+// acquireSharedState runs the setup of the environment, unless another live process
+// did, while holding the lock of the shared state file, and records the process as
+// using the environment.
+func (e *testEnv) acquireSharedState(ctx context.Context) (context.Context, error) {
+	unlock, err := lockStateFile(e.shared.stateFile, e.shared.lockTimeout)
+	if err != nil {
+		return ctx, err
+	}
+	defer unlock()
+
+	state, err := readSharedState(e.shared.stateFile)
+	if err != nil {
+		return ctx, err
+	}
+	state.PIDs = livePIDs(state.PIDs)
+	if len(state.PIDs) > 0 {
+		log.V(4).Infof("Reusing base environment set up by process(es) %v with %s", state.PIDs, e.shared.stateFile)
+		if state.Kubeconfig != "" {
+			e.cfg.WithKubeconfigFile(state.Kubeconfig)
+		}
+		if state.Namespace != "" {
+			e.cfg.WithNamespace(state.Namespace)
+		}
+	} else {
+		if ctx, err = e.runSetups(ctx); err != nil {
+			return ctx, err
+		}
+		state.Kubeconfig, state.Namespace = e.cfg.KubeconfigFile(), e.cfg.Namespace()
+	}
+	state.PIDs = append(state.PIDs, e.shared.pid)
+	return ctx, writeSharedState(e.shared.stateFile, state)
+}
+
+// releaseSharedState records the process as done with the environment, and runs
+// its finish funcs when no more live processes use it.
+func (e *testEnv) releaseSharedState(skipFinish bool) []*ActionError {
+	unlock, err := lockStateFile(e.shared.stateFile, e.shared.lockTimeout)
+	if err != nil {
+		return []*ActionError{asActionError(roleFinish, err)}
+	}
+	defer unlock()
+
+	state, err := readSharedState(e.shared.stateFile)
+	if err != nil {
+		return []*ActionError{asActionError(roleFinish, err)}
+	}
+	var others []int
+	for _, pid := range livePIDs(state.PIDs) {
+		if pid != e.shared.pid {
+			others = append(others, pid)
+		}
+	}
+	if state.PIDs = others; len(state.PIDs) > 0 {
+		log.V(4).Infof("Base environment still used by process(es) %v, skipping its Finish funcs", state.PIDs)
+		if err := writeSharedState(e.shared.stateFile, state); err != nil {
+			return []*ActionError{asActionError(roleFinish, err)}
+		}
+		return nil
+	}
+	if err := os.Remove(e.shared.stateFile); err != nil && !os.IsNotExist(err) {
+		return []*ActionError{asActionError(roleFinish, fmt.Errorf("shared state: %w", err))}
+	}
+	if skipFinish {
+		e.logPreserved("Finish funcs of the base environment")
+		return nil
+	}
+	_, errs := e.runFinishes(e.shared.ctx)
+	return errs
+}
+
+// releaseSetup runs the finish funcs of the environment, and releases its own base
+// if any, once no more extending environments are running. It returns the errors
+// of the finish funcs that failed. The finish funcs are skipped when skipFinish is
+// true, to keep the resources of a failed run.
+func (e *testEnv) releaseSetup(skipFinish bool) []*ActionError {
+	e.shared.mu.Lock()
+	defer e.shared.mu.Unlock()
+
+	e.shared.refs--
+	if e.shared.refs > 0 || !e.shared.ran {
+		return nil
+	}
+	var errs []*ActionError
+	if e.shared.err == nil {
+		switch {
+		case e.shared.stateFile != "":
+			errs = e.releaseSharedState(skipFinish)
+		case skipFinish:
+			e.logPreserved("Finish funcs of the base environment")
+		default:
+			_, errs = e.runFinishes(e.shared.ctx)
+		}
+	}
+	e.shared.ran = false
+	if e.base != nil {
+		errs = append(errs, e.base.releaseSetup(skipFinish)...)
+	}
+	return errs
+}
+
+// sharedState is the content of the state file of a base environment shared across processes
+type sharedState struct {
+	// PIDs are the processes using the environment
+	PIDs []int `json:"pids"`
+	// Kubeconfig and Namespace are set on the configuration by the Setup funcs of the environment
+	Kubeconfig string `json:"kubeconfig,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+}
+
+func readSharedState(path string) (sharedState, error) {
+	var state sharedState
+	data, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return state, fmt.Errorf("shared state: %w", err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("shared state: %s: %w", path, err)
+	}
+	return state, nil
+}
+
+func writeSharedState(path string, state sharedState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("shared state: %w", err)
+	}
+	if err := ioutil.WriteFile(path, data, 0o644); err != nil { // nolint:gosec
+		return fmt.Errorf("shared state: %w", err)
+	}
+	return nil
+}
+
+// lockPollInterval is the interval at which a held lock file is checked
+var lockPollInterval = 100 * time.Millisecond
+
+// lockStateFile acquires the lock file of the state file, waiting up to timeout for other
+// processes to release it, and returns the func releasing it. A lock held by a process
+// that no longer runs is removed.
+func lockStateFile(path string, timeout time.Duration) (func(), error) {
+	lock := path + ".lock"
+	deadline := time.Now().Add(timeout)
+	logged := false
+	for {
+		f, err := os.OpenFile(lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644) // nolint:gosec
+		if err == nil {
+			fmt.Fprintf(f, "%d", os.Getpid())
+			f.Close()
+			return func() {
+				if err := os.Remove(lock); err != nil {
+					log.Errorf("shared state: release lock: %s", err)
+				}
+			}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("shared state: lock: %w", err)
+		}
+
+		if pid, ok := lockHolder(lock); ok && !processAlive(pid) {
+			log.V(4).Infof("Removing the lock %s of process %d, which is no longer running", lock, pid)
+			if err := os.Remove(lock); err != nil && !os.IsNotExist(err) {
+				return nil, fmt.Errorf("shared state: remove stale lock: %w", err)
+			}
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("shared state: lock %s still held after %s", lock, timeout)
+		}
+		if !logged {
+			log.V(4).Infof("Waiting for the lock %s held by another process", lock)
+			logged = true
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// lockHolder returns the PID written in the lock file, if any
+func lockHolder(lock string) (int, bool) {
+	data, err := ioutil.ReadFile(lock)
+	if err != nil {
+		return 0, false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	return pid, err == nil && pid > 0
+}
+
+// livePIDs returns the PIDs of the processes still running
+func livePIDs(pids []int) []int {
+	var live []int
+	for _, pid := range pids {
+		if processAlive(pid) {
+			live = append(live, pid)
+		}
+	}
+	return live
+}
+
+// processAlive reports whether the process is running, by sending it the null signal
+var processAlive = func(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// extendedContext is the context of an extending environment once the setup of its base
+// ran: the values set by the base setup are looked up first, then the values of the
+// context of the extending environment, i.e. set with WithContext.
+type extendedContext struct {
+	context.Context
+	base context.Context
+}
+
+func (c extendedContext) Value(key interface{}) interface{} {
+	if v := c.base.Value(key); v != nil {
+		return v
+	}
+	return c.Context.Value(key)
+}
@@ -0,0 +1,232 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package env
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+)
+
+func TestExtend(t *testing.T) {
+	var baseSetups, baseFinishes, childSetups, beforeTests int32
+
+	base := New()
+	base.Setup(func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		atomic.AddInt32(&baseSetups, 1)
+		cfg.WithNamespace("shared")
+		return context.WithValue(ctx, &ctxTestKeyString{}, "from-base"), nil
+	}).BeforeEachTest(func(ctx context.Context, _ *envconf.Config, _ *testing.T) (context.Context, error) {
+		atomic.AddInt32(&beforeTests, 1)
+		return ctx, nil
+	}).Finish(func(ctx context.Context, _ *envconf.Config) (context.Context, error) {
+		atomic.AddInt32(&baseFinishes, 1)
+		return ctx, nil
+	})
+
+	// both children wait for each other while running their tests so
+	// that they share a single run of the base setup
+	var running sync.WaitGroup
+	running.Add(2)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		child := Extend(base)
+		child.Setup(func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+			atomic.AddInt32(&childSetups, 1)
+			return ctx, nil
+		})
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			child.(*testEnv).run(func() int {
+				running.Done()
+				running.Wait()
+				f := features.New("extended").Assess("base context", func(ctx context.Context, t *testing.T, cfg *envconf.Config) context.Context {
+					if ctx.Value(&ctxTestKeyString{}) != "from-base" {
+						t.Error("expected context value set by base setup")
+					}
+					if cfg.Namespace() != "shared" {
+						t.Error("expected config updated by base setup")
+					}
+					return ctx
+				})
+				child.Test(t, f.Feature())
+				if atomic.LoadInt32(&baseFinishes) != 0 {
+					t.Error("base finish run while extending environments are running")
+				}
+				return 0
+			})
+		}()
+	}
+	wg.Wait()
+
+	if baseSetups != 1 {
+		t.Errorf("expected base setup to run once, ran %d times", baseSetups)
+	}
+	if baseFinishes != 1 {
+		t.Errorf("expected base finish to run once, ran %d times", baseFinishes)
+	}
+	if childSetups != 2 {
+		t.Errorf("expected each child setup to run, ran %d times", childSetups)
+	}
+	if beforeTests != 2 {
+		t.Errorf("expected inherited before-test to run for each child, ran %d times", beforeTests)
+	}
+}
+
+func TestExtend_WithContext(t *testing.T) {
+	base := New()
+	base.Setup(func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		return context.WithValue(ctx, &ctxTestKeyString{}, "from-base"), nil
+	})
+
+	type childKey struct{}
+	child := Extend(base).WithContext(context.WithValue(context.Background(), childKey{}, "from-child"))
+	child.(*testEnv).run(func() int {
+		f := features.New("extended").Assess("contexts", func(ctx context.Context, t *testing.T, cfg *envconf.Config) context.Context {
+			if ctx.Value(&ctxTestKeyString{}) != "from-base" {
+				t.Error("expected context value set by base setup")
+			}
+			if ctx.Value(childKey{}) != "from-child" {
+				t.Error("expected context value of the extending environment")
+			}
+			return ctx
+		})
+		child.Test(t, f.Feature())
+		return 0
+	})
+}
+
+func TestExtend_WithSharedStateFile(t *testing.T) {
+	prev := processAlive
+	processAlive = func(int) bool { return true }
+	defer func() { processAlive = prev }()
+
+	stateFile := filepath.Join(t.TempDir(), "base.json")
+	var setups, finishes int32
+
+	// each base stands for the base environment of a separate test package process
+	newChild := func(pid int) *testEnv {
+		base := New()
+		base.Setup(func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+			atomic.AddInt32(&setups, 1)
+			cfg.WithKubeconfigFile("/tmp/kubeconfig").WithNamespace("shared")
+			return ctx, nil
+		}).Finish(func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+			atomic.AddInt32(&finishes, 1)
+			return ctx, nil
+		})
+		child := Extend(base, WithSharedStateFile(stateFile)).(*testEnv)
+		child.base.shared.pid = pid
+		return child
+	}
+
+	first, second := newChild(1001), newChild(1002)
+	first.run(func() int {
+		second.run(func() int {
+			state, err := readSharedState(stateFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(state.PIDs, []int{1001, 1002}) || state.Kubeconfig != "/tmp/kubeconfig" || state.Namespace != "shared" {
+				t.Errorf("unexpected shared state: %+v", state)
+			}
+			if second.cfg.KubeconfigFile() != "/tmp/kubeconfig" || second.cfg.Namespace() != "shared" {
+				t.Errorf("expected the config of the first process, got %s %s", second.cfg.KubeconfigFile(), second.cfg.Namespace())
+			}
+			return 0
+		})
+		if atomic.LoadInt32(&finishes) != 0 {
+			t.Error("base finish run while another process uses it")
+		}
+		return 0
+	})
+
+	if setups != 1 {
+		t.Errorf("expected base setup to run once, ran %d times", setups)
+	}
+	if finishes != 1 {
+		t.Errorf("expected base finish to run once, ran %d times", finishes)
+	}
+	for _, file := range []string{stateFile, stateFile + ".lock"} {
+		if _, err := os.Stat(file); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed: %v", file, err)
+		}
+	}
+	if len(first.result.FinishErrors) != 0 || len(second.result.FinishErrors) != 0 {
+		t.Errorf("unexpected finish errors: %v %v", first.result.FinishErrors, second.result.FinishErrors)
+	}
+}
+
+func TestExtend_WithSharedStateFile_DeadProcesses(t *testing.T) {
+	prev := processAlive
+	processAlive = func(pid int) bool { return pid != 999 }
+	defer func() { processAlive = prev }()
+
+	stateFile := filepath.Join(t.TempDir(), "base.json")
+	// a process killed while holding the lock, and using the base
+	if err := writeSharedState(stateFile, sharedState{PIDs: []int{999}, Kubeconfig: "/tmp/stale"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(stateFile+".lock", []byte("999"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var setups, finishes int32
+	base := New()
+	base.Setup(func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		atomic.AddInt32(&setups, 1)
+		return ctx, nil
+	}).Finish(func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		atomic.AddInt32(&finishes, 1)
+		return ctx, nil
+	})
+	child := Extend(base, WithSharedStateFile(stateFile), WithSharedStateLockTimeout(time.Second)).(*testEnv)
+	child.run(func() int { return 0 })
+
+	if setups != 1 || finishes != 1 {
+		t.Errorf("expected base setup and finish to run once, ran %d and %d times", setups, finishes)
+	}
+	if child.cfg.KubeconfigFile() == "/tmp/stale" {
+		t.Error("expected the kubeconfig of the dead process to be ignored")
+	}
+	if child.result.SetupError != nil || len(child.result.FinishErrors) != 0 {
+		t.Errorf("unexpected errors: %v %v", child.result.SetupError, child.result.FinishErrors)
+	}
+}
+
+func TestLockStateFile_Timeout(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "base.json")
+	// held by a live process, this one
+	if err := os.WriteFile(stateFile+".lock", []byte(strconv.Itoa(os.Getpid())), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lockStateFile(stateFile, 200*time.Millisecond); err == nil {
+		t.Fatal("expected the lock to time out")
+	}
+}
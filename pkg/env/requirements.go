@@ -0,0 +1,164 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package env
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
+
+	"sigs.k8s.io/e2e-framework/klient/nodes"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/internal/cluster"
+	"sigs.k8s.io/e2e-framework/pkg/internal/types"
+)
+
+// clusterInfo lazily discovers, and caches, the version, resources,
+// and nodes of the cluster to check feature requirements. The cache is
+// reset once the Setup funcs ran, since they may create the cluster or
+// install CRDs.
+type clusterInfo struct {
+	mu sync.Mutex
+	dc discovery.DiscoveryInterface
+	// version is the GitVersion of the API server, empty until discovered
+	version string
+	// resources are the resources served for each group version, nil when not served
+	resources map[string]*metav1.APIResourceList
+	// nodes are the nodes of the cluster, nil until listed
+	nodes []v1.Node
+}
+
+// reset clears the cached cluster details, so that they are discovered again
+func (c *clusterInfo) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dc = nil
+	c.version = ""
+	c.resources = nil
+	c.nodes = nil
+}
+
+func (c *clusterInfo) discovery(cfg *envconf.Config) (discovery.DiscoveryInterface, error) {
+	if c.dc != nil {
+		return c.dc, nil
+	}
+	client, err := cfg.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	dc, err := discovery.NewDiscoveryClientForConfig(client.RESTConfig())
+	if err != nil {
+		return nil, err
+	}
+	c.dc = dc
+	c.resources = make(map[string]*metav1.APIResourceList)
+	return c.dc, nil
+}
+
+// unmet returns a description of the first requirement not satisfied by the cluster,
+// or an empty string when all are. Returns an error if the cluster cannot be queried.
+func (c *clusterInfo) unmet(cfg *envconf.Config, req types.Requirements) (string, error) {
+	if req.MinKubeVersion == "" && len(req.APIResources) == 0 && len(req.NodeSelector) == 0 {
+		return "", nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	dc, err := c.discovery(cfg)
+	if err != nil {
+		return "", fmt.Errorf("cluster discovery: %w", err)
+	}
+
+	if req.MinKubeVersion != "" {
+		if c.version == "" {
+			info, err := dc.ServerVersion()
+			if err != nil {
+				return "", fmt.Errorf("cluster version: %w", err)
+			}
+			c.version = info.GitVersion
+		}
+		older, err := cluster.OlderThan(c.version, req.MinKubeVersion)
+		if err != nil {
+			return "", fmt.Errorf("cluster version: %w", err)
+		}
+		if older {
+			return fmt.Sprintf("cluster version %s is older than %s", c.version, req.MinKubeVersion), nil
+		}
+	}
+
+	for _, gvr := range req.APIResources {
+		gv := gvr.GroupVersion().String()
+		resources, ok := c.resources[gv]
+		if !ok {
+			resources, err = dc.ServerResourcesForGroupVersion(gv)
+			if apierrors.IsNotFound(err) {
+				resources = nil
+			} else if err != nil {
+				return "", fmt.Errorf("cluster resources: %w", err)
+			}
+			c.resources[gv] = resources
+		}
+		if resources == nil {
+			return fmt.Sprintf("API resource %s not served", gvr), nil
+		}
+		found := false
+		for _, r := range resources.APIResources {
+			if r.Name == gvr.Resource {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Sprintf("API resource %s not served", gvr), nil
+		}
+	}
+
+	if len(req.NodeSelector) > 0 {
+		if c.nodes == nil {
+			if c.nodes, err = listNodes(cfg); err != nil {
+				return "", fmt.Errorf("cluster nodes: %w", err)
+			}
+		}
+		if len(nodes.Matching(c.nodes, req.NodeSelector)) == 0 {
+			return fmt.Sprintf("no schedulable node matches %s", labels.SelectorFromSet(req.NodeSelector)), nil
+		}
+	}
+	return "", nil
+}
+
+func listNodes(cfg *envconf.Config) ([]v1.Node, error) {
+	client, err := cfg.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	cs, err := kubernetes.NewForConfig(client.RESTConfig())
+	if err != nil {
+		return nil, err
+	}
+	list, err := cs.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return append([]v1.Node{}, list.Items...), nil
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package env
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"sync"
+
+	"sigs.k8s.io/e2e-framework/pkg/internal/types"
+)
+
+// ActionError is the error returned by a Setup or Finish func of an environment
+type ActionError struct {
+	// Role is the kind of func that failed, "Setup" or "Finish"
+	Role string
+	// Index is the position of the func among the funcs of its role
+	// registered with the environment, starting at 0
+	Index int
+	// Name is the name of the func, as reported by the runtime
+	Name string
+	// Err is the error returned by the func
+	Err error
+}
+
+func (e *ActionError) Error() string {
+	return fmt.Sprintf("%s func #%d (%s): %s", e.Role, e.Index, e.Name, e.Err)
+}
+
+func (e *ActionError) Unwrap() error {
+	return e.Err
+}
+
+// RunResult is the outcome of running an environment with Environment.Run or RunTests
+type RunResult struct {
+	// ExitCode is the exit code returned by the run
+	ExitCode int
+	// SetupError is the error of the Setup func that failed, if any.
+	// The tests and the Finish funcs are not run when a Setup func fails.
+	SetupError *ActionError
+	// FinishErrors are the errors of the Finish funcs that failed, if any
+	FinishErrors []*ActionError
+	// BudgetSkipped are the tests of the features skipped because the run budget
+	// or the suite timeout was exceeded
+	BudgetSkipped []string
+	// OptionalFailures are the failures of the optional assessments, which did
+	// not fail their feature, in the order the assessments completed
+	OptionalFailures []OptionalFailure
+}
+
+// OptionalFailure is the failure of an optional assessment
+type OptionalFailure struct {
+	// Test is the name of the test of the assessment, i.e. TestFoo/feature/assessment
+	Test string
+	// Messages are the failures reported by the assessment
+	Messages []string
+}
+
+// Failed returns true if the tests or any Setup or Finish func failed
+func (r *RunResult) Failed() bool {
+	return r.ExitCode != 0 || r.SetupError != nil || len(r.FinishErrors) > 0
+}
+
+// Result returns the result of the last run of the environment, or nil if it was not run.
+// It lets TestMain act on specific failures, i.e. keep the cluster for debugging when a
+// Setup func failed. Panics if e was not created by the env package.
+func Result(e types.Environment) *RunResult {
+	te, ok := e.(*testEnv)
+	if !ok {
+		panic("env: Result requires an environment created by the env package")
+	}
+	return te.result
+}
+
+// optionalFailures collects the failures of the optional assessments of a run
+type optionalFailures struct {
+	mu       sync.Mutex
+	failures []OptionalFailure
+}
+
+func (o *optionalFailures) record(test string, messages []string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.failures = append(o.failures, OptionalFailure{Test: test, Messages: messages})
+}
+
+// reset clears the failures collected and returns them
+func (o *optionalFailures) reset() []OptionalFailure {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	failures := o.failures
+	o.failures = nil
+	return failures
+}
+
+// asActionError returns err as an ActionError, attributing it to role when the
+// error was not returned by an action
+func asActionError(role actionRole, err error) *ActionError {
+	var actionErr *ActionError
+	if errors.As(err, &actionErr) {
+		return actionErr
+	}
+	return &ActionError{Role: role.String(), Index: -1, Err: err}
+}
+
+// funcName returns the name of the func f
+func funcName(f interface{}) string {
+	if fn := runtime.FuncForPC(reflect.ValueOf(f).Pointer()); fn != nil {
+		return fn.Name()
+	}
+	return "unknown"
+}
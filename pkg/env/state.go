@@ -0,0 +1,133 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package env
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+)
+
+// runState records the features that passed in the state file of the
+// configuration, so that a resumed run can skip them. The file is shared by
+// the test packages, each run as its own process by go test: a line holds the
+// scope of the package, its directory, and the name of the test, separated by
+// a tab. The file is guarded by the same lock file as the shared state of
+// Extend while it is written.
+type runState struct {
+	once  sync.Once
+	err   error
+	scope string
+
+	mu     sync.Mutex
+	passed map[string]bool
+}
+
+// packageScope returns the scope of the test package, the working directory
+// go test runs its binary from
+var packageScope = os.Getwd
+
+// load reads the features of the package that passed from the state file when
+// resuming a run, otherwise it removes the entries of the package from the state
+// file to record a new run. Only the first call has an effect.
+func (s *runState) load(cfg *envconf.Config) error {
+	s.once.Do(func() {
+		s.passed = make(map[string]bool)
+		path := cfg.StateFile()
+		if path == "" {
+			return
+		}
+		if s.scope, s.err = packageScope(); s.err != nil {
+			s.err = fmt.Errorf("run state: %w", s.err)
+			return
+		}
+		unlock, err := lockStateFile(path, DefaultSharedStateLockTimeout)
+		if err != nil {
+			s.err = fmt.Errorf("run state: %w", err)
+			return
+		}
+		defer unlock()
+
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return
+		} else if err != nil {
+			s.err = fmt.Errorf("run state: %w", err)
+			return
+		}
+		var others []string
+		for _, line := range strings.Split(string(data), "\n") {
+			if line == "" {
+				continue
+			}
+			if name := strings.TrimPrefix(line, s.scope+"\t"); name != line {
+				s.passed[name] = true
+			} else {
+				others = append(others, line)
+			}
+		}
+		if !cfg.Resume() {
+			s.passed = make(map[string]bool)
+			var content string
+			if len(others) > 0 {
+				content = strings.Join(others, "\n") + "\n"
+			}
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				s.err = fmt.Errorf("run state: %w", err)
+			}
+		}
+	})
+	return s.err
+}
+
+// hasPassed reports whether the test passed in the run being resumed
+func (s *runState) hasPassed(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.passed[name]
+}
+
+// recordPassed appends the name of the test to the state file right away,
+// so that the progress is kept even if the run is interrupted.
+func (s *runState) recordPassed(cfg *envconf.Config, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path := cfg.StateFile()
+	if path == "" || s.passed[name] {
+		return nil
+	}
+	unlock, err := lockStateFile(path, DefaultSharedStateLockTimeout)
+	if err != nil {
+		return fmt.Errorf("run state: %w", err)
+	}
+	defer unlock()
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("run state: %w", err)
+	}
+	defer file.Close()
+	if _, err := fmt.Fprintf(file, "%s\t%s\n", s.scope, name); err != nil {
+		return fmt.Errorf("run state: %w", err)
+	}
+	s.passed[name] = true
+	return nil
+}
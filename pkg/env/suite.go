@@ -0,0 +1,153 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package env
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"sigs.k8s.io/e2e-framework/pkg/features"
+	"sigs.k8s.io/e2e-framework/pkg/internal/types"
+	"sigs.k8s.io/e2e-framework/pkg/tracing"
+)
+
+type suiteResultsContextKey struct{}
+
+// suiteResults counts the outcomes of the features of a suite
+type suiteResults struct {
+	passed, failed, skipped int32
+}
+
+// suiteResultsFrom returns the results of the suite being tested, if any
+func suiteResultsFrom(ctx context.Context) *suiteResults {
+	results, _ := ctx.Value(suiteResultsContextKey{}).(*suiteResults)
+	return results
+}
+
+// record counts the outcome of the feature tested with t
+func (r *suiteResults) record(t *testing.T) {
+	if r == nil {
+		return
+	}
+	switch {
+	case t.Failed():
+		atomic.AddInt32(&r.failed, 1)
+	case t.Skipped():
+		atomic.AddInt32(&r.skipped, 1)
+	default:
+		atomic.AddInt32(&r.passed, 1)
+	}
+}
+
+// TestSuite executes suites of features from within a TestXXX function. Each suite
+// is a subtest of t, running the setup steps of the suite, then its features as
+// Test does, then its teardown steps, even when a setup step or a feature failed.
+// The features are tested with the labels of the suite, unless they set the same
+// label themselves, so that the label filters apply to whole suites.
+//
+// A summary of the passed, failed, and skipped features is logged for each suite.
+func (e *testEnv) TestSuite(t *testing.T, suites ...types.Suite) {
+	e.panicOnMissingContext()
+	for i, suite := range suites {
+		name := suite.Name()
+		if name == "" {
+			name = fmt.Sprintf("Suite-%d", i+1)
+		}
+		t.Run(name, func(t *testing.T) {
+			e.execSuite(t, name, suite)
+		})
+	}
+}
+
+func (e *testEnv) execSuite(t *testing.T, name string, suite types.Suite) {
+	logger := e.cfg.Logger().WithValues("suite", name)
+	logger.V(1).Info("Starting suite")
+	start := time.Now()
+
+	results := &suiteResults{}
+	ctx, span, endSpan := e.startSpan(e.ctx, name, tracing.SuiteKey.String(name), tracing.TestKey.String(t.Name()))
+	e.ctx = context.WithValue(ctx, suiteResultsContextKey{}, results)
+	defer func() {
+		passed, failed, skipped := atomic.LoadInt32(&results.passed), atomic.LoadInt32(&results.failed), atomic.LoadInt32(&results.skipped)
+		span.SetAttributes(attribute.Int("e2e.features.passed", int(passed)), attribute.Int("e2e.features.failed", int(failed)), attribute.Int("e2e.features.skipped", int(skipped)))
+		setTestStatus(span, t)
+		e.ctx = endSpan(context.WithValue(e.ctx, suiteResultsContextKey{}, (*suiteResults)(nil)))
+
+		t.Logf(`Suite "%s": %d passed, %d failed, %d skipped features`, name, passed, failed, skipped)
+		logger.V(1).Info("Finished suite", "duration", time.Since(start), "passed", passed, "failed", failed, "skipped", skipped)
+	}()
+
+	// teardowns run even when a setup stops the suite, unless the
+	// resources of a failed run are kept for debugging
+	defer func() {
+		if t.Failed() {
+			e.failures.record()
+		}
+		if e.skipTeardown() {
+			t.Logf(`Skipping teardown of suite "%s": resources kept on failure, namespace %q`, name, e.cfg.Namespace())
+			return
+		}
+		e.ctx = detach(e.ctx)
+		for _, teardown := range features.GetStepsByLevel(suite.Steps(), types.LevelTeardown) {
+			e.ctx = e.runStep(e.ctx, t, teardown)
+		}
+	}()
+
+	for _, setup := range features.GetStepsByLevel(suite.Steps(), types.LevelSetup) {
+		e.ctx = e.runStep(e.ctx, t, setup)
+		if t.Failed() {
+			t.Fatalf(`Suite "%s": setup %q failed, skipping the features of the suite`, name, setup.Name())
+		}
+	}
+
+	testFeatures := make([]types.Feature, 0, len(suite.Features()))
+	for _, f := range suite.Features() {
+		testFeatures = append(testFeatures, withSuiteLabels(f, suite.Labels()))
+	}
+	e.processTests(t, false, testFeatures...)
+}
+
+// suiteFeature is a feature of a suite, with the labels of the suite
+type suiteFeature struct {
+	types.Feature
+	labels types.Labels
+}
+
+// withSuiteLabels returns the feature with the suite labels it does not set itself
+func withSuiteLabels(f types.Feature, suiteLabels types.Labels) types.Feature {
+	if len(suiteLabels) == 0 {
+		return f
+	}
+	labels := make(types.Labels, len(suiteLabels)+len(f.Labels()))
+	for k, v := range suiteLabels {
+		labels[k] = v
+	}
+	for k, v := range f.Labels() {
+		labels[k] = v
+	}
+	return &suiteFeature{Feature: f, labels: labels}
+}
+
+func (f *suiteFeature) Labels() types.Labels             { return f.labels }
+func (f *suiteFeature) Parallel() bool                   { return features.IsParallel(f.Feature) }
+func (f *suiteFeature) Requirements() types.Requirements { return features.GetRequirements(f.Feature) }
+func (f *suiteFeature) RateLimit() types.RateLimit       { return features.GetRateLimit(f.Feature) }
@@ -0,0 +1,74 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package env
+
+import (
+	"context"
+	"sync/atomic"
+
+	log "k8s.io/klog/v2"
+)
+
+// runFailures tracks whether a feature of the run failed, to skip the
+// teardowns when the configuration keeps the resources on failure
+type runFailures struct {
+	failed int32
+}
+
+// record marks the run as failed
+func (f *runFailures) record() {
+	atomic.StoreInt32(&f.failed, 1)
+}
+
+// any returns true if a feature of the run failed
+func (f *runFailures) any() bool {
+	return atomic.LoadInt32(&f.failed) == 1
+}
+
+// skipTeardown returns true if the teardowns are to be skipped, the run having
+// failed with a configuration keeping the resources on failure
+func (e *testEnv) skipTeardown() bool {
+	if !e.cfg.SkipTeardownOnFailure() {
+		return false
+	}
+	return e.failures.any() || (e.result != nil && e.result.ExitCode != 0)
+}
+
+type teardownSkippedKey struct{}
+
+// TeardownSkipped reports whether the teardowns are skipped, the run having failed with a
+// configuration keeping the resources on failure, see envconf.Config.WithSkipTeardownOnFailure.
+// The AfterEachFeature and AfterEachTest funcs still run, i.e. to collect logs, and the ones
+// deleting resources, such as envfuncs.RunFeatureCleanup, must leave them in place when true.
+func TeardownSkipped(ctx context.Context) bool {
+	skipped, _ := ctx.Value(teardownSkippedKey{}).(bool)
+	return skipped
+}
+
+// withTeardownState marks the context when the teardowns are skipped, see TeardownSkipped
+func (e *testEnv) withTeardownState(ctx context.Context) context.Context {
+	if e.skipTeardown() && !TeardownSkipped(ctx) {
+		return context.WithValue(ctx, teardownSkippedKey{}, true)
+	}
+	return ctx
+}
+
+// logPreserved logs the resources left in place by skipping the teardown described by what
+func (e *testEnv) logPreserved(what string) {
+	log.Infof("Skipping %s after a test failure, preserving the cluster for debugging: kubeconfig %q, namespace %q, clusters %v",
+		what, e.cfg.KubeconfigFile(), e.cfg.Namespace(), e.cfg.ClusterNames())
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package env
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"sigs.k8s.io/e2e-framework/pkg/tracing"
+)
+
+// startSpan starts a span, child of the span in ctx if any. The returned func ends the
+// span, and returns its context argument with the parent span restored, so that the
+// spans started afterwards are not nested in the ended span.
+func (e *testEnv) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span, func(context.Context) context.Context) {
+	parent := trace.SpanFromContext(ctx)
+	ctx, span := e.cfg.Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+	return ctx, span, func(ctx context.Context) context.Context {
+		span.End()
+		return trace.ContextWithSpan(ctx, parent)
+	}
+}
+
+// setTestStatus records whether the test of the span passed, failed, or was skipped
+func setTestStatus(span trace.Span, t *testing.T) {
+	switch {
+	case t.Failed():
+		span.SetAttributes(tracing.StatusKey.String("failed"))
+		span.SetStatus(codes.Error, "test failed")
+	case t.Skipped():
+		span.SetAttributes(tracing.StatusKey.String("skipped"))
+	default:
+		span.SetAttributes(tracing.StatusKey.String("passed"))
+	}
+}
+
+// setErrorStatus records err, if any, on the span
+func setErrorStatus(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
@@ -17,20 +17,34 @@ limitations under the License.
 package envconf
 
 import (
-	"encoding/hex"
+	"errors"
 	"fmt"
-	"math/rand"
+	"io"
+	"os"
+	"path/filepath"
 	"regexp"
+	"sort"
+	"sync"
 	"time"
 
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/flowcontrol"
 	log "k8s.io/klog/v2"
 
 	"sigs.k8s.io/e2e-framework/klient"
+	"sigs.k8s.io/e2e-framework/klient/apicalls"
+	"sigs.k8s.io/e2e-framework/klient/conf"
 	"sigs.k8s.io/e2e-framework/pkg/flags"
+	"sigs.k8s.io/e2e-framework/pkg/labels"
+	"sigs.k8s.io/e2e-framework/pkg/redact"
 )
 
-// Config represents and environment configuration
+// Config represents and environment configuration. It is safe for concurrent
+// use, so that parallel features can read and update it from their goroutines.
 type Config struct {
+	mu                  sync.RWMutex
 	client              klient.Client
 	kubeconfig          string
 	namespace           string
@@ -41,6 +55,35 @@ type Config struct {
 	skipLabels          map[string]string
 	skipAssessmentRegex *regexp.Regexp
 	parallelTests       bool
+	clusters            map[string]*cluster
+	artifactsDir        string
+	runBudget           time.Duration
+	suiteTimeout        time.Duration
+	rateLimiter         flowcontrol.RateLimiter
+	redactor            *redact.Redactor
+	redactorOnce        sync.Once
+	verbosity           int
+	stateFile           string
+	resume              bool
+	traceEndpoint       string
+	tracerProvider      trace.TracerProvider
+	endpointProvider    *sdktrace.TracerProvider
+	flags               map[string]string
+	labelFilter         *labels.Filter
+	skipTeardown        bool
+	names               nameGenerator
+	runID               string
+	provider            string
+	providerSettings    map[string]string
+	clientOptions       []klient.Option
+	apiCalls            *apicalls.Counter
+}
+
+// cluster stores the connection details of an additional,
+// named cluster registered with the environment configuration.
+type cluster struct {
+	restConfig *rest.Config
+	client     klient.Client
 }
 
 // New creates and initializes an empty environment configuration
@@ -56,45 +99,130 @@ func NewWithKubeConfig(kubeconfig string) *Config {
 
 // NewFromFlags initializes an environment config using flag values
 // parsed from command-line arguments and returns an error on parsing failure.
+// When the --config flag is set, the config is first loaded from the file,
+// see NewFromFile, and the flags that are set override its values.
 func NewFromFlags() (*Config, error) {
 	envFlags, err := flags.Parse()
 	if err != nil {
 		log.Fatalf("flags parse failed: %s", err)
 	}
 	e := New()
-	if envFlags.Assessment() != "" {
-		e.assessmentRegex = regexp.MustCompile(envFlags.Assessment())
+	if envFlags.Config() != "" {
+		if e, err = NewFromFile(envFlags.Config()); err != nil {
+			return nil, err
+		}
+	}
+	if e.flags == nil {
+		e.flags = make(map[string]string)
+	}
+	// only the flags set on the command line are applied, so that they override the
+	// values of the file, even when set to their default value, i.e. --parallel=false
+	var visitErr error
+	envFlags.Visit(func(name string) {
+		switch name {
+		case "assess":
+			e.assessmentRegex = regexp.MustCompile(envFlags.Assessment())
+		case "feature":
+			e.featureRegex = regexp.MustCompile(envFlags.Feature())
+		case "labels":
+			e.labels = envFlags.Labels()
+		case "namespace":
+			e.namespace = envFlags.Namespace()
+		case "kubeconfig":
+			e.kubeconfig = envFlags.Kubeconfig()
+		case "skip-features":
+			e.skipFeatureRegex = regexp.MustCompile(envFlags.SkipFeatures())
+		case "skip-assessment":
+			e.skipAssessmentRegex = regexp.MustCompile(envFlags.SkipAssessment())
+		case "skip-labels":
+			e.skipLabels = envFlags.SkipLabels()
+		case "label-filter":
+			filter, err := labels.ParseFilter(envFlags.LabelFilter())
+			if err != nil {
+				visitErr = fmt.Errorf("envconfig: %w", err)
+				return
+			}
+			e.labelFilter = filter
+		case "parallel":
+			e.parallelTests = envFlags.Parallel()
+		case "artifacts":
+			e.artifactsDir = envFlags.Artifacts()
+		case "state-file":
+			e.stateFile = envFlags.StateFile()
+		case "resume":
+			e.resume = envFlags.Resume()
+		case "skip-teardown-on-failure":
+			e.skipTeardown = envFlags.SkipTeardownOnFailure()
+		case "random-seed":
+			e.names.seed(envFlags.RandomSeed())
+		case "trace-endpoint":
+			e.traceEndpoint = envFlags.TraceEndpoint()
+		default:
+			if value, ok := envFlags.Custom(name); ok {
+				e.flags[name] = value
+			}
+		}
+	})
+	if visitErr != nil {
+		return nil, visitErr
 	}
-	if envFlags.Feature() != "" {
-		e.featureRegex = regexp.MustCompile(envFlags.Feature())
+	if e.labels == nil {
+		e.labels = envFlags.Labels()
 	}
-	e.labels = envFlags.Labels()
-	e.namespace = envFlags.Namespace()
-	e.kubeconfig = envFlags.Kubeconfig()
-	if envFlags.SkipFeatures() != "" {
-		e.skipFeatureRegex = regexp.MustCompile(envFlags.SkipFeatures())
+	if e.skipLabels == nil {
+		e.skipLabels = envFlags.SkipLabels()
 	}
-	if envFlags.SkipAssessment() != "" {
-		e.skipAssessmentRegex = regexp.MustCompile(envFlags.SkipAssessment())
+	e.verbosity = envFlags.Verbosity()
+	// the custom flags neither set nor in the file have their default value
+	for name, value := range envFlags.CustomFlags() {
+		if _, ok := e.flags[name]; !ok {
+			e.flags[name] = value
+		}
 	}
-	e.skipLabels = envFlags.SkipLabels()
-	e.parallelTests = envFlags.Parallel()
 
 	return e, nil
 }
 
 // WithKubeconfigFile creates a new klient.Client and injects it in the cfg
 func (c *Config) WithKubeconfigFile(kubecfg string) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.kubeconfig = kubecfg
 	return c
 }
 
 func (c *Config) KubeconfigFile() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.kubeconfig
 }
 
+// WithInClusterDetection resolves the kubeconfig file, when not set, from $KUBECONFIG or
+// $HOME/.kube/config unless the process runs inside a cluster, in which case the in-cluster
+// configuration (service account token) is used. This allows the same test suite to run
+// both from a workstation and as a Job (see the support/incluster package).
+func (c *Config) WithInClusterDetection() *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.kubeconfig == "" && !conf.IsInCluster() {
+		c.kubeconfig = conf.ResolveKubeConfigFile()
+	}
+	return c
+}
+
+// InCluster reports whether the environment is configured to use the
+// in-cluster configuration (service account token) to reach the API server.
+// This is the case when no kubeconfig file is set and the process runs in a pod.
+func (c *Config) InCluster() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.kubeconfig == "" && conf.IsInCluster()
+}
+
 // WithClient used to update the environment klient.Client
 func (c *Config) WithClient(client klient.Client) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.client = client
 	return c
 }
@@ -103,15 +231,17 @@ func (c *Config) WithClient(client klient.Client) *Config {
 // created klient.Client or create a new one based on configuration
 // previously set. Will return an error if unable to do so.
 func (c *Config) NewClient() (klient.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.client != nil {
 		return c.client, nil
 	}
 
-	client, err := klient.NewWithKubeConfigFile(c.kubeconfig)
+	client, err := klient.NewWithKubeConfigFile(c.kubeconfig, c.allClientOptions()...)
 	if err != nil {
 		return nil, fmt.Errorf("envconfig: client failed: %w", err)
 	}
-	c.client = client
+	c.client = c.rateLimit(client)
 	return c.client, nil
 }
 
@@ -121,20 +251,87 @@ func (c *Config) NewClient() (klient.Client, error) {
 // are confident in the configuration or call NewClient() to ensure its
 // safe creation.
 func (c *Config) Client() klient.Client {
-	if c.client != nil {
-		return c.client
+	client, err := c.NewClient()
+	if err != nil {
+		panic(err.Error())
+	}
+	return client
+}
+
+// WithCluster registers an additional named cluster, reachable with restCfg,
+// with the environment configuration. The client for the cluster is created
+// lazily when first requested with NewClusterClient or ClusterClient.
+func (c *Config) WithCluster(name string, restCfg *rest.Config) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.clusters == nil {
+		c.clusters = make(map[string]*cluster)
+	}
+	c.clusters[name] = &cluster{restConfig: restCfg}
+	return c
+}
+
+// WithClusterClient registers an additional named cluster using a
+// previously created klient.Client.
+func (c *Config) WithClusterClient(name string, client klient.Client) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.clusters == nil {
+		c.clusters = make(map[string]*cluster)
+	}
+	c.clusters[name] = &cluster{restConfig: client.RESTConfig(), client: client}
+	return c
+}
+
+// ClusterNames returns the sorted names of the clusters registered
+// with WithCluster or WithClusterClient.
+func (c *Config) ClusterNames() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	names := make([]string, 0, len(c.clusters))
+	for name := range c.clusters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewClusterClient returns a previously created klient.Client for the named
+// cluster or creates a new one from its registered *rest.Config. Will return
+// an error if the cluster is unknown or the client cannot be created.
+func (c *Config) NewClusterClient(name string) (klient.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cl, ok := c.clusters[name]
+	if !ok {
+		return nil, fmt.Errorf("envconfig: cluster %q not registered", name)
+	}
+	if cl.client != nil {
+		return cl.client, nil
 	}
 
-	client, err := klient.NewWithKubeConfigFile(c.kubeconfig)
+	client, err := klient.New(cl.restConfig, c.allClientOptions()...)
 	if err != nil {
-		panic(fmt.Errorf("envconfig: client failed: %w", err).Error())
+		return nil, fmt.Errorf("envconfig: cluster %q client failed: %w", name, err)
 	}
-	c.client = client
-	return c.client
+	cl.client = c.rateLimit(client)
+	return cl.client, nil
+}
+
+// ClusterClient returns the klient.Client for the named cluster and
+// will panic on any error. Call NewClusterClient to handle errors.
+func (c *Config) ClusterClient(name string) klient.Client {
+	client, err := c.NewClusterClient(name)
+	if err != nil {
+		panic(err.Error())
+	}
+	return client
 }
 
 // WithNamespace updates the environment namespace value
 func (c *Config) WithNamespace(ns string) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.namespace = ns
 	return c
 }
@@ -142,105 +339,457 @@ func (c *Config) WithNamespace(ns string) *Config {
 // WithRandomNamespace sets the environment's namespace
 // to a random value
 func (c *Config) WithRandomNamespace() *Config {
-	c.namespace = randNS()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.namespace = c.NewNamespaceName()
 	return c
 }
 
 // Namespace returns the namespace for the environment
 func (c *Config) Namespace() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.namespace
 }
 
 // WithAssessmentRegex sets the environment assessment regex filter
 func (c *Config) WithAssessmentRegex(regex string) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.assessmentRegex = regexp.MustCompile(regex)
 	return c
 }
 
 // AssessmentRegex returns the environment assessment filter
 func (c *Config) AssessmentRegex() *regexp.Regexp {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.assessmentRegex
 }
 
 // WithSkipAssessmentRegex sets the environment assessment regex filter
 func (c *Config) WithSkipAssessmentRegex(regex string) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.skipAssessmentRegex = regexp.MustCompile(regex)
 	return c
 }
 
 // SkipAssessmentRegex returns the environment assessment filter
 func (c *Config) SkipAssessmentRegex() *regexp.Regexp {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.skipAssessmentRegex
 }
 
 // WithFeatureRegex sets the environment's feature regex filter
 func (c *Config) WithFeatureRegex(regex string) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.featureRegex = regexp.MustCompile(regex)
 	return c
 }
 
 // FeatureRegex returns the environment's feature regex filter
 func (c *Config) FeatureRegex() *regexp.Regexp {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.featureRegex
 }
 
 // WithSkipFeatureRegex sets the environment's skip feature regex filter
 func (c *Config) WithSkipFeatureRegex(regex string) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.skipFeatureRegex = regexp.MustCompile(regex)
 	return c
 }
 
 // SkipFeatureRegex returns the environment's skipfeature regex filter
 func (c *Config) SkipFeatureRegex() *regexp.Regexp {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.skipFeatureRegex
 }
 
 // WithLabels sets the environment label filters
 func (c *Config) WithLabels(lbls map[string]string) *Config {
-	c.labels = lbls
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.labels = copyLabels(lbls)
+	return c
+}
+
+// WithLabel adds, or replaces, a single environment label filter
+func (c *Config) WithLabel(key, value string) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.labels == nil {
+		c.labels = make(map[string]string)
+	}
+	c.labels[key] = value
 	return c
 }
 
-// Labels returns the environment's label filters
+// Labels returns a copy of the environment's label filters
 func (c *Config) Labels() map[string]string {
-	return c.labels
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return copyLabels(c.labels)
 }
 
 // WithSkipLabels sets the environment label filters
 func (c *Config) WithSkipLabels(lbls map[string]string) *Config {
-	c.skipLabels = lbls
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.skipLabels = copyLabels(lbls)
 	return c
 }
 
-// SkipLabels returns the environment's label filters
+// SkipLabels returns a copy of the environment's label filters
 func (c *Config) SkipLabels() map[string]string {
-	return c.skipLabels
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return copyLabels(c.skipLabels)
+}
+
+// copyLabels copies the labels so that callers cannot mutate
+// the maps of the configuration without holding its lock
+func copyLabels(lbls map[string]string) map[string]string {
+	if lbls == nil {
+		return nil
+	}
+	cp := make(map[string]string, len(lbls))
+	for k, v := range lbls {
+		cp[k] = v
+	}
+	return cp
+}
+
+// WithLabelFilter sets a boolean expression, i.e. "conformance && !slow || gpu", selecting
+// the features by their labels. See labels.ParseFilter for the syntax. It panics if the
+// expression cannot be parsed.
+func (c *Config) WithLabelFilter(expr string) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.labelFilter = labels.MustParseFilter(expr)
+	return c
+}
+
+// LabelFilter returns the environment's label filter expression, if any
+func (c *Config) LabelFilter() *labels.Filter {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.labelFilter
 }
 
 func (c *Config) WithParallelTestEnabled() *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.parallelTests = true
 	return c
 }
 
 func (c *Config) ParallelTestEnabled() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.parallelTests
 }
 
-func randNS() string {
-	return RandomName("testns-", 32)
+// WithArtifactsDir sets the directory where test artifacts
+// (logs, resource dumps, reports) are written
+func (c *Config) WithArtifactsDir(dir string) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.artifactsDir = dir
+	return c
+}
+
+// ArtifactsDir returns the directory where test artifacts are written.
+// When not set, a directory named e2e-artifacts in the OS temp directory is used.
+func (c *Config) ArtifactsDir() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.artifactsDir == "" {
+		return filepath.Join(os.TempDir(), "e2e-artifacts")
+	}
+	return c.artifactsDir
+}
+
+// ArtifactPath creates and returns a new subdirectory of the artifacts directory
+// for the given name, typically the name of the running test (t.Name()). Path
+// separators in the name are preserved, so subtests are nested under their parent.
+// When the directory already exists, a numeric suffix is added so that each call
+// returns a unique directory.
+func (c *Config) ArtifactPath(name string) (string, error) {
+	base := filepath.Join(c.ArtifactsDir(), sanitizeArtifactName(name))
+	if err := os.MkdirAll(filepath.Dir(base), 0o755); err != nil {
+		return "", fmt.Errorf("envconfig: artifact path: %w", err)
+	}
+	path := base
+	for i := 1; ; i++ {
+		err := os.Mkdir(path, 0o755)
+		if err == nil {
+			return path, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return "", fmt.Errorf("envconfig: artifact path: %w", err)
+		}
+		path = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+// CreateArtifact creates, or truncates, the file at path, typically within a directory returned
+// by ArtifactPath, to write an artifact. The secrets registered with WithSecrets are masked in
+// the written data, the returned writer must be closed to write all the data.
+func (c *Config) CreateArtifact(path string) (io.WriteCloser, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("envconfig: create artifact: %w", err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("envconfig: create artifact: %w", err)
+	}
+	return c.Redactor().NewWriter(file), nil
+}
+
+var artifactNameRegex = regexp.MustCompile(`[^a-zA-Z0-9_./-]+`)
+
+// sanitizeArtifactName replaces characters that are not safe in a
+// file name and prevents the name from escaping the artifacts directory
+func sanitizeArtifactName(name string) string {
+	name = artifactNameRegex.ReplaceAllString(name, "_")
+	name = filepath.Clean("/" + name)[1:]
+	if name == "" {
+		return "artifacts"
+	}
+	return name
+}
+
+// WithRunBudget sets the maximum duration of the test run. Once the budget is
+// about to be exceeded, the remaining features are skipped (and reported as
+// budget-skipped) so that the Finish funcs can run, and artifacts and reports
+// get written, before the CI job is killed. A zero budget means no limit.
+func (c *Config) WithRunBudget(budget time.Duration) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.runBudget = budget
+	return c
+}
+
+// RunBudget returns the maximum duration of the test run
+func (c *Config) RunBudget() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.runBudget
+}
+
+// WithSuiteTimeout sets a hard limit on the duration of the test run, measured from the start of
+// Environment.Run. Once exceeded, the context of the running features is cancelled, the remaining
+// features are skipped (and reported as budget-skipped), and the Finish funcs run with a context
+// that is not cancelled. It should be shorter than the timeout of go test, which kills the tests
+// without running the Finish funcs. A zero timeout means no limit.
+func (c *Config) WithSuiteTimeout(timeout time.Duration) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.suiteTimeout = timeout
+	return c
+}
+
+// SuiteTimeout returns the maximum duration of the test run enforced by Environment.Run
+func (c *Config) SuiteTimeout() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.suiteTimeout
+}
+
+// WithStateFile sets the file where the environment records the features that passed,
+// so that a long running suite can be resumed with WithResume after an interruption.
+// The file can be shared by the test packages, the features being recorded per package.
+// Unless resuming, the features of the package are removed from the file when its first
+// feature is tested.
+func (c *Config) WithStateFile(path string) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stateFile = path
+	return c
+}
+
+// StateFile returns the file recording the features that passed
+func (c *Config) StateFile() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.stateFile
+}
+
+// WithResume sets whether to resume the run recorded in the state file, skipping
+// the features that already passed against the same long-lived environment.
+func (c *Config) WithResume(resume bool) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.resume = resume
+	return c
+}
+
+// Resume returns whether the run recorded in the state file is resumed
+func (c *Config) Resume() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.resume
+}
+
+// WithSkipTeardownOnFailure skips the feature teardowns, and the Finish funcs, once
+// a test failed, leaving the cluster and namespaces intact for interactive debugging.
+func (c *Config) WithSkipTeardownOnFailure() *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.skipTeardown = true
+	return c
+}
+
+// SkipTeardownOnFailure returns whether the teardowns are skipped once a test failed
+func (c *Config) SkipTeardownOnFailure() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.skipTeardown
 }
 
-// RandomName generates a random name of n length with the provided
-// prefix. If prefix is omitted, the then entire name is random char.
-func RandomName(prefix string, n int) string {
-	if n == 0 {
-		n = 32
+// WithRateLimit paces the create, apply, and delete operations of the clients
+// created by the configuration to qps operations per second, allowing bursts of
+// burst operations. Features can override the limit with FeatureBuilder.WithRateLimit.
+func (c *Config) WithRateLimit(qps float32, burst int) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rateLimiter = flowcontrol.NewTokenBucketRateLimiter(qps, burst)
+	if c.client != nil {
+		c.rateLimit(c.client)
 	}
-	if len(prefix) >= n {
-		return prefix
+	return c
+}
+
+// RateLimiter returns the rate limiter set with WithRateLimit, if any
+func (c *Config) RateLimiter() flowcontrol.RateLimiter {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.rateLimiter
+}
+
+// rateLimit must be called with the lock held
+func (c *Config) rateLimit(client klient.Client) klient.Client {
+	if c.rateLimiter != nil {
+		client.Resources().WithRateLimiter(c.rateLimiter)
 	}
-	rand.Seed(time.Now().UnixNano())
-	p := make([]byte, n)
-	rand.Read(p)
-	return fmt.Sprintf("%s-%s", prefix, hex.EncodeToString(p))[:n]
+	return client
+}
+
+// WithClientOptions tunes the clients created by the configuration afterwards, i.e. with
+// klient.WithQPS to raise the client-side rate limit of client-go in large parallel suites,
+// or klient.WithTimeout to bound the duration of the requests
+func (c *Config) WithClientOptions(opts ...klient.Option) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.clientOptions = append(c.clientOptions, opts...)
+	return c
+}
+
+// WithAPICallCounting counts the API calls of the clients created by the configuration
+// afterwards. The environment counts the calls of each feature under the name of its test,
+// logs them when the feature completes, and the counter returned by APICalls reports them.
+func (c *Config) WithAPICallCounting() *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.apiCalls == nil {
+		c.apiCalls = apicalls.NewCounter()
+	}
+	return c
+}
+
+// APICalls returns the counter of the API calls enabled with WithAPICallCounting, if any
+func (c *Config) APICalls() *apicalls.Counter {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.apiCalls
+}
+
+// allClientOptions must be called with the lock held
+func (c *Config) allClientOptions() []klient.Option {
+	opts := append([]klient.Option(nil), c.clientOptions...)
+	if c.apiCalls != nil {
+		opts = append(opts, klient.WithTransportWrapper(c.apiCalls.Wrap))
+	}
+	return opts
+}
+
+// logRedactor masks the secrets of all the configurations in the klog output. The klog
+// filter is global to the process, so the secrets are registered on a single redactor
+// rather than each configuration installing its own.
+var logRedactor = redact.New()
+
+// WithSecrets registers secrets, such as tokens and passwords, to mask in the framework
+// logs and the artifacts created with CreateArtifact. The secrets are also registered on
+// the process-wide redactor installed as the klog filter, so that they are masked in the
+// logs of the other packages, along with the secrets of the other configurations.
+func (c *Config) WithSecrets(secrets ...string) *Config {
+	c.Redactor().Add(secrets...)
+	logRedactor.Add(secrets...)
+	log.SetLogFilter(logRedactor)
+	return c
+}
+
+// Redactor returns the redactor masking the secrets registered with WithSecrets,
+// i.e. to mask the secrets in captured command output with Redactor().NewWriter
+func (c *Config) Redactor() *redact.Redactor {
+	c.redactorOnce.Do(func() { c.redactor = redact.New() })
+	return c.redactor
+}
+
+// Redact returns s with the secrets registered with WithSecrets masked
+func (c *Config) Redact(s string) string {
+	return c.Redactor().String(s)
+}
+
+// WithFlag sets the value of a custom flag, i.e. to configure the
+// environment without parsing the command-line arguments
+func (c *Config) WithFlag(name, value string) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.flags == nil {
+		c.flags = make(map[string]string)
+	}
+	c.flags[name] = value
+	return c
+}
+
+// Flag returns the value of a custom flag declared with flags.Define, or set
+// with WithFlag or in the flags section of the configuration file. An empty
+// string is returned for an unknown flag.
+func (c *Config) Flag(name string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.flags[name]
+}
+
+// WithProvider sets the name and the settings of the provider of the test cluster,
+// i.e. kind, for the env funcs creating the cluster from the configuration
+func (c *Config) WithProvider(name string, settings map[string]string) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.provider = name
+	c.providerSettings = copyLabels(settings)
+	return c
+}
+
+// Provider returns the name of the provider of the test cluster, if any
+func (c *Config) Provider() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.provider
+}
+
+// ProviderSetting returns the value of a setting of the provider of the test cluster.
+// An empty string is returned for an unknown setting.
+func (c *Config) ProviderSetting(key string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.providerSettings[key]
 }
@@ -17,8 +17,21 @@ limitations under the License.
 package envconf
 
 import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"k8s.io/client-go/rest"
+	log "k8s.io/klog/v2"
+	"sigs.k8s.io/e2e-framework/klient"
+	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
 )
 
 func TestConfig_New(t *testing.T) {
@@ -47,3 +60,203 @@ func TestConfig_New_WithParallel(t *testing.T) {
 		t.Error("expected parallel test to be enabled when -parallel argument is provided")
 	}
 }
+
+type fakeClient struct {
+	cfg *rest.Config
+}
+
+func (f *fakeClient) RESTConfig() *rest.Config { return f.cfg }
+
+func (f *fakeClient) Resources(...string) *resources.Resources { return nil }
+
+func TestConfig_WithClusters(t *testing.T) {
+	target := &fakeClient{cfg: &rest.Config{Host: "https://target:6443"}}
+	cfg := New().
+		WithClusterClient("target", target).
+		WithCluster("mgmt", &rest.Config{Host: "https://mgmt:6443"})
+
+	names := cfg.ClusterNames()
+	if len(names) != 2 || names[0] != "mgmt" || names[1] != "target" {
+		t.Fatalf("unexpected cluster names: %v", names)
+	}
+
+	client, err := cfg.NewClusterClient("target")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client != target {
+		t.Error("expected registered client to be returned")
+	}
+
+	if _, err := cfg.NewClusterClient("unknown"); err == nil {
+		t.Error("expected error for unregistered cluster")
+	}
+}
+
+func TestConfig_WithClientOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api":
+			fmt.Fprint(w, `{"kind": "APIVersions", "versions": ["v1"]}`)
+		case "/apis":
+			fmt.Fprint(w, `{"kind": "APIGroupList", "groups": []}`)
+		default:
+			fmt.Fprint(w, `{"kind": "APIResourceList", "groupVersion": "v1", "resources": []}`)
+		}
+	}))
+	defer server.Close()
+
+	cfg := New().
+		WithCluster("test", &rest.Config{Host: server.URL}).
+		WithClientOptions(klient.WithQPS(50, 100), klient.WithTimeout(time.Minute)).
+		WithAPICallCounting()
+	client, err := cfg.NewClusterClient("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restCfg := client.RESTConfig(); restCfg.QPS != 50 || restCfg.Burst != 100 || restCfg.Timeout != time.Minute {
+		t.Errorf("unexpected client QPS %v, burst %d, or timeout %s", restCfg.QPS, restCfg.Burst, restCfg.Timeout)
+	}
+
+	// the discovery of the client is counted
+	if count, _ := cfg.APICalls().Total(""); count == 0 {
+		t.Errorf("expected the API calls of the client to be counted, got %+v", cfg.APICalls().Calls())
+	}
+}
+
+func TestConfig_ArtifactPath(t *testing.T) {
+	dir := t.TempDir()
+	cfg := New().WithArtifactsDir(dir)
+
+	first, err := cfg.ArtifactPath("TestFeature/assess pods")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := filepath.Join(dir, "TestFeature", "assess_pods"); first != expected {
+		t.Errorf("expected path %s, got %s", expected, first)
+	}
+
+	second, err := cfg.ArtifactPath("TestFeature/assess pods")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second != first+"-1" {
+		t.Errorf("expected unique path %s-1, got %s", first, second)
+	}
+
+	escaped, err := cfg.ArtifactPath("../outside")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(escaped) != dir {
+		t.Errorf("expected path under %s, got %s", dir, escaped)
+	}
+	for _, p := range []string{first, second, escaped} {
+		if info, err := os.Stat(p); err != nil || !info.IsDir() {
+			t.Errorf("expected directory %s to be created: %v", p, err)
+		}
+	}
+}
+
+func TestConfig_WithSecrets(t *testing.T) {
+	cfg := New().WithSecrets("s3cret-token")
+	defer log.SetLogFilter(nil)
+
+	if got := cfg.Redact("Authorization: Bearer s3cret-token"); got != "Authorization: Bearer [REDACTED]" {
+		t.Errorf("unexpected redaction: %s", got)
+	}
+
+	path := filepath.Join(t.TempDir(), "logs", "controller.log")
+	w, err := cfg.CreateArtifact(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("token=s3cret-token\ndone")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "token=[REDACTED]\ndone" {
+		t.Errorf("unexpected artifact content: %q", data)
+	}
+}
+
+func TestConfig_WithSecrets_Logs(t *testing.T) {
+	var out bytes.Buffer
+	log.SetOutput(&out)
+	log.LogToStderr(false)
+	defer log.LogToStderr(true)
+	defer log.SetLogFilter(nil)
+
+	first := New().WithSecrets("first-token")
+	second := New().WithSecrets("second-token")
+	log.InfoS("Connecting", "first", "first-token", "second", "second-token")
+	log.Flush()
+
+	output := out.String()
+	if strings.Contains(output, "first-token") || strings.Contains(output, "second-token") {
+		t.Errorf("expected the secrets of both configurations to be masked, got:\n%s", output)
+	}
+	if got := first.Redact("second-token"); got != "second-token" {
+		t.Errorf("unexpected redaction of the secret of another configuration: %s", got)
+	}
+	if got := second.Redact("second-token"); got != "[REDACTED]" {
+		t.Errorf("unexpected redaction: %s", got)
+	}
+}
+
+func TestConfig_ConcurrentUse(t *testing.T) {
+	cfg := New().WithLabels(map[string]string{"env": "e2e"})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cfg.WithNamespace(RandomName("ns", 10))
+			_ = cfg.Namespace()
+			cfg.WithLabel(fmt.Sprintf("feature-%d", i), "true")
+			for k := range cfg.Labels() {
+				_ = k
+			}
+			cfg.WithCluster(fmt.Sprintf("cluster-%d", i), &rest.Config{Host: "https://localhost"})
+			_ = cfg.ClusterNames()
+		}(i)
+	}
+	wg.Wait()
+
+	if len(cfg.Labels()) != 11 {
+		t.Errorf("expected 11 labels, got %v", cfg.Labels())
+	}
+	if len(cfg.ClusterNames()) != 10 {
+		t.Errorf("expected 10 clusters, got %v", cfg.ClusterNames())
+	}
+
+	lbls := cfg.Labels()
+	lbls["env"] = "changed"
+	if cfg.Labels()["env"] != "e2e" {
+		t.Error("expected Labels to return a copy")
+	}
+}
+
+func TestConfig_WithInClusterDetection(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	t.Setenv("KUBECONFIG", kubeconfig)
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+
+	if cfg := New(); cfg.KubeconfigFile() != "" {
+		t.Errorf("expected no kubeconfig without detection, got %s", cfg.KubeconfigFile())
+	}
+	if cfg := New().WithInClusterDetection(); cfg.KubeconfigFile() != kubeconfig {
+		t.Errorf("expected kubeconfig resolved from $KUBECONFIG outside a cluster, got %s", cfg.KubeconfigFile())
+	}
+	if cfg := NewWithKubeConfig("explicit").WithInClusterDetection(); cfg.KubeconfigFile() != "explicit" {
+		t.Errorf("expected explicit kubeconfig to be kept, got %s", cfg.KubeconfigFile())
+	}
+}
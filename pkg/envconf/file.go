@@ -0,0 +1,186 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envconf
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/flowcontrol"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/e2e-framework/klient"
+	"sigs.k8s.io/e2e-framework/klient/apicalls"
+	"sigs.k8s.io/e2e-framework/pkg/labels"
+)
+
+// fileConfig is the content of a run configuration file, see NewFromFile
+type fileConfig struct {
+	Kubeconfig            string            `json:"kubeconfig,omitempty"`
+	Namespace             string            `json:"namespace,omitempty"`
+	Feature               string            `json:"feature,omitempty"`
+	Assess                string            `json:"assess,omitempty"`
+	SkipFeatures          string            `json:"skipFeatures,omitempty"`
+	SkipAssessment        string            `json:"skipAssessment,omitempty"`
+	Labels                map[string]string `json:"labels,omitempty"`
+	SkipLabels            map[string]string `json:"skipLabels,omitempty"`
+	LabelFilter           string            `json:"labelFilter,omitempty"`
+	Parallel              bool              `json:"parallel,omitempty"`
+	Artifacts             string            `json:"artifacts,omitempty"`
+	RunBudget             metav1.Duration   `json:"runBudget,omitempty"`
+	SuiteTimeout          metav1.Duration   `json:"suiteTimeout,omitempty"`
+	RateLimit             *fileRateLimit    `json:"rateLimit,omitempty"`
+	Client                fileClient        `json:"client,omitempty"`
+	StateFile             string            `json:"stateFile,omitempty"`
+	SkipTeardownOnFailure bool              `json:"skipTeardownOnFailure,omitempty"`
+	RandomSeed            int64             `json:"randomSeed,omitempty"`
+	RunID                 string            `json:"runID,omitempty"`
+	TraceEndpoint         string            `json:"traceEndpoint,omitempty"`
+	Provider              fileProvider      `json:"provider,omitempty"`
+	Flags                 map[string]string `json:"flags,omitempty"`
+}
+
+type fileRateLimit struct {
+	QPS   float32 `json:"qps"`
+	Burst int     `json:"burst"`
+}
+
+type fileClient struct {
+	QPS           float32         `json:"qps,omitempty"`
+	Burst         int             `json:"burst,omitempty"`
+	Timeout       metav1.Duration `json:"timeout,omitempty"`
+	CountAPICalls bool            `json:"countAPICalls,omitempty"`
+}
+
+type fileProvider struct {
+	Name     string            `json:"name,omitempty"`
+	Settings map[string]string `json:"settings,omitempty"`
+}
+
+// NewFromFile initializes an environment config from a declarative YAML, or JSON,
+// run configuration file, so that CI pipelines can configure runs without long
+// command lines:
+//
+//	kubeconfig: /etc/ci/kubeconfig
+//	namespace: e2e
+//	labels:
+//	  type: conformance
+//	labelFilter: "!slow"
+//	parallel: true
+//	artifacts: /tmp/artifacts
+//	runBudget: 45m
+//	suiteTimeout: 50m
+//	rateLimit:
+//	  qps: 20
+//	  burst: 40
+//	client:
+//	  qps: 50
+//	  burst: 100
+//	  timeout: 30s
+//	  countAPICalls: true
+//	provider:
+//	  name: kind
+//	  settings:
+//	    image: kindest/node:v1.23.4
+//	flags:
+//	  controller-image: example.com/controller:dev
+//
+// The other fields are feature, assess, skipFeatures, skipAssessment, skipLabels,
+// stateFile, skipTeardownOnFailure, randomSeed, runID, and traceEndpoint, named after
+// the flags. The values of the flags section are returned by Flag. The file can also
+// be set with the --config flag, see NewFromFlags.
+func NewFromFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("envconfig: %w", err)
+	}
+	var file fileConfig
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("envconfig: %s: %w", path, err)
+	}
+	c := New()
+	if err := c.applyFile(&file); err != nil {
+		return nil, fmt.Errorf("envconfig: %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// applyFile sets the fields of the configuration from the content of a configuration file
+func (c *Config) applyFile(file *fileConfig) error {
+	var err error
+	if c.featureRegex, err = compileRegex(file.Feature); err != nil {
+		return err
+	}
+	if c.assessmentRegex, err = compileRegex(file.Assess); err != nil {
+		return err
+	}
+	if c.skipFeatureRegex, err = compileRegex(file.SkipFeatures); err != nil {
+		return err
+	}
+	if c.skipAssessmentRegex, err = compileRegex(file.SkipAssessment); err != nil {
+		return err
+	}
+	if file.LabelFilter != "" {
+		if c.labelFilter, err = labels.ParseFilter(file.LabelFilter); err != nil {
+			return err
+		}
+	}
+	if file.RateLimit != nil {
+		if file.RateLimit.QPS <= 0 || file.RateLimit.Burst <= 0 {
+			return fmt.Errorf("rateLimit: qps and burst must be positive")
+		}
+		c.rateLimiter = flowcontrol.NewTokenBucketRateLimiter(file.RateLimit.QPS, file.RateLimit.Burst)
+	}
+	if file.Client.QPS > 0 || file.Client.Burst > 0 {
+		c.clientOptions = append(c.clientOptions, klient.WithQPS(file.Client.QPS, file.Client.Burst))
+	}
+	if file.Client.Timeout.Duration > 0 {
+		c.clientOptions = append(c.clientOptions, klient.WithTimeout(file.Client.Timeout.Duration))
+	}
+	if file.Client.CountAPICalls {
+		c.apiCalls = apicalls.NewCounter()
+	}
+	if file.RandomSeed != 0 {
+		c.names.seed(file.RandomSeed)
+	}
+
+	c.kubeconfig = file.Kubeconfig
+	c.namespace = file.Namespace
+	c.labels = copyLabels(file.Labels)
+	c.skipLabels = copyLabels(file.SkipLabels)
+	c.parallelTests = file.Parallel
+	c.artifactsDir = file.Artifacts
+	c.runBudget = file.RunBudget.Duration
+	c.suiteTimeout = file.SuiteTimeout.Duration
+	c.stateFile = file.StateFile
+	c.skipTeardown = file.SkipTeardownOnFailure
+	c.runID = file.RunID
+	c.traceEndpoint = file.TraceEndpoint
+	c.provider = file.Provider.Name
+	c.providerSettings = copyLabels(file.Provider.Settings)
+	c.flags = copyLabels(file.Flags)
+	return nil
+}
+
+func compileRegex(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	return regexp.Compile(expr)
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envconf
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/e2e-framework/pkg/flags"
+)
+
+const testFileConfig = `
+kubeconfig: /etc/ci/kubeconfig
+namespace: e2e
+feature: ^storage
+labels:
+  type: conformance
+labelFilter: "!slow"
+parallel: true
+artifacts: /tmp/artifacts
+runBudget: 45m
+suiteTimeout: 50m
+rateLimit:
+  qps: 20
+  burst: 40
+client:
+  qps: 50
+  burst: 100
+  countAPICalls: true
+runID: ci-1234
+provider:
+  name: kind
+  settings:
+    image: kindest/node:v1.23.4
+flags:
+  controller-image: example.com/controller:dev
+`
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestNewFromFile(t *testing.T) {
+	cfg, err := NewFromFile(writeConfigFile(t, "e2e.yaml", testFileConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.KubeconfigFile() != "/etc/ci/kubeconfig" || cfg.Namespace() != "e2e" {
+		t.Errorf("unexpected kubeconfig %q or namespace %q", cfg.KubeconfigFile(), cfg.Namespace())
+	}
+	if cfg.FeatureRegex() == nil || !cfg.FeatureRegex().MatchString("storage volumes") {
+		t.Errorf("unexpected feature regex %v", cfg.FeatureRegex())
+	}
+	if !reflect.DeepEqual(cfg.Labels(), map[string]string{"type": "conformance"}) {
+		t.Errorf("unexpected labels %v", cfg.Labels())
+	}
+	if cfg.LabelFilter() == nil || cfg.LabelFilter().String() != "!slow" {
+		t.Errorf("unexpected label filter %v", cfg.LabelFilter())
+	}
+	if !cfg.ParallelTestEnabled() || cfg.ArtifactsDir() != "/tmp/artifacts" {
+		t.Errorf("unexpected parallel %t or artifacts %q", cfg.ParallelTestEnabled(), cfg.ArtifactsDir())
+	}
+	if cfg.RunBudget() != 45*time.Minute || cfg.SuiteTimeout() != 50*time.Minute {
+		t.Errorf("unexpected run budget %s or suite timeout %s", cfg.RunBudget(), cfg.SuiteTimeout())
+	}
+	if cfg.RateLimiter() == nil || cfg.RateLimiter().QPS() != 20 {
+		t.Errorf("unexpected rate limiter %v", cfg.RateLimiter())
+	}
+	if len(cfg.clientOptions) != 1 || cfg.APICalls() == nil {
+		t.Errorf("expected the client QPS option and the API call counting, got %d options", len(cfg.clientOptions))
+	}
+	if cfg.RunID() != "ci-1234" {
+		t.Errorf("unexpected run id %q", cfg.RunID())
+	}
+	if cfg.Provider() != "kind" || cfg.ProviderSetting("image") != "kindest/node:v1.23.4" {
+		t.Errorf("unexpected provider %q with image %q", cfg.Provider(), cfg.ProviderSetting("image"))
+	}
+	if cfg.Flag("controller-image") != "example.com/controller:dev" {
+		t.Errorf("unexpected flag value %q", cfg.Flag("controller-image"))
+	}
+}
+
+func TestNewFromFile_JSON(t *testing.T) {
+	cfg, err := NewFromFile(writeConfigFile(t, "e2e.json", `{"namespace": "e2e", "skipLabels": {"slow": "true"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Namespace() != "e2e" || cfg.SkipLabels()["slow"] != "true" {
+		t.Errorf("unexpected namespace %q or skip labels %v", cfg.Namespace(), cfg.SkipLabels())
+	}
+}
+
+func TestNewFromFile_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{name: "unknown field", content: "namespaces: e2e", err: "unknown field"},
+		{name: "invalid regex", content: "feature: '['", err: "missing closing ]"},
+		{name: "invalid label filter", content: "labelFilter: 'a &&'", err: "label filter"},
+		{name: "invalid duration", content: "runBudget: forever", err: "invalid duration"},
+		{name: "invalid rate limit", content: "rateLimit: {qps: 10}", err: "rateLimit"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewFromFile(writeConfigFile(t, "e2e.yaml", test.content))
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("expected error containing %q, got %v", test.err, err)
+			}
+		})
+	}
+
+	if _, err := NewFromFile(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestNewFromFlags_WithConfigFile(t *testing.T) {
+	path := writeConfigFile(t, "e2e.yaml", testFileConfig)
+	os.Args = []string{"test-binary", "--config", path, "--namespace", "override", "--labels", "type=smoke"}
+	cfg, err := NewFromFlags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Namespace() != "override" {
+		t.Errorf("expected the namespace flag to override the file, got %q", cfg.Namespace())
+	}
+	if !reflect.DeepEqual(cfg.Labels(), map[string]string{"type": "smoke"}) {
+		t.Errorf("expected the labels flag to override the file, got %v", cfg.Labels())
+	}
+	if cfg.KubeconfigFile() != "/etc/ci/kubeconfig" || !cfg.ParallelTestEnabled() {
+		t.Errorf("expected the values of the file, got kubeconfig %q and parallel %t", cfg.KubeconfigFile(), cfg.ParallelTestEnabled())
+	}
+}
+
+func TestNewFromFlags_WithConfigFile_DefaultValues(t *testing.T) {
+	flags.Define("controller-image", "example.com/controller:latest", "image of the controller")
+	path := writeConfigFile(t, "e2e.yaml", testFileConfig+"skipTeardownOnFailure: true\n")
+	os.Args = []string{"test-binary", "--config", path, "--parallel=false", "--skip-teardown-on-failure=false", "--controller-image", "example.com/controller:latest"}
+	cfg, err := NewFromFlags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ParallelTestEnabled() || cfg.SkipTeardownOnFailure() {
+		t.Errorf("expected the flags to override the file, got parallel %t and skip teardown %t", cfg.ParallelTestEnabled(), cfg.SkipTeardownOnFailure())
+	}
+	if cfg.Flag("controller-image") != "example.com/controller:latest" {
+		t.Errorf("expected the custom flag to override the file, got %q", cfg.Flag("controller-image"))
+	}
+	if cfg.Namespace() != "e2e" {
+		t.Errorf("expected the namespace of the file, got %q", cfg.Namespace())
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envconf
+
+import (
+	"github.com/go-logr/logr"
+	"k8s.io/klog/v2/klogr"
+)
+
+// WithVerbosity sets the verbosity of the logger returned by Logger. The environment
+// logs the start and finish of the actions and features at level 1, and of the steps
+// at level 2. When created from flags, the verbosity is the value of the --v flag.
+func (c *Config) WithVerbosity(v int) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.verbosity = v
+	return c
+}
+
+// Verbosity returns the verbosity of the logger returned by Logger
+func (c *Config) Verbosity() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.verbosity
+}
+
+// Logger returns a structured logger, writing to klog, whose messages are
+// logged when their level is at most the verbosity of the configuration.
+// Test authors can use it for output consistent with the framework logs:
+//
+//	cfg.Logger().V(1).Info("Deployment created", "name", dep.Name)
+func (c *Config) Logger() logr.Logger {
+	sink := klogr.NewWithOptions(klogr.WithFormat(klogr.FormatKlog)).GetSink()
+	return logr.New(&verbositySink{LogSink: sink, verbosity: c.Verbosity()})
+}
+
+// verbositySink enables the messages based on the verbosity of the
+// configuration, rather than the global klog verbosity.
+type verbositySink struct {
+	logr.LogSink
+	verbosity int
+}
+
+// Init does not initialize the klog sink again since it was initialized by klogr
+func (s *verbositySink) Init(logr.RuntimeInfo) {}
+
+func (s *verbositySink) Enabled(level int) bool {
+	return level <= s.verbosity
+}
+
+func (s *verbositySink) WithName(name string) logr.LogSink {
+	return &verbositySink{LogSink: s.LogSink.WithName(name), verbosity: s.verbosity}
+}
+
+func (s *verbositySink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	return &verbositySink{LogSink: s.LogSink.WithValues(keysAndValues...), verbosity: s.verbosity}
+}
+
+func (s *verbositySink) WithCallDepth(depth int) logr.LogSink {
+	sink := s.LogSink
+	if cd, ok := sink.(logr.CallDepthLogSink); ok {
+		sink = cd.WithCallDepth(depth)
+	}
+	return &verbositySink{LogSink: sink, verbosity: s.verbosity}
+}
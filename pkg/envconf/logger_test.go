@@ -0,0 +1,48 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envconf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	log "k8s.io/klog/v2"
+)
+
+func TestConfig_Logger(t *testing.T) {
+	var out bytes.Buffer
+	log.SetOutput(&out)
+	log.LogToStderr(false)
+	defer log.LogToStderr(true)
+
+	logger := New().WithVerbosity(1).Logger().WithValues("feature", "pods")
+	logger.Info("starting")
+	logger.V(1).Info("verbose", "name", "p1")
+	logger.V(2).Info("too verbose")
+	log.Flush()
+
+	output := out.String()
+	for _, expected := range []string{`"starting" feature="pods"`, `"verbose" feature="pods" name="p1"`, "logger_test.go"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected log output to contain %q, got:\n%s", expected, output)
+		}
+	}
+	if strings.Contains(output, "too verbose") {
+		t.Errorf("expected messages above the verbosity to be discarded, got:\n%s", output)
+	}
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envconf
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	log "k8s.io/klog/v2"
+)
+
+// defaultNameLength is the length of the random names when none is given
+const defaultNameLength = 32
+
+// nameGenerator generates random names from a seeded source, so that the names
+// of a run can be reproduced with the same seed. It is safe for concurrent use,
+// names being generated concurrently by parallel features.
+type nameGenerator struct {
+	mu     sync.Mutex
+	rnd    *rand.Rand
+	seeded int64
+}
+
+// seed resets the random source of the generator with seed
+func (g *nameGenerator) seed(seed int64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.rnd = rand.New(rand.NewSource(seed)) // nolint:gosec
+	g.seeded = seed
+}
+
+// current returns the seed of the generator, seeding it from the time if not seeded yet
+func (g *nameGenerator) current() int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.init()
+	return g.seeded
+}
+
+// init seeds the generator from the time, unless already seeded. It must be called with the lock held.
+func (g *nameGenerator) init() {
+	if g.rnd != nil {
+		return
+	}
+	g.seeded = time.Now().UnixNano()
+	g.rnd = rand.New(rand.NewSource(g.seeded)) // nolint:gosec
+	log.V(1).InfoS("Seeded random names, reproduce them with --random-seed", "seed", g.seeded)
+}
+
+func (g *nameGenerator) name(prefix string, n int) string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.init()
+	return randomName(g.rnd, prefix, n)
+}
+
+// names is the generator of the names created with RandomName
+var names nameGenerator
+
+// RandomName generates a random name of n length, 32 if n is 0, with the provided prefix.
+// If prefix is omitted, the entire name is random characters. The name is a valid RFC 1123
+// label, usable for namespaces and most objects: the prefix is lowercased and its invalid
+// characters replaced by '-', and it is shortened if needed to keep a random suffix.
+//
+// Use Config.RandomName for names reproducible with the --random-seed flag.
+func RandomName(prefix string, n int) string {
+	return names.name(prefix, n)
+}
+
+// WithRandomSeed seeds the source of the names generated with RandomName and NewNamespaceName,
+// so that a run generates the same names when repeated with the same seed, i.e. to reproduce a
+// failure. It can also be set with the --random-seed flag.
+func (c *Config) WithRandomSeed(seed int64) *Config {
+	c.names.seed(seed)
+	return c
+}
+
+// RandomSeed returns the seed of the names generated by the configuration. Unless set with
+// WithRandomSeed, it is derived from the time, and logged at verbosity 1 when first used.
+func (c *Config) RandomSeed() int64 {
+	return c.names.current()
+}
+
+// RandomName generates a random name, as the RandomName func, from the seeded source of the
+// configuration so that the names are reproducible.
+func (c *Config) RandomName(prefix string, n int) string {
+	return c.names.name(prefix, n)
+}
+
+// NewNamespaceName generates a random, RFC 1123 compliant, namespace name
+func (c *Config) NewNamespaceName() string {
+	return c.RandomName("testns", defaultNameLength)
+}
+
+// RunLabelKey is the key of the label identifying the objects created by a test run
+const RunLabelKey = "e2e-framework.sigs.k8s.io/run"
+
+// WithRunID sets the identifier of the test run, i.e. the id of the CI job, used
+// as the value of the run label. It must be a valid label value.
+func (c *Config) WithRunID(id string) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.runID = id
+	return c
+}
+
+// RunID returns the identifier of the test run. Unless set with WithRunID, a random
+// identifier is generated on first use, without affecting the seeded random names.
+func (c *Config) RunID() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.runID == "" {
+		c.runID = randomName(rand.New(rand.NewSource(time.Now().UnixNano())), "run", 16) // nolint:gosec
+	}
+	return c.runID
+}
+
+// RunLabels returns the labels identifying the objects created by the test run, to be set on
+// the objects the tests create so that they can be swept with envfuncs.DeleteResourcesByLabel
+func (c *Config) RunLabels() map[string]string {
+	return map[string]string{RunLabelKey: c.RunID()}
+}
+
+// RunLabelSelector returns the label selector of the objects labeled with RunLabels
+func (c *Config) RunLabelSelector() string {
+	return fmt.Sprintf("%s=%s", RunLabelKey, c.RunID())
+}
+
+func randomName(rnd *rand.Rand, prefix string, n int) string {
+	if n <= 0 {
+		n = defaultNameLength
+	}
+	prefix = strings.Trim(sanitizeName(prefix), "-")
+	// keep at least a random character after the prefix and its separator
+	if prefix != "" && len(prefix)+2 > n {
+		if n < 3 {
+			prefix = ""
+		} else {
+			prefix = strings.TrimRight(prefix[:n-2], "-")
+		}
+	}
+
+	p := make([]byte, n/2+1)
+	rnd.Read(p)
+	suffix := hex.EncodeToString(p)
+	if prefix == "" {
+		return suffix[:n]
+	}
+	return prefix + "-" + suffix[:n-len(prefix)-1]
+}
+
+// sanitizeName lowercases s and replaces the characters not allowed in RFC 1123 labels with '-'
+func sanitizeName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		default:
+			return '-'
+		}
+	}, s)
+}
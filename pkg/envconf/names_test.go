@@ -0,0 +1,95 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envconf
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func TestRandomName(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefix   string
+		n        int
+		length   int
+		expected string
+	}{
+		{name: "with prefix", prefix: "my-cluster", n: 16, length: 16, expected: "my-cluster-"},
+		{name: "without prefix", n: 10, length: 10},
+		{name: "default length", prefix: "ns", length: 32, expected: "ns-"},
+		{name: "invalid characters", prefix: "My_Suite.Test", n: 24, length: 24, expected: "my-suite-test-"},
+		{name: "trailing separator", prefix: "ns-", n: 8, length: 8, expected: "ns-"},
+		{name: "long prefix", prefix: "a-very-long-prefix", n: 8, length: 8, expected: "a-very-"},
+		{name: "too short for prefix", prefix: "ns", n: 2, length: 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			name := RandomName(test.prefix, test.n)
+			if len(name) != test.length {
+				t.Errorf("expected %d characters, got %q", test.length, name)
+			}
+			if !strings.HasPrefix(name, test.expected) {
+				t.Errorf("expected prefix %q, got %q", test.expected, name)
+			}
+			if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+				t.Errorf("invalid name %q: %v", name, errs)
+			}
+		})
+	}
+}
+
+func TestConfig_RandomSeed(t *testing.T) {
+	first, second := New().WithRandomSeed(42), New().WithRandomSeed(42)
+	for i := 0; i < 3; i++ {
+		if a, b := first.NewNamespaceName(), second.NewNamespaceName(); a != b {
+			t.Errorf("expected the same names with the same seed, got %q and %q", a, b)
+		}
+	}
+	if seed := first.RandomSeed(); seed != 42 {
+		t.Errorf("expected seed 42, got %d", seed)
+	}
+
+	cfg := New()
+	if cfg.RandomSeed() == 0 {
+		t.Error("expected a seed derived from the time")
+	}
+	if a, b := cfg.RandomName("ns", 16), cfg.RandomName("ns", 16); a == b {
+		t.Errorf("expected different names, got %q twice", a)
+	}
+}
+
+func TestConfig_RunID(t *testing.T) {
+	cfg := New()
+	id := cfg.RunID()
+	if id == "" || id != cfg.RunID() {
+		t.Fatalf("expected a stable run id, got %q and %q", id, cfg.RunID())
+	}
+	if errs := validation.IsValidLabelValue(id); len(errs) > 0 {
+		t.Errorf("run id %q is not a valid label value: %v", id, errs)
+	}
+	if labels := cfg.RunLabels(); labels[RunLabelKey] != id {
+		t.Errorf("expected run labels with %q, got %v", id, labels)
+	}
+
+	cfg.WithRunID("ci-1234")
+	if selector := cfg.RunLabelSelector(); selector != RunLabelKey+"=ci-1234" {
+		t.Errorf("unexpected run label selector %q", selector)
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envconf
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	log "k8s.io/klog/v2"
+
+	"sigs.k8s.io/e2e-framework/pkg/tracing"
+)
+
+// WithTraceEndpoint sets the OTLP/HTTP collector endpoint (i.e. http://localhost:4318)
+// the environment exports its spans to, unless a provider is set with WithTracerProvider.
+// The provider exporting to the endpoint is shut down, flushing the spans, when the
+// environment is done running. When created from flags, the endpoint is the value of
+// the --trace-endpoint flag.
+func (c *Config) WithTraceEndpoint(endpoint string) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.traceEndpoint = endpoint
+	return c
+}
+
+// TraceEndpoint returns the OTLP/HTTP collector endpoint spans are exported to
+func (c *Config) TraceEndpoint() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.traceEndpoint
+}
+
+// WithTracerProvider sets the provider of the tracer creating the spans of the
+// environment setup, features, and assessments. The caller is responsible for
+// shutting down the provider once the tests are done.
+func (c *Config) WithTracerProvider(tp trace.TracerProvider) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tracerProvider = tp
+	return c
+}
+
+// TracerProvider returns the provider set with WithTracerProvider, a provider exporting
+// to the endpoint set with WithTraceEndpoint, or the global OpenTelemetry provider,
+// which does not record the spans unless configured. The global provider is also
+// returned, and the error logged, when the endpoint is invalid.
+func (c *Config) TracerProvider() trace.TracerProvider {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tracerProvider != nil {
+		return c.tracerProvider
+	}
+	if c.traceEndpoint != "" {
+		tp, err := tracing.NewTracerProvider(context.Background(), c.traceEndpoint)
+		if err != nil {
+			log.ErrorS(err, "Spans not exported", "endpoint", c.traceEndpoint)
+			return otel.GetTracerProvider()
+		}
+		c.endpointProvider = tp
+		c.tracerProvider = c.endpointProvider
+		return c.tracerProvider
+	}
+	return otel.GetTracerProvider()
+}
+
+// ShutdownTracing shuts down the provider exporting to the endpoint set with
+// WithTraceEndpoint, if it was created, to flush the remaining spans. A new
+// provider is created if spans are started afterwards.
+func (c *Config) ShutdownTracing(ctx context.Context) error {
+	c.mu.Lock()
+	tp := c.endpointProvider
+	if tp != nil {
+		c.endpointProvider = nil
+		c.tracerProvider = nil
+	}
+	c.mu.Unlock()
+
+	if tp == nil {
+		return nil
+	}
+	if err := tp.Shutdown(ctx); err != nil {
+		return fmt.Errorf("envconfig: tracing shutdown: %w", err)
+	}
+	return nil
+}
+
+// Tracer returns the tracer creating the spans of the environment. Test authors
+// can use it to add their own spans, nested in the assessment spans when started
+// from the context passed to the steps.
+func (c *Config) Tracer() trace.Tracer {
+	return c.TracerProvider().Tracer(tracing.TracerName)
+}
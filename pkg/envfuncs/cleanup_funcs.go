@@ -0,0 +1,83 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envfuncs
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+)
+
+// SetupFeatureCleanup provides an Environment.FeatureFunc that adds a new cleanup registry
+// to the context of each feature. Objects created in the feature steps can be registered
+// with resources.RegisterCleanup(ctx, obj) to be deleted by RunFeatureCleanup.
+//
+// NOTE: this should be used with Environment.BeforeEachFeature.
+func SetupFeatureCleanup() env.FeatureFunc {
+	return func(ctx context.Context, _ *envconf.Config, _ *testing.T, _ features.Feature) (context.Context, error) {
+		return resources.WithCleanup(ctx), nil
+	}
+}
+
+// RunFeatureCleanup provides an Environment.FeatureFunc that deletes the objects registered
+// during the feature, even when one of its assessments failed midway. The objects are kept
+// when the teardowns are skipped after a failure, see env.TeardownSkipped.
+//
+// NOTE: this should be used with Environment.AfterEachFeature.
+func RunFeatureCleanup() env.FeatureFunc {
+	return func(ctx context.Context, cfg *envconf.Config, _ *testing.T, _ features.Feature) (context.Context, error) {
+		if env.TeardownSkipped(ctx) {
+			return ctx, nil
+		}
+		client, err := cfg.NewClient()
+		if err != nil {
+			return ctx, fmt.Errorf("run feature cleanup func: %w", err)
+		}
+		if err := resources.Cleanup(ctx, client.Resources()); err != nil {
+			return ctx, fmt.Errorf("run feature cleanup func: %w", err)
+		}
+		return ctx, nil
+	}
+}
+
+// VerifyFeatureTeardown provides an Environment.FeatureFunc that waits, up to timeout, for
+// all the objects registered during the feature to be gone, turning resources leaked by the
+// teardown steps into feature failures listing the stragglers. Nothing is verified when the
+// teardowns are skipped after a failure, see env.TeardownSkipped.
+//
+// NOTE: this should be used with Environment.AfterEachFeature, after RunFeatureCleanup if used.
+func VerifyFeatureTeardown(timeout time.Duration) env.FeatureFunc {
+	return func(ctx context.Context, cfg *envconf.Config, _ *testing.T, _ features.Feature) (context.Context, error) {
+		if env.TeardownSkipped(ctx) {
+			return ctx, nil
+		}
+		client, err := cfg.NewClient()
+		if err != nil {
+			return ctx, fmt.Errorf("verify feature teardown func: %w", err)
+		}
+		if err := resources.VerifyCleanup(ctx, client.Resources(), timeout); err != nil {
+			return ctx, fmt.Errorf("verify feature teardown func: %w", err)
+		}
+		return ctx, nil
+	}
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envfuncs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	log "k8s.io/klog/v2"
+
+	"sigs.k8s.io/e2e-framework/klient"
+	"sigs.k8s.io/e2e-framework/klient/conf"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/klient/wait/conditions"
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/internal/cluster"
+)
+
+// WaitForClusterReady provides an Environment.Func that waits, up to timeout, for the
+// API server of the env config cluster to report ready on its /readyz endpoint.
+//
+// NOTE: this is useful in the Setup right after creating a cluster with a provider.
+func WaitForClusterReady(timeout time.Duration) env.Func {
+	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		client, err := cfg.NewClient()
+		if err != nil {
+			return ctx, fmt.Errorf("wait for cluster ready func: %w", err)
+		}
+		cs, err := kubernetes.NewForConfig(client.RESTConfig())
+		if err != nil {
+			return ctx, fmt.Errorf("wait for cluster ready func: %w", err)
+		}
+
+		err = wait.For(func() (bool, error) {
+			body, err := cs.Discovery().RESTClient().Get().AbsPath("/readyz").DoRaw(ctx)
+			if err != nil {
+				log.V(4).Infof("Cluster not ready: %s", err)
+				return false, nil
+			}
+			return string(body) == "ok", nil
+		}, wait.WithTimeout(timeout), wait.WithImmediate())
+		if err != nil {
+			return ctx, fmt.Errorf("wait for cluster ready func: %w", err)
+		}
+		return ctx, nil
+	}
+}
+
+// WaitForNodesReady provides an Environment.Func that waits, up to timeout, for at
+// least count nodes of the env config cluster to report the Ready condition.
+func WaitForNodesReady(count int, timeout time.Duration) env.Func {
+	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		client, err := cfg.NewClient()
+		if err != nil {
+			return ctx, fmt.Errorf("wait for nodes ready func: %w", err)
+		}
+		err = wait.For(conditions.New(client.Resources()).WithContext(ctx).NodesReady(count), wait.WithTimeout(timeout), wait.WithImmediate())
+		if err != nil {
+			return ctx, fmt.Errorf("wait for nodes ready func: %d node(s) not ready: %w", count, err)
+		}
+		return ctx, nil
+	}
+}
+
+// defaultPreflightTimeout bounds each preflight request to fail fast on unreachable clusters
+const defaultPreflightTimeout = 10 * time.Second
+
+// PreflightOptions configures the checks run by LoadExistingCluster
+type PreflightOptions struct {
+	// MinServerVersion is the minimum Kubernetes version of the API server, i.e. 1.22
+	MinServerVersion string
+	// APIGroups are the API groups that must be served, i.e. apps or cert-manager.io
+	APIGroups []string
+	// Timeout of each preflight request
+	Timeout time.Duration
+}
+
+type PreflightOption func(*PreflightOptions)
+
+// WithMinServerVersion requires the API server version to be at least ver
+func WithMinServerVersion(ver string) PreflightOption {
+	return func(o *PreflightOptions) {
+		o.MinServerVersion = ver
+	}
+}
+
+// WithRequiredAPIGroups requires the API groups to be served by the API server
+func WithRequiredAPIGroups(groups ...string) PreflightOption {
+	return func(o *PreflightOptions) {
+		o.APIGroups = append(o.APIGroups, groups...)
+	}
+}
+
+// WithPreflightTimeout sets the timeout of each preflight request
+func WithPreflightTimeout(timeout time.Duration) PreflightOption {
+	return func(o *PreflightOptions) {
+		o.Timeout = timeout
+	}
+}
+
+// LoadExistingCluster provides an Environment.Func that configures the env config to use an
+// existing cluster, from the kubeconfig file and context name, after verifying that the API server
+// is reachable, and, optionally, that its version and served API groups meet the requirements.
+// An empty kubeconfig resolves to the kubeconfig of the env config, i.e. set with the --kubeconfig
+// flag, then to $KUBECONFIG or $HOME/.kube/config, and an empty context name to the current context.
+//
+// NOTE: this should be used in the Environment.Setup, so that the run fails fast, with a
+// clear message, instead of every feature failing to connect to the cluster.
+func LoadExistingCluster(kubeconfig, contextName string, opts ...PreflightOption) env.Func {
+	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		kubeconfig := kubeconfig
+		if kubeconfig == "" {
+			kubeconfig = cfg.KubeconfigFile()
+		}
+		if kubeconfig == "" {
+			kubeconfig = conf.ResolveKubeConfigFile()
+		}
+		restCfg, err := conf.NewWithContextName(kubeconfig, contextName)
+		if err != nil {
+			return ctx, fmt.Errorf("load existing cluster func: kubeconfig %s: %w", kubeconfig, err)
+		}
+		if err := Preflight(restCfg, opts...); err != nil {
+			return ctx, fmt.Errorf("load existing cluster func: context %q: %w", contextName, err)
+		}
+
+		client, err := klient.New(restCfg)
+		if err != nil {
+			return ctx, fmt.Errorf("load existing cluster func: %w", err)
+		}
+		cfg.WithKubeconfigFile(kubeconfig).WithClient(client)
+		return ctx, nil
+	}
+}
+
+// Preflight verifies that the API server configured by restCfg is reachable and that
+// it meets the version and API groups requirements.
+func Preflight(restCfg *rest.Config, opts ...PreflightOption) error {
+	o := &PreflightOptions{Timeout: defaultPreflightTimeout}
+	for _, fn := range opts {
+		fn(o)
+	}
+
+	preflightCfg := rest.CopyConfig(restCfg)
+	preflightCfg.Timeout = o.Timeout
+	dc, err := discovery.NewDiscoveryClientForConfig(preflightCfg)
+	if err != nil {
+		return fmt.Errorf("preflight: %w", err)
+	}
+
+	info, err := dc.ServerVersion()
+	if err != nil {
+		return fmt.Errorf("preflight: cannot reach API server at %s: %w", restCfg.Host, err)
+	}
+	log.V(4).Infof("Preflight: API server %s version %s", restCfg.Host, info.GitVersion)
+
+	if o.MinServerVersion != "" {
+		older, err := cluster.OlderThan(info.GitVersion, o.MinServerVersion)
+		if err != nil {
+			return fmt.Errorf("preflight: %w", err)
+		}
+		if older {
+			return fmt.Errorf("preflight: API server version %s is older than the required %s", info.GitVersion, o.MinServerVersion)
+		}
+	}
+
+	if len(o.APIGroups) > 0 {
+		groups, err := dc.ServerGroups()
+		if err != nil {
+			return fmt.Errorf("preflight: list API groups: %w", err)
+		}
+		served := make(map[string]bool)
+		for _, g := range groups.Groups {
+			served[g.Name] = true
+		}
+		var missing []string
+		for _, g := range o.APIGroups {
+			if !served[g] {
+				missing = append(missing, g)
+			}
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("preflight: API group(s) not served: %s", strings.Join(missing, ", "))
+		}
+	}
+	return nil
+}
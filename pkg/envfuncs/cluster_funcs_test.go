@@ -0,0 +1,117 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envfuncs
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+)
+
+func TestPreflight(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/version":
+			w.Write([]byte(`{"major":"1","minor":"23","gitVersion":"v1.23.1"}`))
+		case "/api":
+			w.Write([]byte(`{"kind":"APIVersions","versions":["v1"]}`))
+		case "/apis":
+			w.Write([]byte(`{"kind":"APIGroupList","groups":[{"name":"apps","versions":[{"groupVersion":"apps/v1","version":"v1"}]}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	cfg := &rest.Config{Host: server.URL}
+
+	tests := []struct {
+		name string
+		cfg  *rest.Config
+		opts []PreflightOption
+		err  string
+	}{
+		{name: "satisfied", cfg: cfg, opts: []PreflightOption{WithMinServerVersion("1.22"), WithRequiredAPIGroups("apps")}},
+		{name: "old server", cfg: cfg, opts: []PreflightOption{WithMinServerVersion("1.24")}, err: "older than the required 1.24"},
+		{name: "missing group", cfg: cfg, opts: []PreflightOption{WithRequiredAPIGroups("apps", "cert-manager.io")}, err: "not served: cert-manager.io"},
+		{name: "unreachable", cfg: &rest.Config{Host: "http://127.0.0.1:1"}, opts: []PreflightOption{WithPreflightTimeout(time.Second)}, err: "cannot reach API server"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := Preflight(test.cfg, test.opts...)
+			if test.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("expected error containing %q, got: %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestLoadExistingCluster_ConfigKubeconfig(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"major":"1","minor":"23","gitVersion":"v1.23.1"}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	kubeconfig := filepath.Join(dir, "kubeconfig")
+	content := fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: %s
+contexts:
+- name: test
+  context:
+    cluster: test
+current-context: test
+`, server.URL)
+	if err := ioutil.WriteFile(kubeconfig, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// nothing to fallback to, the kubeconfig must come from the env config
+	t.Setenv("KUBECONFIG", "")
+	t.Setenv("HOME", dir)
+
+	cfg := envconf.NewWithKubeConfig(kubeconfig)
+	if _, err := LoadExistingCluster("", "")(context.TODO(), cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.KubeconfigFile() != kubeconfig {
+		t.Errorf("unexpected kubeconfig: %s", cfg.KubeconfigFile())
+	}
+	if cfg.Client() == nil {
+		t.Error("expected client to be set")
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envfuncs
+
+import (
+	"context"
+	"fmt"
+
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/support/envtest"
+)
+
+type envtestContextKey string
+
+// StartEnvtestControlPlane returns an env.Func that is used to start a local
+// control plane, an etcd and a kube-apiserver, from the envtest binaries found in
+// $KUBEBUILDER_ASSETS. The control plane is then injected in the context using the
+// name as a key. Features testing APIs only can run against it without a cluster.
+//
+// NOTE: the returned function will update its env config with the
+// kubeconfig file for the config client.
+func StartEnvtestControlPlane(name string, apiServerFlags ...string) env.Func {
+	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		cp := envtest.NewControlPlane(name).WithAPIServerFlags(apiServerFlags...)
+		kubecfg, err := cp.Start()
+		if err != nil {
+			return ctx, fmt.Errorf("start envtest control plane func: %w", err)
+		}
+
+		// update envconfig with kubeconfig
+		cfg.WithKubeconfigFile(kubecfg)
+
+		// store the control plane in ctx for future access using its name
+		return context.WithValue(ctx, envtestContextKey(name), cp), nil
+	}
+}
+
+// StopEnvtestControlPlane returns an env.Func that retrieves a previously
+// started control plane in the context (using the name), then stops it.
+//
+// NOTE: this should be used in a Environment.Finish step.
+func StopEnvtestControlPlane(name string) env.Func {
+	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		cp, ok := ctx.Value(envtestContextKey(name)).(*envtest.ControlPlane)
+		if !ok {
+			return ctx, fmt.Errorf("stop envtest control plane func: control plane %s not found in context", name)
+		}
+		if err := cp.Stop(); err != nil {
+			return ctx, fmt.Errorf("stop envtest control plane func: %w", err)
+		}
+		return ctx, nil
+	}
+}
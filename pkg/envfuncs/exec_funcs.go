@@ -0,0 +1,55 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envfuncs
+
+import (
+	"context"
+	"fmt"
+
+	log "k8s.io/klog/v2"
+
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/utils"
+)
+
+// Exec provides an Environment.Func that runs an external command, i.e. a cloud CLI in
+// Environment.Setup or a cleanup script in Environment.Finish. See ExecWithOptions.
+func Exec(name string, args ...string) env.Func {
+	return ExecWithOptions(name, args)
+}
+
+// ExecWithOptions provides an Environment.Func that runs an external command with the options
+// of utils.RunCommand. KUBECONFIG is set to the kubeconfig file of the environment, when known,
+// so that the command targets the cluster under test. The output of the command is logged, and
+// the func fails with the standard error of the command if it exits with a non-zero code.
+func ExecWithOptions(name string, args []string, opts ...utils.CommandOption) env.Func {
+	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		cmdOpts := opts
+		if kubeconfig := cfg.KubeconfigFile(); kubeconfig != "" {
+			cmdOpts = append([]utils.CommandOption{utils.WithCommandEnv("KUBECONFIG=" + kubeconfig)}, opts...)
+		}
+		result, err := utils.RunCommand(ctx, name, args, cmdOpts...)
+		if result != nil {
+			log.V(4).Infof("Command %q output:\n%s%s", result.Command, result.Stdout, result.Stderr)
+		}
+		if err != nil {
+			return ctx, fmt.Errorf("exec func: %w", err)
+		}
+		return ctx, nil
+	}
+}
@@ -89,6 +89,34 @@ func CreateKindClusterWithConfig(clusterName, image, configFilePath string) env.
 	}
 }
 
+// CreateKindClusterWithConfigTemplate returns an env.Func that is used to
+// create a kind cluster, with the kind config rendered from the Go template
+// and its data, that is then injected in the context using the name as a key.
+// See kind.RenderConfig for the functions available to the template.
+//
+// NOTE: the returned function will update its env config with the
+// kubeconfig file for the config client.
+func CreateKindClusterWithConfigTemplate(clusterName, image, configTemplate string, templateData interface{}) env.Func {
+	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		k := kind.NewCluster(clusterName)
+		kubecfg, err := k.CreateWithConfigTemplate(image, configTemplate, templateData)
+		if err != nil {
+			return ctx, err
+		}
+
+		// update envconfig  with kubeconfig
+		cfg.WithKubeconfigFile(kubecfg)
+
+		// stall, wait for pods initializations
+		if err := waitForControlPlane(cfg.Client()); err != nil {
+			return ctx, err
+		}
+
+		// store entire cluster value in ctx for future access using the cluster name
+		return context.WithValue(ctx, kindContextKey(clusterName), k), nil
+	}
+}
+
 func waitForControlPlane(client klient.Client) error {
 	r, err := resources.New(client.RESTConfig())
 	if err != nil {
@@ -129,22 +157,43 @@ func waitForControlPlane(client klient.Client) error {
 
 // DestroyKindCluster returns an EnvFunc that
 // retrieves a previously saved kind Cluster in the context (using the name), then deletes it.
+// The cluster is deleted by its name when it is not in the context, i.e. when the
+// Finish funcs of an environment shared with env.WithSharedStateFile are run by
+// another process than the one that created the cluster.
 //
 // NOTE: this should be used in a Environment.Finish step.
 //
 func DestroyKindCluster(name string) env.Func {
 	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
-		clusterVal := ctx.Value(kindContextKey(name))
-		if clusterVal == nil {
-			return ctx, fmt.Errorf("destroy kind cluster func: context cluster is nil")
+		cluster, err := contextKindCluster(ctx, name)
+		if err != nil {
+			return ctx, err
 		}
 
-		cluster, ok := clusterVal.(*kind.Cluster)
-		if !ok {
-			return ctx, fmt.Errorf("destroy kind cluster func: unexpected type for cluster value")
+		if err := cluster.Destroy(); err != nil {
+			return ctx, fmt.Errorf("destroy kind cluster: %w", err)
 		}
 
-		if err := cluster.Destroy(); err != nil {
+		return ctx, nil
+	}
+}
+
+// DestroyKindClusterWithDockerCleanup returns an EnvFunc that retrieves a previously
+// saved kind Cluster in the context (using the name), then deletes it along with the
+// docker containers and volumes labelled for the cluster, see kind.Cluster.WithDockerCleanup.
+// The shared kind docker network is kept, see kind.Cluster.WithNetworkCleanup. As with
+// DestroyKindCluster, the cluster is deleted by its name when it is not in the context.
+//
+// NOTE: this should be used in a Environment.Finish step.
+//
+func DestroyKindClusterWithDockerCleanup(name string) env.Func {
+	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		cluster, err := contextKindCluster(ctx, name)
+		if err != nil {
+			return ctx, err
+		}
+
+		if err := cluster.WithDockerCleanup().Destroy(); err != nil {
 			return ctx, fmt.Errorf("destroy kind cluster: %w", err)
 		}
 
@@ -152,6 +201,20 @@ func DestroyKindCluster(name string) env.Func {
 	}
 }
 
+// contextKindCluster returns the kind Cluster saved in the context under the name,
+// or a Cluster of that name when there is none
+func contextKindCluster(ctx context.Context, name string) (*kind.Cluster, error) {
+	clusterVal := ctx.Value(kindContextKey(name))
+	if clusterVal == nil {
+		return kind.NewCluster(name), nil
+	}
+	cluster, ok := clusterVal.(*kind.Cluster)
+	if !ok {
+		return nil, fmt.Errorf("destroy kind cluster func: unexpected type for cluster value")
+	}
+	return cluster, nil
+}
+
 // LoadDockerImageToCluster returns an EnvFunc that
 // retrieves a previously saved kind Cluster in the context (using the name), and then loads a docker image
 // from the host into the cluster.
@@ -0,0 +1,108 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envfuncs
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/client-go/kubernetes"
+	log "k8s.io/klog/v2"
+
+	"sigs.k8s.io/e2e-framework/klient/logs"
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+)
+
+// CaptureLogs provides an Environment.FeatureFunc that captures, for the duration of each feature,
+// the logs of the pods in namespace matching the label selector, i.e. the pods of the controller
+// under test. The namespace of the environment is used when namespace is empty. The assessments
+// retrieve the collector by name to assert that lines were logged:
+//
+//	c, _ := logs.CollectorFrom(ctx, "controller")
+//	if _, err := c.WaitFor(regexp.MustCompile("reconciled"), time.Minute); err != nil {
+//		t.Error(err)
+//	}
+//
+// NOTE: this should be used with Environment.BeforeEachFeature, along with StopCapturedLogs
+// in Environment.AfterEachFeature.
+func CaptureLogs(name, namespace, selector string) env.FeatureFunc {
+	return func(ctx context.Context, cfg *envconf.Config, _ *testing.T, _ features.Feature) (context.Context, error) {
+		client, err := cfg.NewClient()
+		if err != nil {
+			return ctx, fmt.Errorf("capture logs func: %w", err)
+		}
+		cs, err := kubernetes.NewForConfig(client.RESTConfig())
+		if err != nil {
+			return ctx, fmt.Errorf("capture logs func: %w", err)
+		}
+		if namespace == "" {
+			namespace = cfg.Namespace()
+		}
+		c, err := logs.Collect(ctx, cs, namespace, selector)
+		if err != nil {
+			return ctx, fmt.Errorf("capture logs func: %w", err)
+		}
+		return logs.WithCollector(ctx, name, c), nil
+	}
+}
+
+// StopCapturedLogs provides an Environment.FeatureFunc that stops the log collectors started by
+// CaptureLogs. When the feature failed, the captured logs are written to the artifacts of the
+// test, in a file named after the collector (i.e. controller.log).
+//
+// NOTE: this should be used with Environment.AfterEachFeature.
+func StopCapturedLogs() env.FeatureFunc {
+	return func(ctx context.Context, cfg *envconf.Config, t *testing.T, _ features.Feature) (context.Context, error) {
+		var dir string
+		for _, name := range logs.CollectorNames(ctx) {
+			c, _ := logs.CollectorFrom(ctx, name)
+			c.Stop()
+			if !t.Failed() {
+				continue
+			}
+			if dir == "" {
+				var err error
+				if dir, err = cfg.ArtifactPath(t.Name()); err != nil {
+					return ctx, fmt.Errorf("stop captured logs func: %w", err)
+				}
+			}
+			if err := saveLogs(cfg, filepath.Join(dir, name+".log"), c); err != nil {
+				return ctx, fmt.Errorf("stop captured logs func: %w", err)
+			}
+		}
+		if dir != "" {
+			log.V(4).Infof("Captured logs of failed test %s written to %s", t.Name(), dir)
+		}
+		return ctx, nil
+	}
+}
+
+func saveLogs(cfg *envconf.Config, path string, c *logs.Collector) error {
+	w, err := cfg.CreateArtifact(path)
+	if err != nil {
+		return err
+	}
+	if _, err := c.WriteTo(w); err != nil {
+		_ = w.Close()
+		return err
+	}
+	return w.Close()
+}
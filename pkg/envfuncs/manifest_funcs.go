@@ -0,0 +1,82 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envfuncs
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"sigs.k8s.io/e2e-framework/klient/decoder"
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/utils"
+)
+
+// ApplyFiles provides an Environment.Func that applies, with server-side apply, the objects
+// decoded from the manifests in fsys matching pattern. The filesystem can be embedded in the
+// test binary with go:embed, so that test binaries shipped as container images carry their
+// manifests, i.e.
+//
+//	//go:embed testdata
+//	var manifests embed.FS
+//	...
+//	testenv.Setup(envfuncs.ApplyFiles(manifests, "testdata/*.yaml"))
+//
+// The decode options can be used to mutate the objects, i.e. with decoder.MutateNamespace.
+func ApplyFiles(fsys fs.FS, pattern string, opts ...decoder.DecodeOption) env.Func {
+	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		client, err := cfg.NewClient()
+		if err != nil {
+			return ctx, fmt.Errorf("apply files func: %w", err)
+		}
+		if _, err := utils.ApplyManifests(ctx, client.Resources(), fsys, pattern, opts...); err != nil {
+			return ctx, fmt.Errorf("apply files func: %w", err)
+		}
+		return ctx, nil
+	}
+}
+
+// ApplyDirFiles provides an Environment.Func that applies the manifests in the dir directory
+// on disk matching pattern. See ApplyFiles.
+func ApplyDirFiles(dir, pattern string, opts ...decoder.DecodeOption) env.Func {
+	return ApplyFiles(os.DirFS(dir), pattern, opts...)
+}
+
+// DeleteFiles provides an Environment.Func that deletes the objects decoded from the manifests
+// in fsys matching pattern, ignoring those already deleted. The deletions are not waited for.
+//
+// NOTE: the decode options should be the ones used with ApplyFiles, so that the same objects are deleted.
+func DeleteFiles(fsys fs.FS, pattern string, opts ...decoder.DecodeOption) env.Func {
+	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		client, err := cfg.NewClient()
+		if err != nil {
+			return ctx, fmt.Errorf("delete files func: %w", err)
+		}
+		if _, err := utils.DeleteManifests(ctx, client.Resources(), fsys, pattern, opts...); err != nil {
+			return ctx, fmt.Errorf("delete files func: %w", err)
+		}
+		return ctx, nil
+	}
+}
+
+// DeleteDirFiles provides an Environment.Func that deletes the objects of the manifests in the
+// dir directory on disk matching pattern. See DeleteFiles.
+func DeleteDirFiles(dir, pattern string, opts ...decoder.DecodeOption) env.Func {
+	return DeleteFiles(os.DirFS(dir), pattern, opts...)
+}
@@ -30,14 +30,15 @@ type namespaceContextKey string
 
 // CreateNamespace provides an Environment.Func that
 // creates a new namespace API object and stores it the context
-// using its name as key.
+// using its name as key. The namespace is labeled with the run
+// labels of the env config, see DeleteResourcesByLabel.
 //
 // NOTE: the returned environment function automatically updates
 // the env config, it receives, with the namespace to make it available
 // for subsequent call.
 func CreateNamespace(name string) env.Func {
 	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
-		namespace := corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		namespace := corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: cfg.RunLabels()}}
 		client, err := cfg.NewClient()
 		if err != nil {
 			return ctx, fmt.Errorf("create namespace func: %w", err)
@@ -52,9 +53,13 @@ func CreateNamespace(name string) env.Func {
 
 // DeleteNamespace provides an Environment.Func that deletes the named
 // namespace. It first searches for the ns in its context, if not found then
-// attempt to retrieve it from the API server. Then deletes it.
+// attempt to retrieve it from the API server. Then deletes it. The namespace
+// is kept when the teardowns are skipped after a failure, see env.TeardownSkipped.
 func DeleteNamespace(name string) env.Func {
 	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		if env.TeardownSkipped(ctx) {
+			return ctx, nil
+		}
 		var namespace *corev1.Namespace
 
 		// attempt to retrieve from context
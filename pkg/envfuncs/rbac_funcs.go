@@ -0,0 +1,163 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envfuncs
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	log "k8s.io/klog/v2"
+	"sigs.k8s.io/e2e-framework/klient"
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+)
+
+type serviceAccountContextKey string
+
+// serviceAccountFixture stores the objects created for a service
+// account along with a client impersonating it.
+type serviceAccountFixture struct {
+	serviceAccount *corev1.ServiceAccount
+	role           k8s.Object
+	binding        k8s.Object
+	client         klient.Client
+}
+
+// CreateServiceAccountWithRole provides an Environment.Func that creates a
+// ServiceAccount in the env config namespace along with a Role, granting the
+// provided rules, bound to it. The created objects and a client impersonating
+// the service account are stored in the context using the name as key.
+// The objects already created are deleted when one of them fails to be created.
+//
+// Use ServiceAccountClient to retrieve the scoped client and
+// DeleteServiceAccount to remove the objects.
+func CreateServiceAccountWithRole(name string, rules ...rbacv1.PolicyRule) env.Func {
+	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		role := &rbacv1.Role{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: cfg.Namespace()},
+			Rules:      rules,
+		}
+		binding := &rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: cfg.Namespace()},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: name},
+		}
+		return createServiceAccount(ctx, cfg, name, role, binding, &binding.Subjects)
+	}
+}
+
+// CreateServiceAccountWithClusterRole provides an Environment.Func that creates a
+// ServiceAccount in the env config namespace along with a ClusterRole, named after
+// the namespace and the name, granting the provided rules, bound to it. The created
+// objects and a client impersonating the service account are stored in the context
+// using the name as key. The objects already created are deleted when one of them
+// fails to be created.
+//
+// Use ServiceAccountClient to retrieve the scoped client and
+// DeleteServiceAccount to remove the objects.
+func CreateServiceAccountWithClusterRole(name string, rules ...rbacv1.PolicyRule) env.Func {
+	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		roleName := fmt.Sprintf("%s-%s", cfg.Namespace(), name)
+		role := &rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{Name: roleName},
+			Rules:      rules,
+		}
+		binding := &rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: roleName},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: roleName},
+		}
+		return createServiceAccount(ctx, cfg, name, role, binding, &binding.Subjects)
+	}
+}
+
+func createServiceAccount(ctx context.Context, cfg *envconf.Config, name string, role, binding k8s.Object, subjects *[]rbacv1.Subject) (context.Context, error) {
+	if cfg.Namespace() == "" {
+		return ctx, fmt.Errorf("create service account func: namespace not set in env config")
+	}
+	client, err := cfg.NewClient()
+	if err != nil {
+		return ctx, fmt.Errorf("create service account func: %w", err)
+	}
+
+	sa := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: cfg.Namespace()}}
+	*subjects = []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: name, Namespace: sa.Namespace}}
+
+	var created []k8s.Object
+	for _, obj := range []k8s.Object{sa, role, binding} {
+		if err := client.Resources().Create(ctx, obj); err != nil {
+			// do not leave a partially created service account behind
+			for i := len(created) - 1; i >= 0; i-- {
+				if err := client.Resources().Delete(ctx, created[i]); err != nil {
+					log.ErrorS(err, "Rolling back service account object failed", "name", created[i].GetName())
+				}
+			}
+			return ctx, fmt.Errorf("create service account func: %w", err)
+		}
+		created = append(created, obj)
+	}
+
+	saClient, err := klient.NewImpersonating(
+		client.RESTConfig(),
+		fmt.Sprintf("system:serviceaccount:%s:%s", sa.Namespace, name),
+		"system:serviceaccounts", fmt.Sprintf("system:serviceaccounts:%s", sa.Namespace),
+	)
+	if err != nil {
+		return ctx, fmt.Errorf("create service account func: %w", err)
+	}
+
+	fixture := &serviceAccountFixture{serviceAccount: sa, role: role, binding: binding, client: saClient}
+	return context.WithValue(ctx, serviceAccountContextKey(name), fixture), nil
+}
+
+// ServiceAccountClient returns the client impersonating the service account
+// previously created, with the given name, by CreateServiceAccountWithRole
+// or CreateServiceAccountWithClusterRole.
+func ServiceAccountClient(ctx context.Context, name string) (klient.Client, error) {
+	fixture, ok := ctx.Value(serviceAccountContextKey(name)).(*serviceAccountFixture)
+	if !ok {
+		return nil, fmt.Errorf("service account %q not found in context", name)
+	}
+	return fixture.client, nil
+}
+
+// DeleteServiceAccount provides an Environment.Func that deletes the ServiceAccount,
+// role, and binding previously created with the given name.
+//
+// NOTE: this should be used in a Environment.Finish step.
+func DeleteServiceAccount(name string) env.Func {
+	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		fixture, ok := ctx.Value(serviceAccountContextKey(name)).(*serviceAccountFixture)
+		if !ok {
+			return ctx, fmt.Errorf("delete service account func: service account %q not found in context", name)
+		}
+
+		client, err := cfg.NewClient()
+		if err != nil {
+			return ctx, fmt.Errorf("delete service account func: %w", err)
+		}
+
+		for _, obj := range []k8s.Object{fixture.binding, fixture.role, fixture.serviceAccount} {
+			if err := client.Resources().Delete(ctx, obj); err != nil {
+				return ctx, fmt.Errorf("delete service account func: %w", err)
+			}
+		}
+		return ctx, nil
+	}
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envfuncs
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/client-go/rest"
+
+	"sigs.k8s.io/e2e-framework/klient"
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+)
+
+// fakeAPIServer serves the discovery of the core and rbac APIs, and accepts the creation
+// and deletion of objects, except for the creation of the resource configured to fail.
+type fakeAPIServer struct {
+	*httptest.Server
+	fail string
+
+	mu       sync.Mutex
+	requests []string
+}
+
+func newFakeAPIServer(fail string) *fakeAPIServer {
+	s := &fakeAPIServer{fail: fail}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+func (s *fakeAPIServer) serve(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	switch r.URL.Path {
+	case "/api":
+		w.Write([]byte(`{"kind":"APIVersions","versions":["v1"]}`))
+		return
+	case "/api/v1":
+		w.Write([]byte(`{"kind":"APIResourceList","groupVersion":"v1","resources":[
+			{"name":"serviceaccounts","namespaced":true,"kind":"ServiceAccount","verbs":["create","delete"]}]}`))
+		return
+	case "/apis":
+		w.Write([]byte(`{"kind":"APIGroupList","groups":[{"name":"rbac.authorization.k8s.io",
+			"versions":[{"groupVersion":"rbac.authorization.k8s.io/v1","version":"v1"}],
+			"preferredVersion":{"groupVersion":"rbac.authorization.k8s.io/v1","version":"v1"}}]}`))
+		return
+	case "/apis/rbac.authorization.k8s.io/v1":
+		w.Write([]byte(`{"kind":"APIResourceList","groupVersion":"rbac.authorization.k8s.io/v1","resources":[
+			{"name":"roles","namespaced":true,"kind":"Role","verbs":["create","delete"]},
+			{"name":"rolebindings","namespaced":true,"kind":"RoleBinding","verbs":["create","delete"]},
+			{"name":"clusterroles","namespaced":false,"kind":"ClusterRole","verbs":["create","delete"]},
+			{"name":"clusterrolebindings","namespaced":false,"kind":"ClusterRoleBinding","verbs":["create","delete"]}]}`))
+		return
+	}
+
+	parts := strings.Split(r.URL.Path, "/")
+	resource := parts[len(parts)-1]
+	if r.Method == http.MethodDelete {
+		resource = parts[len(parts)-2] + "/" + resource
+	}
+	s.mu.Lock()
+	s.requests = append(s.requests, r.Method+" "+resource)
+	s.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodPost && resource == s.fail:
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Forbidden","code":403}`))
+	case r.Method == http.MethodPost:
+		body, _ := ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+		w.Write(body)
+	case r.Method == http.MethodDelete:
+		w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Success"}`))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *fakeAPIServer) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+func TestCreateServiceAccount(t *testing.T) {
+	rules := []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}}}
+	tests := []struct {
+		name      string
+		namespace string
+		create    func(string, ...rbacv1.PolicyRule) env.Func
+		fail      string
+		requests  []string
+		err       string
+	}{
+		{
+			name:      "role",
+			namespace: "ns",
+			create:    CreateServiceAccountWithRole,
+			requests:  []string{"POST serviceaccounts", "POST roles", "POST rolebindings"},
+		},
+		{
+			name:      "cluster role",
+			namespace: "ns",
+			create:    CreateServiceAccountWithClusterRole,
+			requests:  []string{"POST serviceaccounts", "POST clusterroles", "POST clusterrolebindings"},
+		},
+		{
+			name:      "rollback on binding failure",
+			namespace: "ns",
+			create:    CreateServiceAccountWithRole,
+			fail:      "rolebindings",
+			requests:  []string{"POST serviceaccounts", "POST roles", "POST rolebindings", "DELETE roles/sa", "DELETE serviceaccounts/sa"},
+			err:       "create service account func",
+		},
+		{
+			name:      "rollback on cluster role failure",
+			namespace: "ns",
+			create:    CreateServiceAccountWithClusterRole,
+			fail:      "clusterroles",
+			requests:  []string{"POST serviceaccounts", "POST clusterroles", "DELETE serviceaccounts/sa"},
+			err:       "create service account func",
+		},
+		{
+			name:     "no namespace",
+			create:   CreateServiceAccountWithClusterRole,
+			requests: nil,
+			err:      "namespace not set",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newFakeAPIServer(test.fail)
+			defer server.Close()
+			// the fake server echoes the created objects, which must be JSON encoded
+			client, err := klient.New(&rest.Config{Host: server.URL, ContentConfig: rest.ContentConfig{ContentType: "application/json"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg := envconf.New().WithClient(client).WithNamespace(test.namespace)
+
+			ctx, err := test.create("sa", rules...)(context.TODO(), cfg)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Errorf("expected error containing %q, got %v", test.err, err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if requests := server.Requests(); !reflect.DeepEqual(requests, test.requests) {
+				t.Errorf("unexpected requests:\n got: %v\nwant: %v", requests, test.requests)
+			}
+
+			saClient, err := ServiceAccountClient(ctx, "sa")
+			if test.err != "" {
+				if err == nil {
+					t.Error("expected no service account client after a failure")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if user := saClient.RESTConfig().Impersonate.UserName; user != "system:serviceaccount:ns:sa" {
+				t.Errorf("unexpected impersonated user: %s", user)
+			}
+		})
+	}
+}
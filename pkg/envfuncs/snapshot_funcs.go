@@ -0,0 +1,272 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envfuncs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	log "k8s.io/klog/v2"
+
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+)
+
+type snapshotContextKey struct{}
+
+// clusterSnapshot records the uids of the objects present in the cluster
+type clusterSnapshot struct {
+	uids map[apitypes.UID]struct{}
+}
+
+// snapshotObject identifies an object created since the snapshot
+type snapshotObject struct {
+	gvr        schema.GroupVersionResource
+	namespaced bool
+	obj        unstructured.Unstructured
+}
+
+// alwaysIgnored are the resources never deleted by RestoreCluster
+var alwaysIgnored = []schema.GroupResource{
+	{Group: "", Resource: "events"},
+	{Group: "events.k8s.io", Resource: "events"},
+}
+
+// SnapshotCluster provides an Environment.Func that records the objects, namespaced and
+// cluster-scoped, present in the cluster so that RestoreCluster can delete the objects
+// created afterwards. This provides stronger isolation between features than deleting
+// their namespace when they create cluster-scoped objects (i.e. CRDs, ClusterRoles).
+//
+// NOTE: this should be used in Environment.Setup, after the funcs creating the cluster
+// and the resources shared by all the features.
+func SnapshotCluster() env.Func {
+	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		dc, dyn, err := snapshotClients(cfg)
+		if err != nil {
+			return ctx, fmt.Errorf("snapshot cluster func: %w", err)
+		}
+		snapshot, err := takeSnapshot(ctx, dc, dyn)
+		if err != nil {
+			return ctx, fmt.Errorf("snapshot cluster func: %w", err)
+		}
+		return context.WithValue(ctx, snapshotContextKey{}, snapshot), nil
+	}
+}
+
+// RestoreCluster provides an Environment.FeatureFunc that deletes the objects created since
+// SnapshotCluster ran. The objects with owner references are left to the garbage collector,
+// and the objects in new namespaces are deleted along with their namespace. Events, and the
+// resources listed in ignore, are never deleted. The deletions are not waited for.
+//
+// Every object created since the snapshot is deleted, not only the objects of the feature:
+// this includes the objects of the features still running concurrently, and the objects
+// created by the controllers of the cluster, i.e. leases or service account token secrets,
+// which should then be listed in ignore, or restored with RestoreClusterResources instead.
+// To avoid deleting the objects of other features, RestoreCluster fails when the env config
+// enables parallel tests or when the feature is marked parallel; features run with
+// Environment.TestInParallel cannot be detected and must not be used with RestoreCluster.
+//
+// NOTE: this should be used with Environment.AfterEachFeature.
+func RestoreCluster(ignore ...schema.GroupResource) env.FeatureFunc {
+	return restoreCluster(listFilter{ignore: ignore})
+}
+
+// RestoreClusterResources provides an Environment.FeatureFunc that deletes the objects created
+// since SnapshotCluster ran, as RestoreCluster does, but only the objects of the given resources,
+// leaving the objects of the other resources, i.e. the ones created by controllers, untouched.
+//
+// NOTE: this should be used with Environment.AfterEachFeature.
+func RestoreClusterResources(resources ...schema.GroupResource) env.FeatureFunc {
+	if len(resources) == 0 {
+		return func(ctx context.Context, _ *envconf.Config, _ *testing.T, _ features.Feature) (context.Context, error) {
+			return ctx, fmt.Errorf("restore cluster func: no resources to restore")
+		}
+	}
+	return restoreCluster(listFilter{resources: resources})
+}
+
+func restoreCluster(filter listFilter) env.FeatureFunc {
+	return func(ctx context.Context, cfg *envconf.Config, _ *testing.T, feature features.Feature) (context.Context, error) {
+		if cfg.ParallelTestEnabled() || (feature != nil && features.IsParallel(feature)) {
+			return ctx, fmt.Errorf("restore cluster func: cannot restore the cluster while features run in parallel")
+		}
+		snapshot, ok := ctx.Value(snapshotContextKey{}).(*clusterSnapshot)
+		if !ok {
+			return ctx, fmt.Errorf("restore cluster func: no snapshot found in context, use SnapshotCluster in Environment.Setup")
+		}
+		dc, dyn, err := snapshotClients(cfg)
+		if err != nil {
+			return ctx, fmt.Errorf("restore cluster func: %w", err)
+		}
+		if err := restoreSnapshot(ctx, dc, dyn, snapshot, filter); err != nil {
+			return ctx, fmt.Errorf("restore cluster func: %w", err)
+		}
+		return ctx, nil
+	}
+}
+
+func snapshotClients(cfg *envconf.Config) (discovery.DiscoveryInterface, dynamic.Interface, error) {
+	client, err := cfg.NewClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	dc, err := discovery.NewDiscoveryClientForConfig(client.RESTConfig())
+	if err != nil {
+		return nil, nil, err
+	}
+	dyn, err := dynamic.NewForConfig(client.RESTConfig())
+	if err != nil {
+		return nil, nil, err
+	}
+	return dc, dyn, nil
+}
+
+func takeSnapshot(ctx context.Context, dc discovery.DiscoveryInterface, dyn dynamic.Interface) (*clusterSnapshot, error) {
+	snapshot := &clusterSnapshot{uids: make(map[apitypes.UID]struct{})}
+	err := listObjects(ctx, dc, dyn, listFilter{}, func(o snapshotObject) {
+		snapshot.uids[o.obj.GetUID()] = struct{}{}
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.V(4).Infof("Cluster snapshot recorded %d objects", len(snapshot.uids))
+	return snapshot, nil
+}
+
+func restoreSnapshot(ctx context.Context, dc discovery.DiscoveryInterface, dyn dynamic.Interface, snapshot *clusterSnapshot, filter listFilter) error {
+	var created []snapshotObject
+	newNamespaces := make(map[string]bool)
+	err := listObjects(ctx, dc, dyn, filter, func(o snapshotObject) {
+		if _, ok := snapshot.uids[o.obj.GetUID()]; ok || len(o.obj.GetOwnerReferences()) > 0 {
+			return
+		}
+		if o.gvr.Group == "" && o.gvr.Resource == "namespaces" {
+			newNamespaces[o.obj.GetName()] = true
+		}
+		created = append(created, o)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, o := range created {
+		if o.namespaced && newNamespaces[o.obj.GetNamespace()] {
+			continue
+		}
+		log.V(4).Infof("Cluster restore deleting %s %s/%s", o.gvr.GroupResource(), o.obj.GetNamespace(), o.obj.GetName())
+		if err := deleteObject(ctx, dyn, o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteObject deletes the object, leaving its dependents to the garbage collector,
+// and ignores the objects already deleted
+func deleteObject(ctx context.Context, dyn dynamic.Interface, o snapshotObject) error {
+	propagation := metav1.DeletePropagationBackground
+	err := dyn.Resource(o.gvr).Namespace(o.obj.GetNamespace()).Delete(ctx, o.obj.GetName(), metav1.DeleteOptions{PropagationPolicy: &propagation})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("delete %s %s/%s: %w", o.gvr.GroupResource(), o.obj.GetNamespace(), o.obj.GetName(), err)
+	}
+	return nil
+}
+
+// listFilter narrows down the objects listed by listObjects
+type listFilter struct {
+	// ignore are the resources not listed
+	ignore []schema.GroupResource
+	// kinds are the only kinds listed, when set, whatever their version
+	kinds []schema.GroupVersionKind
+	// resources are the only resources listed, when set
+	resources []schema.GroupResource
+	// selector is the label selector of the objects listed
+	selector string
+}
+
+// matchesKind reports whether the resource of the group version is of the kinds of the filter
+func (f listFilter) matchesKind(gv schema.GroupVersion, res metav1.APIResource) bool {
+	if len(f.kinds) == 0 {
+		return true
+	}
+	for _, gvk := range f.kinds {
+		if gvk.GroupKind() == gv.WithKind(res.Kind).GroupKind() {
+			return true
+		}
+	}
+	return false
+}
+
+// listObjects calls fn for each object of the resources that can be listed and deleted,
+// except the ignored ones, and only the allowed ones when the filter has resources. Groups that cannot be discovered, i.e. an unavailable
+// aggregated API, are skipped.
+func listObjects(ctx context.Context, dc discovery.DiscoveryInterface, dyn dynamic.Interface, filter listFilter, fn func(snapshotObject)) error {
+	lists, err := dc.ServerPreferredResources()
+	if err != nil {
+		if !discovery.IsGroupDiscoveryFailedError(err) {
+			return fmt.Errorf("discover resources: %w", err)
+		}
+		log.V(4).Infof("Cluster snapshot skipping groups: %s", err)
+	}
+	lists = discovery.FilteredBy(discovery.SupportsAllVerbs{Verbs: []string{"list", "delete"}}, lists)
+
+	ignored := make(map[schema.GroupResource]bool)
+	for _, gr := range append(alwaysIgnored, filter.ignore...) {
+		ignored[gr] = true
+	}
+	var allowed map[schema.GroupResource]bool
+	if len(filter.resources) > 0 {
+		allowed = make(map[schema.GroupResource]bool)
+		for _, gr := range filter.resources {
+			allowed[gr] = true
+		}
+	}
+
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			return fmt.Errorf("discover resources: %w", err)
+		}
+		for _, res := range list.APIResources {
+			gvr := gv.WithResource(res.Name)
+			if strings.Contains(res.Name, "/") || ignored[gvr.GroupResource()] || !filter.matchesKind(gv, res) {
+				continue
+			}
+			if allowed != nil && !allowed[gvr.GroupResource()] {
+				continue
+			}
+			objs, err := dyn.Resource(gvr).List(ctx, metav1.ListOptions{LabelSelector: filter.selector})
+			if err != nil {
+				return fmt.Errorf("list %s: %w", gvr.GroupResource(), err)
+			}
+			for _, obj := range objs.Items {
+				fn(snapshotObject{gvr: gvr, namespaced: res.Namespaced, obj: obj})
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envfuncs
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+)
+
+// preferredDiscovery serves the resources of the fake as the preferred resources
+type preferredDiscovery struct {
+	*fakediscovery.FakeDiscovery
+}
+
+func (d preferredDiscovery) ServerPreferredResources() ([]*metav1.APIResourceList, error) {
+	return d.Resources, nil
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	verbs := metav1.Verbs{"list", "delete"}
+	dc := preferredDiscovery{&fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: []*metav1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{
+			{Name: "namespaces", Kind: "Namespace", Verbs: verbs},
+			{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: verbs},
+			{Name: "events", Kind: "Event", Namespaced: true, Verbs: verbs},
+		}},
+		{GroupVersion: "rbac.authorization.k8s.io/v1", APIResources: []metav1.APIResource{
+			{Name: "clusterroles", Kind: "ClusterRole", Verbs: verbs},
+		}},
+	}}}}
+
+	object := func(apiVersion, kind, ns, name string, owned bool) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(apiVersion)
+		obj.SetKind(kind)
+		obj.SetNamespace(ns)
+		obj.SetName(name)
+		obj.SetUID(apitypes.UID(ns + "/" + name))
+		if owned {
+			obj.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "v1", Kind: "ConfigMap", Name: "owner", UID: "owner"}})
+		}
+		return obj
+	}
+	listKinds := map[schema.GroupVersionResource]string{
+		{Version: "v1", Resource: "namespaces"}:                                       "NamespaceList",
+		{Version: "v1", Resource: "configmaps"}:                                       "ConfigMapList",
+		{Version: "v1", Resource: "events"}:                                           "EventList",
+		{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles"}: "ClusterRoleList",
+	}
+	dyn := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds,
+		object("v1", "Namespace", "", "default", false),
+		object("v1", "ConfigMap", "default", "shared", false),
+		object("rbac.authorization.k8s.io/v1", "ClusterRole", "", "admin", false),
+	)
+
+	ctx := context.Background()
+	snapshot, err := takeSnapshot(ctx, dc, dyn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshot.uids) != 3 {
+		t.Fatalf("expected 3 objects in snapshot, got %d", len(snapshot.uids))
+	}
+
+	// objects created by a feature
+	tracker := dyn.Tracker()
+	for _, created := range []struct {
+		gvr schema.GroupVersionResource
+		obj *unstructured.Unstructured
+	}{
+		{schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}, object("v1", "Namespace", "", "feature", false)},
+		{schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}, object("v1", "ConfigMap", "feature", "in-new-ns", false)},
+		{schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}, object("v1", "ConfigMap", "default", "leaked", false)},
+		{schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}, object("v1", "ConfigMap", "default", "owned", true)},
+		{schema.GroupVersionResource{Version: "v1", Resource: "events"}, object("v1", "Event", "default", "event", false)},
+		{schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles"}, object("rbac.authorization.k8s.io/v1", "ClusterRole", "", "feature-role", false)},
+	} {
+		if err := tracker.Create(created.gvr, created.obj, created.obj.GetNamespace()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dyn.ClearActions()
+	if err := restoreSnapshot(ctx, dc, dyn, snapshot, listFilter{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var deleted []string
+	for _, action := range dyn.Actions() {
+		if del, ok := action.(clienttesting.DeleteAction); ok {
+			deleted = append(deleted, del.GetResource().Resource+":"+del.GetNamespace()+"/"+del.GetName())
+		}
+	}
+	expected := []string{"namespaces:/feature", "configmaps:default/leaked", "clusterroles:/feature-role"}
+	if len(deleted) != len(expected) {
+		t.Fatalf("expected deletions %v, got %v", expected, deleted)
+	}
+	for i := range expected {
+		if deleted[i] != expected[i] {
+			t.Errorf("expected deletions %v, got %v", expected, deleted)
+			break
+		}
+	}
+}
+
+func TestRestoreSnapshot_Resources(t *testing.T) {
+	verbs := metav1.Verbs{"list", "delete"}
+	dc := preferredDiscovery{&fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: []*metav1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{
+			{Name: "namespaces", Kind: "Namespace", Verbs: verbs},
+			{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: verbs},
+			{Name: "secrets", Kind: "Secret", Namespaced: true, Verbs: verbs},
+		}},
+	}}}}
+	object := func(kind, ns, name string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion("v1")
+		obj.SetKind(kind)
+		obj.SetNamespace(ns)
+		obj.SetName(name)
+		obj.SetUID(apitypes.UID(ns + "/" + name))
+		return obj
+	}
+	dyn := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		{Version: "v1", Resource: "namespaces"}: "NamespaceList",
+		{Version: "v1", Resource: "configmaps"}: "ConfigMapList",
+		{Version: "v1", Resource: "secrets"}:    "SecretList",
+	}, object("Namespace", "", "default"))
+
+	ctx := context.Background()
+	snapshot, err := takeSnapshot(ctx, dc, dyn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker := dyn.Tracker()
+	for _, created := range []struct {
+		resource string
+		obj      *unstructured.Unstructured
+	}{
+		{"namespaces", object("Namespace", "", "feature")},
+		{"configmaps", object("ConfigMap", "feature", "in-new-ns")},
+		{"configmaps", object("ConfigMap", "default", "leaked")},
+		// i.e. created by a controller
+		{"secrets", object("Secret", "default", "token")},
+	} {
+		if err := tracker.Create(schema.GroupVersionResource{Version: "v1", Resource: created.resource}, created.obj, created.obj.GetNamespace()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dyn.ClearActions()
+	if err := restoreSnapshot(ctx, dc, dyn, snapshot, listFilter{resources: []schema.GroupResource{{Resource: "configmaps"}}}); err != nil {
+		t.Fatal(err)
+	}
+	var deleted []string
+	for _, action := range dyn.Actions() {
+		if del, ok := action.(clienttesting.DeleteAction); ok {
+			deleted = append(deleted, del.GetResource().Resource+":"+del.GetNamespace()+"/"+del.GetName())
+		}
+	}
+	expected := []string{"configmaps:default/leaked", "configmaps:feature/in-new-ns"}
+	if !reflect.DeepEqual(deleted, expected) {
+		t.Errorf("expected deletions %v, got %v", expected, deleted)
+	}
+}
+
+func TestRestoreCluster_Parallel(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *envconf.Config
+		feature features.Feature
+	}{
+		{name: "parallel config", cfg: envconf.New().WithParallelTestEnabled(), feature: features.New("f").Feature()},
+		{name: "parallel feature", cfg: envconf.New(), feature: features.New("f").WithParallel().Feature()},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), snapshotContextKey{}, &clusterSnapshot{})
+			_, err := RestoreCluster()(ctx, test.cfg, t, test.feature)
+			if err == nil || !strings.Contains(err.Error(), "parallel") {
+				t.Errorf("expected restore to be refused, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envfuncs
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	log "k8s.io/klog/v2"
+
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+)
+
+// DeleteResourcesByLabel provides an Environment.Func that deletes the objects, namespaced and
+// cluster-scoped, matching the label selector, i.e. to clean up the objects that the feature
+// teardowns left behind when they failed. An empty selector selects the objects labeled with the
+// run labels of the env config (envconf.Config.RunLabels).
+//
+// The sweep only finds the objects carrying the labels: the run labels are set on the namespaces
+// created with CreateNamespace, but the callers must label every other object they create, i.e.
+// with the resources client, with the labels of cfg.RunLabels() for it to be swept. Unlabeled
+// objects are left behind, unless deleted along with their namespace.
+//
+// Only the objects of the given kinds are deleted, or the objects of all the resources that can
+// be listed and deleted when no kind is given. The objects with owner references are left to the
+// garbage collector. The deletion of every object is attempted, and the errors of the deletions
+// that failed are returned together. The deletions are not waited for. Nothing is
+// deleted when the teardowns are skipped after a failure, see env.TeardownSkipped.
+//
+// NOTE: this should be used in Environment.Finish.
+func DeleteResourcesByLabel(selector string, gvks ...schema.GroupVersionKind) env.Func {
+	return func(ctx context.Context, cfg *envconf.Config) (context.Context, error) {
+		if env.TeardownSkipped(ctx) {
+			return ctx, nil
+		}
+		sel := selector
+		if sel == "" {
+			sel = cfg.RunLabelSelector()
+		}
+		dc, dyn, err := snapshotClients(cfg)
+		if err != nil {
+			return ctx, fmt.Errorf("delete resources by label func: %w", err)
+		}
+		if err := sweep(ctx, dc, dyn, sel, gvks); err != nil {
+			return ctx, fmt.Errorf("delete resources by label func: %w", err)
+		}
+		return ctx, nil
+	}
+}
+
+func sweep(ctx context.Context, dc discovery.DiscoveryInterface, dyn dynamic.Interface, selector string, gvks []schema.GroupVersionKind) error {
+	var matched []snapshotObject
+	err := listObjects(ctx, dc, dyn, listFilter{kinds: gvks, selector: selector}, func(o snapshotObject) {
+		if len(o.obj.GetOwnerReferences()) == 0 {
+			matched = append(matched, o)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	log.V(4).Infof("Sweeping %d objects matching %q", len(matched), selector)
+	var errs []error
+	for _, o := range matched {
+		log.V(4).Infof("Sweep deleting %s %s/%s", o.gvr.GroupResource(), o.obj.GetNamespace(), o.obj.GetName())
+		if err := deleteObject(ctx, dyn, o); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envfuncs
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestSweep(t *testing.T) {
+	verbs := metav1.Verbs{"list", "delete"}
+	dc := preferredDiscovery{&fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: []*metav1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{
+			{Name: "namespaces", Kind: "Namespace", Verbs: verbs},
+			{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: verbs},
+		}},
+		{GroupVersion: "rbac.authorization.k8s.io/v1", APIResources: []metav1.APIResource{
+			{Name: "clusterroles", Kind: "ClusterRole", Verbs: verbs},
+		}},
+	}}}}
+
+	object := func(apiVersion, kind, ns, name, run string, owned bool) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(apiVersion)
+		obj.SetKind(kind)
+		obj.SetNamespace(ns)
+		obj.SetName(name)
+		if run != "" {
+			obj.SetLabels(map[string]string{"run": run})
+		}
+		if owned {
+			obj.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "v1", Kind: "ConfigMap", Name: "owner", UID: "owner"}})
+		}
+		return obj
+	}
+	listKinds := map[schema.GroupVersionResource]string{
+		{Version: "v1", Resource: "namespaces"}:                                       "NamespaceList",
+		{Version: "v1", Resource: "configmaps"}:                                       "ConfigMapList",
+		{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles"}: "ClusterRoleList",
+	}
+	newClient := func() *fakedynamic.FakeDynamicClient {
+		return fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds,
+			object("v1", "Namespace", "", "default", "", false),
+			object("v1", "Namespace", "", "feature", "a", false),
+			object("v1", "ConfigMap", "default", "leaked", "a", false),
+			object("v1", "ConfigMap", "default", "owned", "a", true),
+			object("v1", "ConfigMap", "default", "other-run", "b", false),
+			object("rbac.authorization.k8s.io/v1", "ClusterRole", "", "feature-role", "a", false),
+		)
+	}
+	deletions := func(dyn *fakedynamic.FakeDynamicClient) []string {
+		var deleted []string
+		for _, action := range dyn.Actions() {
+			if del, ok := action.(clienttesting.DeleteAction); ok {
+				deleted = append(deleted, del.GetResource().Resource+":"+del.GetNamespace()+"/"+del.GetName())
+			}
+		}
+		return deleted
+	}
+
+	tests := []struct {
+		name     string
+		gvks     []schema.GroupVersionKind
+		expected []string
+	}{
+		{
+			name:     "all kinds",
+			expected: []string{"namespaces:/feature", "configmaps:default/leaked", "clusterroles:/feature-role"},
+		},
+		{
+			name:     "selected kinds",
+			gvks:     []schema.GroupVersionKind{{Group: "rbac.authorization.k8s.io", Version: "v1beta1", Kind: "ClusterRole"}},
+			expected: []string{"clusterroles:/feature-role"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dyn := newClient()
+			if err := sweep(context.Background(), dc, dyn, "run=a", test.gvks); err != nil {
+				t.Fatal(err)
+			}
+			if deleted := deletions(dyn); !reflect.DeepEqual(deleted, test.expected) {
+				t.Errorf("expected deletions %v, got %v", test.expected, deleted)
+			}
+		})
+	}
+	t.Run("failed deletion", func(t *testing.T) {
+		dyn := newClient()
+		dyn.PrependReactor("delete", "namespaces", func(action clienttesting.Action) (bool, runtime.Object, error) {
+			return true, nil, fmt.Errorf("forbidden")
+		})
+		err := sweep(context.Background(), dc, dyn, "run=a", nil)
+		if err == nil || !strings.Contains(err.Error(), "forbidden") {
+			t.Errorf("expected the deletion error, got %v", err)
+		}
+		expected := []string{"namespaces:/feature", "configmaps:default/leaked", "clusterroles:/feature-role"}
+		if deleted := deletions(dyn); !reflect.DeepEqual(deleted, expected) {
+			t.Errorf("expected the deletion of every object to be attempted %v, got %v", expected, deleted)
+		}
+	})
+}
@@ -19,6 +19,9 @@ package features
 import (
 	"fmt"
 
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/e2e-framework/klient/nodes"
 	"sigs.k8s.io/e2e-framework/pkg/internal/types"
 )
 
@@ -38,6 +41,63 @@ func (b *FeatureBuilder) WithLabel(key, value string) *FeatureBuilder {
 	return b
 }
 
+// WithParallel marks the feature as safe to be tested concurrently with
+// other features marked the same way. When tested with env.Test, features
+// not marked as parallel are run sequentially first, then parallel features
+// are run concurrently, similar to tests calling t.Parallel().
+func (b *FeatureBuilder) WithParallel() *FeatureBuilder {
+	b.feat.parallel = true
+	return b
+}
+
+// WithMinKubeVersion declares the minimum Kubernetes version, i.e. 1.23, of the cluster
+// required by the feature. The feature is skipped on clusters running an older version.
+func (b *FeatureBuilder) WithMinKubeVersion(version string) *FeatureBuilder {
+	b.feat.requirements.MinKubeVersion = version
+	return b
+}
+
+// RequireAPIResource declares an API resource that must be served by the cluster
+// for the feature to be tested. The feature is skipped on clusters not serving it.
+func (b *FeatureBuilder) RequireAPIResource(gvr schema.GroupVersionResource) *FeatureBuilder {
+	b.feat.requirements.APIResources = append(b.feat.requirements.APIResources, gvr)
+	return b
+}
+
+// RequireNodeSelector declares labels of the nodes needed by the feature, i.e. to test
+// GPU workloads. The feature is skipped on clusters without a schedulable node with all
+// the labels of the selectors declared. nodes.ScheduleOn schedules the pods of the
+// feature on these nodes.
+func (b *FeatureBuilder) RequireNodeSelector(selector map[string]string) *FeatureBuilder {
+	if b.feat.requirements.NodeSelector == nil {
+		b.feat.requirements.NodeSelector = make(map[string]string, len(selector))
+	}
+	for k, v := range selector {
+		b.feat.requirements.NodeSelector[k] = v
+	}
+	return b
+}
+
+// RequireOS declares the operating system, i.e. windows, of the nodes needed by the
+// feature, see RequireNodeSelector.
+func (b *FeatureBuilder) RequireOS(os string) *FeatureBuilder {
+	return b.RequireNodeSelector(nodes.OSSelector(os))
+}
+
+// RequireArch declares the architecture, i.e. arm64, of the nodes needed by the
+// feature, see RequireNodeSelector.
+func (b *FeatureBuilder) RequireArch(arch string) *FeatureBuilder {
+	return b.RequireNodeSelector(nodes.ArchSelector(arch))
+}
+
+// WithRateLimit overrides, for the feature, the rate limit of the create, apply, and
+// delete operations made with the context of its steps, i.e. to pace a rollout.
+// See envconf.Config.WithRateLimit to limit the operations of all the features.
+func (b *FeatureBuilder) WithRateLimit(qps float32, burst int) *FeatureBuilder {
+	b.feat.rateLimit = types.RateLimit{QPS: qps, Burst: burst}
+	return b
+}
+
 // WithStep adds a new step that will be applied prior to feature test.
 func (b *FeatureBuilder) WithStep(name string, level Level, fn Func) *FeatureBuilder {
 	b.feat.steps = append(b.feat.steps, newStep(name, level, fn))
@@ -71,6 +131,14 @@ func (b *FeatureBuilder) Assess(desc string, fn Func) *FeatureBuilder {
 	return b.WithStep(desc, types.LevelAssess, fn)
 }
 
+// AssessOptional adds an optional assessment step to the feature test. The failures of the
+// assessment are logged and recorded in the result of the run, but do not fail the feature,
+// i.e. for canary checks or newly added probes that are still flaky. See RunOptional.
+func (b *FeatureBuilder) AssessOptional(desc string, fn OptionalFunc) *FeatureBuilder {
+	b.feat.steps = append(b.feat.steps, newOptionalStep(desc, fn))
+	return b
+}
+
 // Feature returns a feature configured by builder.
 func (b *FeatureBuilder) Feature() types.Feature {
 	return b.feat
@@ -18,6 +18,8 @@ package features
 
 import (
 	"context"
+	"runtime"
+	"strings"
 	"testing"
 
 	"sigs.k8s.io/e2e-framework/pkg/envconf"
@@ -252,3 +254,18 @@ func TestFeatureBuilder(t *testing.T) {
 		})
 	}
 }
+
+func TestFeatureBuilder_StepLocation(t *testing.T) {
+	_, file, _, _ := runtime.Caller(0)
+	f := New("test").
+		Setup(func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context { return ctx }).
+		Assess("assessment", func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context { return ctx }).
+		Feature()
+
+	for _, step := range f.Steps() {
+		location := StepLocation(step)
+		if !strings.HasPrefix(location, file+":") {
+			t.Errorf("step %q: unexpected location %q, expected to be in %s", step.Name(), location, file)
+		}
+	}
+}
@@ -17,7 +17,10 @@ limitations under the License.
 package features
 
 import (
+	"fmt"
 	"regexp"
+	"runtime"
+	"strings"
 
 	"sigs.k8s.io/e2e-framework/pkg/internal/types"
 )
@@ -31,9 +34,12 @@ type (
 )
 
 type defaultFeature struct {
-	name   string
-	labels types.Labels
-	steps  []types.Step
+	name         string
+	labels       types.Labels
+	steps        []types.Step
+	parallel     bool
+	requirements types.Requirements
+	rateLimit    types.RateLimit
 }
 
 func newDefaultFeature(name string) *defaultFeature {
@@ -52,17 +58,54 @@ func (f *defaultFeature) Steps() []types.Step {
 	return f.steps
 }
 
+func (f *defaultFeature) Parallel() bool {
+	return f.parallel
+}
+
+func (f *defaultFeature) Requirements() types.Requirements {
+	return f.requirements
+}
+
+// GetRequirements returns the requirements declared by the feature, if any
+func GetRequirements(f Feature) types.Requirements {
+	if rf, ok := f.(types.FeatureWithRequirements); ok {
+		return rf.Requirements()
+	}
+	return types.Requirements{}
+}
+
+func (f *defaultFeature) RateLimit() types.RateLimit {
+	return f.rateLimit
+}
+
+// GetRateLimit returns the rate limit overridden by the feature, if any
+func GetRateLimit(f Feature) types.RateLimit {
+	if rf, ok := f.(types.RateLimitedFeature); ok {
+		return rf.RateLimit()
+	}
+	return types.RateLimit{}
+}
+
+// IsParallel returns true when the feature has been marked
+// as safe to be tested in parallel with other features.
+func IsParallel(f Feature) bool {
+	pf, ok := f.(types.ParallelFeature)
+	return ok && pf.Parallel()
+}
+
 type testStep struct {
-	name  string
-	level Level
-	fn    Func
+	name     string
+	level    Level
+	fn       Func
+	location string
 }
 
 func newStep(name string, level Level, fn Func) *testStep {
 	return &testStep{
-		name:  name,
-		level: level,
-		fn:    fn,
+		name:     name,
+		level:    level,
+		fn:       fn,
+		location: callerLocation(),
 	}
 }
 
@@ -78,6 +121,36 @@ func (s *testStep) Func() Func {
 	return s.fn
 }
 
+func (s *testStep) Location() string {
+	return s.location
+}
+
+// StepLocation returns the file:line where the step was registered,
+// or an empty string if the location is unknown.
+func StepLocation(s Step) string {
+	if ls, ok := s.(types.LocatedStep); ok {
+		return ls.Location()
+	}
+	return ""
+}
+
+// callerLocation returns the file:line of the first caller outside of this package,
+// which is the code registering the step with the builder or a table.
+func callerLocation() string {
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "sigs.k8s.io/e2e-framework/pkg/features.") || strings.HasSuffix(frame.File, "_test.go") {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
 func GetStepsByLevel(steps []types.Step, l types.Level) []types.Step {
 	if steps == nil {
 		return nil
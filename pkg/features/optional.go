@@ -0,0 +1,193 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package features
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"testing"
+
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/internal/types"
+)
+
+// OptionalFunc is the function of an optional assessment, see FeatureBuilder.AssessOptional
+type OptionalFunc = types.OptionalStepFunc
+
+type optionalStep struct {
+	*testStep
+	optionalFn OptionalFunc
+}
+
+func newOptionalStep(name string, fn OptionalFunc) *optionalStep {
+	s := &optionalStep{testStep: newStep(name, types.LevelAssess, nil), optionalFn: fn}
+	s.fn = func(ctx context.Context, t *testing.T, cfg *envconf.Config) context.Context {
+		ctx, failures := RunOptional(ctx, t, cfg, fn)
+		if len(failures) > 0 {
+			t.Logf(`Optional assessment "%s" failed, not failing the test:\n%s`, name, strings.Join(failures, "\n"))
+		}
+		return ctx
+	}
+	return s
+}
+
+func (s *optionalStep) OptionalFunc() OptionalFunc {
+	return s.optionalFn
+}
+
+// IsOptional returns true when the step is an optional assessment
+func IsOptional(s Step) bool {
+	_, ok := s.(types.OptionalStep)
+	return ok
+}
+
+// RunOptional runs the function of an optional assessment with t, intercepting the failures it
+// reports: Error, Fatal, and their variants, are logged and returned instead of failing t, and
+// a panic is returned as a failure. Skip and its variants skip t. The subtests started with t.Run
+// are not optional, as they are given the *testing.T of the subtest.
+func RunOptional(ctx context.Context, t *testing.T, cfg *envconf.Config, fn OptionalFunc) (context.Context, []string) {
+	ot := &optionalT{T: t}
+	result := ctx
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() {
+			if r := recover(); r != nil {
+				ot.record(fmt.Sprintf("panic: %v\n%s", r, debug.Stack()))
+			}
+		}()
+		result = fn(ctx, ot, cfg)
+	}()
+	<-done
+
+	if reason, ok := ot.skipped(); ok && reason != "" {
+		t.Skip(reason)
+	} else if ok {
+		t.SkipNow()
+	}
+	return result, ot.failureMessages()
+}
+
+// optionalT is the testing.TB of an optional assessment, recording its
+// failures rather than reporting them to the test
+type optionalT struct {
+	*testing.T
+
+	mu         sync.Mutex
+	failed     bool
+	failures   []string
+	skip       bool
+	skipReason string
+}
+
+func (o *optionalT) record(msg string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.failed = true
+	if msg != "" {
+		o.failures = append(o.failures, msg)
+	}
+}
+
+func (o *optionalT) failureMessages() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.failed && len(o.failures) == 0 {
+		return []string{"failed"}
+	}
+	return append([]string(nil), o.failures...)
+}
+
+func (o *optionalT) skipped() (string, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.skipReason, o.skip
+}
+
+func (o *optionalT) Fail() {
+	o.record("")
+}
+
+func (o *optionalT) FailNow() {
+	o.record("")
+	runtime.Goexit()
+}
+
+func (o *optionalT) Failed() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.failed
+}
+
+func (o *optionalT) Error(args ...interface{}) {
+	o.T.Helper()
+	o.fail(fmt.Sprintln(args...))
+}
+
+func (o *optionalT) Errorf(format string, args ...interface{}) {
+	o.T.Helper()
+	o.fail(fmt.Sprintf(format, args...))
+}
+
+func (o *optionalT) Fatal(args ...interface{}) {
+	o.T.Helper()
+	o.fail(fmt.Sprintln(args...))
+	runtime.Goexit()
+}
+
+func (o *optionalT) Fatalf(format string, args ...interface{}) {
+	o.T.Helper()
+	o.fail(fmt.Sprintf(format, args...))
+	runtime.Goexit()
+}
+
+// fail logs the failure, at the location of the assessment, and records it
+func (o *optionalT) fail(msg string) {
+	o.T.Helper()
+	msg = strings.TrimSuffix(msg, "\n")
+	o.T.Log("optional failure: " + msg)
+	o.record(msg)
+}
+
+func (o *optionalT) Skip(args ...interface{}) {
+	o.skipWith(strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+}
+
+func (o *optionalT) Skipf(format string, args ...interface{}) {
+	o.skipWith(fmt.Sprintf(format, args...))
+}
+
+func (o *optionalT) SkipNow() {
+	o.skipWith("")
+}
+
+func (o *optionalT) Skipped() bool {
+	_, ok := o.skipped()
+	return ok
+}
+
+// skipWith stops the assessment, which is skipped once returned to the test goroutine
+func (o *optionalT) skipWith(reason string) {
+	o.mu.Lock()
+	o.skip, o.skipReason = true, reason
+	o.mu.Unlock()
+	runtime.Goexit()
+}
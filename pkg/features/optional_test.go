@@ -0,0 +1,89 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package features
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/internal/types"
+)
+
+type optionalKey struct{}
+
+func TestRunOptional(t *testing.T) {
+	t.Run("failures are recorded", func(t *testing.T) {
+		ctx, failures := RunOptional(context.TODO(), t, envconf.New(), func(ctx context.Context, t testing.TB, _ *envconf.Config) context.Context {
+			t.Error("first")
+			if !t.Failed() {
+				t.Error("expected the optional assessment to report its failure")
+			}
+			return context.WithValue(ctx, optionalKey{}, "value")
+		})
+		if t.Failed() {
+			t.Fatal("expected the optional failures not to fail the test")
+		}
+		if expected := []string{"first"}; !reflect.DeepEqual(failures, expected) {
+			t.Errorf("expected failures %v, got %v", expected, failures)
+		}
+		if ctx.Value(optionalKey{}) != "value" {
+			t.Error("expected the context returned by the assessment")
+		}
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		ctx := context.TODO()
+		result, failures := RunOptional(ctx, t, envconf.New(), func(ctx context.Context, t testing.TB, _ *envconf.Config) context.Context {
+			panic("boom")
+		})
+		if len(failures) != 1 || !strings.HasPrefix(failures[0], "panic: boom") {
+			t.Errorf("expected the panic to be recorded, got %v", failures)
+		}
+		if result != ctx {
+			t.Error("expected the context to be returned unchanged after a panic")
+		}
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		defer func() {
+			if !t.Skipped() {
+				t.Error("expected the test to be skipped")
+			}
+		}()
+		RunOptional(context.TODO(), t, envconf.New(), func(ctx context.Context, t testing.TB, _ *envconf.Config) context.Context {
+			t.Skip("not applicable")
+			return ctx
+		})
+		t.Error("expected the test to stop on skip")
+	})
+}
+
+func TestAssessOptional(t *testing.T) {
+	f := New("feature").AssessOptional("probe", func(ctx context.Context, t testing.TB, _ *envconf.Config) context.Context {
+		return ctx
+	}).Feature()
+	steps := f.Steps()
+	if len(steps) != 1 || !IsOptional(steps[0]) || steps[0].Level() != types.LevelAssess {
+		t.Fatalf("expected an optional assessment, got %v", steps)
+	}
+	if location := StepLocation(steps[0]); !strings.Contains(location, "optional_test.go") {
+		t.Errorf("expected the location of the assessment, got %q", location)
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package features
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/e2e-framework/pkg/internal/types"
+)
+
+// Suite groups related features, tested together with env.TestSuite
+type Suite = types.Suite
+
+type defaultSuite struct {
+	name     string
+	labels   types.Labels
+	features []types.Feature
+	steps    []types.Step
+}
+
+func (s *defaultSuite) Name() string {
+	return s.name
+}
+
+func (s *defaultSuite) Labels() types.Labels {
+	return s.labels
+}
+
+func (s *defaultSuite) Features() []types.Feature {
+	return s.features
+}
+
+func (s *defaultSuite) Steps() []types.Step {
+	return s.steps
+}
+
+// SuiteBuilder is a type to define a suite of features
+type SuiteBuilder struct {
+	suite *defaultSuite
+}
+
+// NewSuite creates a builder of a suite of features
+func NewSuite(name string) *SuiteBuilder {
+	return &SuiteBuilder{suite: &defaultSuite{name: name, labels: make(types.Labels)}}
+}
+
+// WithLabel adds a label key/value pair shared by the features of the suite.
+// A feature label with the same key takes precedence.
+func (b *SuiteBuilder) WithLabel(key, value string) *SuiteBuilder {
+	b.suite.labels[key] = value
+	return b
+}
+
+// WithFeatures adds features to the suite
+func (b *SuiteBuilder) WithFeatures(features ...Feature) *SuiteBuilder {
+	b.suite.features = append(b.suite.features, features...)
+	return b
+}
+
+// Setup adds a setup step run once before the features of the suite
+func (b *SuiteBuilder) Setup(fn Func) *SuiteBuilder {
+	return b.WithSetup(fmt.Sprintf("%s-setup", b.suite.name), fn)
+}
+
+// WithSetup adds a named setup step run once before the features of the suite
+func (b *SuiteBuilder) WithSetup(name string, fn Func) *SuiteBuilder {
+	b.suite.steps = append(b.suite.steps, newStep(name, types.LevelSetup, fn))
+	return b
+}
+
+// Teardown adds a teardown step run once after the features of the suite,
+// even when a setup step or a feature failed
+func (b *SuiteBuilder) Teardown(fn Func) *SuiteBuilder {
+	return b.WithTeardown(fmt.Sprintf("%s-teardown", b.suite.name), fn)
+}
+
+// WithTeardown adds a named teardown step run once after the features of the suite
+func (b *SuiteBuilder) WithTeardown(name string, fn Func) *SuiteBuilder {
+	b.suite.steps = append(b.suite.steps, newStep(name, types.LevelTeardown, fn))
+	return b
+}
+
+// Suite returns the suite configured by the builder
+func (b *SuiteBuilder) Suite() types.Suite {
+	return b.suite
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flags
+
+import (
+	"flag"
+	"fmt"
+	"sync"
+)
+
+// customFlag is a flag declared by test authors with Define
+type customFlag struct {
+	name     string
+	defValue string
+	usage    string
+}
+
+// customFlags is the registry of the flags declared with Define
+var (
+	customMu    sync.Mutex
+	customFlags = make(map[string]customFlag)
+)
+
+// Define declares a custom flag, i.e. --controller-image, that is parsed alongside
+// the framework flags. Its value is retrieved with EnvFlags.Custom or, for an
+// environment configuration created from flags, with envconf.Config.Flag.
+//
+// Define must be called before the flags are parsed, typically in TestMain before
+// calling envconf.NewFromFlags. It panics if a flag with the same name is already
+// defined, as the flag package does.
+func Define(name, defValue, usage string) {
+	register()
+
+	customMu.Lock()
+	defer customMu.Unlock()
+	if _, ok := customFlags[name]; ok || flag.CommandLine.Lookup(name) != nil {
+		panic(fmt.Sprintf("flags: flag %q already defined", name))
+	}
+	customFlags[name] = customFlag{name: name, defValue: defValue, usage: usage}
+	// the flag is also defined on the command line, so that it is accepted when the
+	// test binary parses its arguments
+	flag.CommandLine.String(name, defValue, usage)
+}
+
+// Custom returns the value of the custom flag declared with Define, or its default
+// value when not set. The boolean is false if the flag was not declared.
+func (f *EnvFlags) Custom(name string) (string, bool) {
+	value, ok := f.custom[name]
+	return value, ok
+}
+
+// CustomFlags returns the values of all the custom flags declared with Define
+func (f *EnvFlags) CustomFlags() map[string]string {
+	values := make(map[string]string, len(f.custom))
+	for name, value := range f.custom {
+		values[name] = value
+	}
+	return values
+}
+
+// CustomDefault returns the default value of the custom flag declared with Define
+func CustomDefault(name string) string {
+	customMu.Lock()
+	defer customMu.Unlock()
+	return customFlags[name].defValue
+}
+
+// defineCustomFlags defines the custom flags on fs, storing their values in f
+func defineCustomFlags(fs *flag.FlagSet, f *EnvFlags) {
+	customMu.Lock()
+	defer customMu.Unlock()
+	f.custom = make(map[string]string, len(customFlags))
+	for name, cf := range customFlags {
+		f.custom[name] = cf.defValue
+		fs.Var(&customValue{values: f.custom, name: name}, name, cf.usage)
+	}
+}
+
+// customValue stores the value of a custom flag in the map of the parsed flags
+type customValue struct {
+	values map[string]string
+	name   string
+}
+
+func (v *customValue) String() string {
+	if v.values == nil {
+		return ""
+	}
+	return v.values[v.name]
+}
+
+func (v *customValue) Set(s string) error {
+	v.values[v.name] = s
+	return nil
+}
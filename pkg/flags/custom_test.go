@@ -0,0 +1,59 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flags
+
+import (
+	"flag"
+	"testing"
+)
+
+func TestDefine(t *testing.T) {
+	Define("controller-image", "controller:latest", "Image of the controller under test")
+	Define("cloud-region", "", "Cloud region of the cluster")
+
+	if flag.CommandLine.Lookup("controller-image") == nil {
+		t.Error("expected custom flag to be accepted on the command line")
+	}
+
+	parsed, err := ParseArgs([]string{"--cloud-region", "us-east-1", "--feature", "beta"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if region, ok := parsed.Custom("cloud-region"); !ok || region != "us-east-1" {
+		t.Errorf("unexpected cloud-region: %q, %t", region, ok)
+	}
+	if image, _ := parsed.Custom("controller-image"); image != "controller:latest" {
+		t.Errorf("expected default controller-image, got: %q", image)
+	}
+	if _, ok := parsed.Custom("unknown"); ok {
+		t.Error("expected unknown custom flag not to be found")
+	}
+	if parsed.Feature() != "beta" {
+		t.Errorf("unexpected feature: %s", parsed.Feature())
+	}
+
+	for _, name := range []string{"cloud-region", "namespace"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected redefining flag %s to panic", name)
+				}
+			}()
+			Define(name, "", "")
+		}()
+	}
+}
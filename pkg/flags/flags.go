@@ -20,7 +20,9 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
 
 	"k8s.io/klog/v2"
 )
@@ -35,6 +37,14 @@ const (
 	flagSkipFeatureName    = "skip-features"
 	flagSkipAssessmentName = "skip-assessment"
 	flagParallelTestsName  = "parallel"
+	flagArtifactsName      = "artifacts"
+	flagStateFileName      = "state-file"
+	flagResumeName         = "resume"
+	flagTraceEndpointName  = "trace-endpoint"
+	flagLabelFilterName    = "label-filter"
+	flagSkipTeardownName   = "skip-teardown-on-failure"
+	flagRandomSeedName     = "random-seed"
+	flagConfigName         = "config"
 )
 
 // Supported flag definitions
@@ -75,6 +85,38 @@ var (
 		Name:  flagParallelTestsName,
 		Usage: "Run test features in parallel",
 	}
+	artifactsFlag = flag.Flag{
+		Name:  flagArtifactsName,
+		Usage: "Directory where test artifacts (logs, dumps, reports) are written (optional)",
+	}
+	stateFileFlag = flag.Flag{
+		Name:  flagStateFileName,
+		Usage: "File recording the features that passed, to resume the run (optional)",
+	}
+	resumeFlag = flag.Flag{
+		Name:  flagResumeName,
+		Usage: "Skip the features recorded as passed in the state file",
+	}
+	labelFilterFlag = flag.Flag{
+		Name:  flagLabelFilterName,
+		Usage: "Boolean expression to filter features by labels, i.e. \"conformance && !slow || gpu\"",
+	}
+	skipTeardownFlag = flag.Flag{
+		Name:  flagSkipTeardownName,
+		Usage: "Skip the feature teardowns and Finish funcs once a test failed, keeping the cluster for debugging",
+	}
+	randomSeedFlag = flag.Flag{
+		Name:  flagRandomSeedName,
+		Usage: "Seed of the random names generated by the environment config, to reproduce a run (optional)",
+	}
+	configFlag = flag.Flag{
+		Name:  flagConfigName,
+		Usage: "YAML or JSON file of the test run configuration, overridden by the flags that are set (optional)",
+	}
+	traceEndpointFlag = flag.Flag{
+		Name:  flagTraceEndpointName,
+		Usage: "OTLP/HTTP collector endpoint the test spans are exported to, i.e. http://localhost:4318 (optional)",
+	}
 )
 
 // EnvFlags surfaces all resolved flag values for the testing framework
@@ -88,6 +130,17 @@ type EnvFlags struct {
 	skipFeatures    string
 	skipAssessments string
 	parallelTests   bool
+	artifacts       string
+	verbosity       int
+	stateFile       string
+	resume          bool
+	traceEndpoint   string
+	custom          map[string]string
+	labelFilter     string
+	skipTeardown    bool
+	randomSeed      int64
+	config          string
+	set             []string
 }
 
 // Feature returns value for `-feature` flag
@@ -131,81 +184,212 @@ func (f *EnvFlags) Parallel() bool {
 	return f.parallelTests
 }
 
+// Artifacts returns an optional path for the artifacts directory
+func (f *EnvFlags) Artifacts() string {
+	return f.artifacts
+}
+
+// StateFile returns an optional path for the run state file
+func (f *EnvFlags) StateFile() string {
+	return f.stateFile
+}
+
+// Resume returns whether to resume the run recorded in the state file
+func (f *EnvFlags) Resume() bool {
+	return f.resume
+}
+
+// LabelFilter returns an optional boolean expression to filter features by labels
+func (f *EnvFlags) LabelFilter() string {
+	return f.labelFilter
+}
+
+// SkipTeardownOnFailure returns whether to skip the teardowns once a test failed
+func (f *EnvFlags) SkipTeardownOnFailure() bool {
+	return f.skipTeardown
+}
+
+// RandomSeed returns an optional seed of the random names, 0 when not set
+func (f *EnvFlags) RandomSeed() int64 {
+	return f.randomSeed
+}
+
+// Config returns an optional path for the run configuration file
+func (f *EnvFlags) Config() string {
+	return f.config
+}
+
+// TraceEndpoint returns an optional OTLP/HTTP collector endpoint for the test spans
+func (f *EnvFlags) TraceEndpoint() string {
+	return f.traceEndpoint
+}
+
+// Visit calls fn with the name of each flag set on the command line, in
+// lexicographical order, including the custom flags and the flags set to
+// their default value
+func (f *EnvFlags) Visit(fn func(name string)) {
+	for _, name := range f.set {
+		fn(name)
+	}
+}
+
+// Verbosity returns the value of the klog `-v` flag
+func (f *EnvFlags) Verbosity() int {
+	return f.verbosity
+}
+
 // Parse parses defined CLI args os.Args[1:]
 func Parse() (*EnvFlags, error) {
 	return ParseArgs(os.Args[1:])
 }
 
-// ParseArgs parses the specified args from global flag.CommandLine
-// and returns a set of environment flag values.
-func ParseArgs(args []string) (*EnvFlags, error) {
-	var (
-		feature        string
-		assess         string
-		namespace      string
-		kubeconfig     string
-		skipFeature    string
-		skipAssessment string
-		parallelTests  bool
-	)
+// registerOnce guards the registration of the framework and klog flags
+// with the global flag.CommandLine
+var registerOnce sync.Once
+
+// register adds the framework flags, if not already defined, and the klog flags
+// to the global flag.CommandLine so that they are accepted when the test binary
+// parses its command-line arguments.
+func register() {
+	registerOnce.Do(func() {
+		defineFlags(flag.CommandLine, &EnvFlags{labels: make(LabelsMap), skiplabels: make(LabelsMap)})
+		// Enable klog/v2 flag integration
+		klog.InitFlags(nil)
+	})
+}
+
+// defineFlags defines the framework flags, that are not already defined,
+// on fs using the fields of f as storage.
+func defineFlags(fs *flag.FlagSet, f *EnvFlags) {
+	if fs.Lookup(featureFlag.Name) == nil {
+		fs.StringVar(&f.feature, featureFlag.Name, featureFlag.DefValue, featureFlag.Usage)
+	}
+
+	if fs.Lookup(assessFlag.Name) == nil {
+		fs.StringVar(&f.assess, assessFlag.Name, assessFlag.DefValue, assessFlag.Usage)
+	}
 
-	labels := make(LabelsMap)
-	skipLabels := make(LabelsMap)
+	if fs.Lookup(kubecfgFlag.Name) == nil {
+		fs.StringVar(&f.kubeconfig, kubecfgFlag.Name, kubecfgFlag.DefValue, kubecfgFlag.Usage)
+	}
+
+	if fs.Lookup(kubeNSFlag.Name) == nil {
+		fs.StringVar(&f.namespace, kubeNSFlag.Name, kubeNSFlag.DefValue, kubeNSFlag.Usage)
+	}
 
-	if flag.Lookup(featureFlag.Name) == nil {
-		flag.StringVar(&feature, featureFlag.Name, featureFlag.DefValue, featureFlag.Usage)
+	if fs.Lookup(labelsFlag.Name) == nil {
+		fs.Var(&f.labels, labelsFlag.Name, labelsFlag.Usage)
 	}
 
-	if flag.Lookup(assessFlag.Name) == nil {
-		flag.StringVar(&assess, assessFlag.Name, assessFlag.DefValue, assessFlag.Usage)
+	if fs.Lookup(skipLabelsFlag.Name) == nil {
+		fs.Var(&f.skiplabels, skipLabelsFlag.Name, skipLabelsFlag.Usage)
 	}
 
-	if flag.Lookup(kubecfgFlag.Name) == nil {
-		flag.StringVar(&kubeconfig, kubecfgFlag.Name, kubecfgFlag.DefValue, kubecfgFlag.Usage)
+	if fs.Lookup(skipAssessmentFlag.Name) == nil {
+		fs.StringVar(&f.skipAssessments, skipAssessmentFlag.Name, skipAssessmentFlag.DefValue, skipAssessmentFlag.Usage)
 	}
 
-	if flag.Lookup(kubeNSFlag.Name) == nil {
-		flag.StringVar(&namespace, kubeNSFlag.Name, kubeNSFlag.DefValue, kubeNSFlag.Usage)
+	if fs.Lookup(skipFeatureFlag.Name) == nil {
+		fs.StringVar(&f.skipFeatures, skipFeatureFlag.Name, skipFeatureFlag.DefValue, skipFeatureFlag.Usage)
 	}
 
-	if flag.Lookup(labelsFlag.Name) == nil {
-		flag.Var(&labels, labelsFlag.Name, labelsFlag.Usage)
+	if fs.Lookup(parallelTestsFlag.Name) == nil {
+		fs.BoolVar(&f.parallelTests, parallelTestsFlag.Name, false, parallelTestsFlag.Usage)
 	}
 
-	if flag.Lookup(skipLabelsFlag.Name) == nil {
-		flag.Var(&skipLabels, skipLabelsFlag.Name, skipLabelsFlag.Usage)
+	if fs.Lookup(artifactsFlag.Name) == nil {
+		fs.StringVar(&f.artifacts, artifactsFlag.Name, artifactsFlag.DefValue, artifactsFlag.Usage)
 	}
 
-	if flag.Lookup(skipAssessmentFlag.Name) == nil {
-		flag.StringVar(&skipAssessment, skipAssessmentFlag.Name, skipAssessmentFlag.DefValue, skipAssessmentFlag.Usage)
+	if fs.Lookup(stateFileFlag.Name) == nil {
+		fs.StringVar(&f.stateFile, stateFileFlag.Name, stateFileFlag.DefValue, stateFileFlag.Usage)
 	}
 
-	if flag.Lookup(skipFeatureFlag.Name) == nil {
-		flag.StringVar(&skipFeature, skipFeatureFlag.Name, skipFeatureFlag.DefValue, skipFeatureFlag.Usage)
+	if fs.Lookup(resumeFlag.Name) == nil {
+		fs.BoolVar(&f.resume, resumeFlag.Name, false, resumeFlag.Usage)
 	}
 
-	if flag.Lookup(parallelTestsFlag.Name) == nil {
-		flag.BoolVar(&parallelTests, parallelTestsFlag.Name, false, parallelTestsFlag.Usage)
+	if fs.Lookup(labelFilterFlag.Name) == nil {
+		fs.StringVar(&f.labelFilter, labelFilterFlag.Name, labelFilterFlag.DefValue, labelFilterFlag.Usage)
 	}
 
-	// Enable klog/v2 flag integration
-	klog.InitFlags(nil)
+	if fs.Lookup(skipTeardownFlag.Name) == nil {
+		fs.BoolVar(&f.skipTeardown, skipTeardownFlag.Name, false, skipTeardownFlag.Usage)
+	}
 
-	if err := flag.CommandLine.Parse(args); err != nil {
+	if fs.Lookup(randomSeedFlag.Name) == nil {
+		fs.Int64Var(&f.randomSeed, randomSeedFlag.Name, 0, randomSeedFlag.Usage)
+	}
+
+	if fs.Lookup(configFlag.Name) == nil {
+		fs.StringVar(&f.config, configFlag.Name, configFlag.DefValue, configFlag.Usage)
+	}
+
+	if fs.Lookup(traceEndpointFlag.Name) == nil {
+		fs.StringVar(&f.traceEndpoint, traceEndpointFlag.Name, traceEndpointFlag.DefValue, traceEndpointFlag.Usage)
+	}
+}
+
+// globalFlags are the framework flags whose parsed values are also set on the
+// global flag.CommandLine, for the helpers resolving them without an EnvFlags,
+// i.e. conf.ResolveKubeConfigFile for --kubeconfig.
+var globalFlags = []string{flagKubecofigName}
+
+// syncMu serializes the updates of the global flag values
+var syncMu sync.Mutex
+
+// ParseArgs parses the specified args and returns a set of environment flag values.
+//
+// The framework flags are parsed using a new flag set for each call, so that
+// ParseArgs can be called multiple times (i.e. by several environments in the
+// same process) and each call returns its own values. Flags registered with the
+// global flag.CommandLine, such as testing or klog flags, are still accepted.
+// The --kubeconfig value, when set, is also set on flag.CommandLine.
+func ParseArgs(args []string) (*EnvFlags, error) {
+	register()
+
+	envFlags := &EnvFlags{labels: make(LabelsMap), skiplabels: make(LabelsMap)}
+	fs := flag.NewFlagSet(flag.CommandLine.Name(), flag.ContinueOnError)
+	defineFlags(fs, envFlags)
+	defineCustomFlags(fs, envFlags)
+	flag.CommandLine.VisitAll(func(f *flag.Flag) {
+		if fs.Lookup(f.Name) == nil {
+			fs.Var(f.Value, f.Name, f.Usage)
+		}
+	})
+
+	if err := fs.Parse(args); err != nil {
+		return nil, fmt.Errorf("flags parsing: %w", err)
+	}
+	fs.Visit(func(f *flag.Flag) {
+		envFlags.set = append(envFlags.set, f.Name)
+	})
+	if v := fs.Lookup("v"); v != nil {
+		envFlags.verbosity, _ = strconv.Atoi(v.Value.String())
+	}
+	if err := syncGlobalFlags(fs); err != nil {
 		return nil, fmt.Errorf("flags parsing: %w", err)
 	}
 
-	return &EnvFlags{
-		feature:         feature,
-		assess:          assess,
-		labels:          labels,
-		namespace:       namespace,
-		kubeconfig:      kubeconfig,
-		skiplabels:      skipLabels,
-		skipFeatures:    skipFeature,
-		skipAssessments: skipAssessment,
-		parallelTests:   parallelTests,
-	}, nil
+	return envFlags, nil
+}
+
+// syncGlobalFlags sets the values of the globalFlags parsed in fs on flag.CommandLine
+func syncGlobalFlags(fs *flag.FlagSet) error {
+	syncMu.Lock()
+	defer syncMu.Unlock()
+	var err error
+	fs.Visit(func(f *flag.Flag) {
+		for _, name := range globalFlags {
+			if f.Name != name {
+				continue
+			}
+			if global := flag.CommandLine.Lookup(name); global != nil && err == nil {
+				err = global.Value.Set(f.Value.String())
+			}
+		}
+	})
+	return err
 }
 
 type LabelsMap map[string]string
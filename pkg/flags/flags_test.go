@@ -17,7 +17,11 @@ limitations under the License.
 package flags
 
 import (
+	"flag"
+	"reflect"
 	"testing"
+
+	"sigs.k8s.io/e2e-framework/klient/conf"
 )
 
 func TestParseFlags(t *testing.T) {
@@ -28,8 +32,8 @@ func TestParseFlags(t *testing.T) {
 	}{
 		{
 			name:  "with all",
-			args:  []string{"-assess", "volume test", "--feature", "beta", "--labels", "k0=v0, k1=v1, k2=v2", "--skip-labels", "k0=v0, k1=v1", "-skip-features", "networking", "-skip-assessment", "volume test", "-parallel"},
-			flags: &EnvFlags{assess: "volume test", feature: "beta", labels: LabelsMap{"k0": "v0", "k1": "v1", "k2": "v2"}, skiplabels: LabelsMap{"k0": "v0", "k1": "v1"}, skipFeatures: "networking", skipAssessments: "volume test"},
+			args:  []string{"-assess", "volume test", "--feature", "beta", "--labels", "k0=v0, k1=v1, k2=v2", "--skip-labels", "k0=v0, k1=v1", "-skip-features", "networking", "-skip-assessment", "volume test", "-parallel", "--artifacts", "/tmp/artifacts", "--v", "2", "--state-file", "/tmp/state", "--resume", "--trace-endpoint", "http://localhost:4318", "--label-filter", "conformance && !slow", "--skip-teardown-on-failure", "--random-seed", "42", "--config", "/tmp/e2e.yaml"},
+			flags: &EnvFlags{assess: "volume test", feature: "beta", labels: LabelsMap{"k0": "v0", "k1": "v1", "k2": "v2"}, skiplabels: LabelsMap{"k0": "v0", "k1": "v1"}, skipFeatures: "networking", skipAssessments: "volume test", artifacts: "/tmp/artifacts", verbosity: 2, stateFile: "/tmp/state", resume: true, traceEndpoint: "http://localhost:4318", labelFilter: "conformance && !slow", skipTeardown: true, randomSeed: 42, config: "/tmp/e2e.yaml"},
 		},
 	}
 
@@ -67,9 +71,91 @@ func TestParseFlags(t *testing.T) {
 				t.Errorf("unmatched assessment name for skip: %s", testFlags.SkipFeatures())
 			}
 
+			if testFlags.Artifacts() != test.flags.Artifacts() {
+				t.Errorf("unmatched artifacts directory: %s", testFlags.Artifacts())
+			}
+
+			if testFlags.StateFile() != test.flags.StateFile() || testFlags.Resume() != test.flags.Resume() {
+				t.Errorf("unmatched state file %s or resume %t", testFlags.StateFile(), testFlags.Resume())
+			}
+			if testFlags.TraceEndpoint() != test.flags.TraceEndpoint() {
+				t.Errorf("unmatched trace endpoint %s", testFlags.TraceEndpoint())
+			}
+			if testFlags.LabelFilter() != test.flags.LabelFilter() {
+				t.Errorf("unmatched label filter %s", testFlags.LabelFilter())
+			}
+			if testFlags.SkipTeardownOnFailure() != test.flags.SkipTeardownOnFailure() {
+				t.Errorf("unmatched skip teardown on failure %t", testFlags.SkipTeardownOnFailure())
+			}
+			if testFlags.RandomSeed() != test.flags.RandomSeed() {
+				t.Errorf("unmatched random seed %d", testFlags.RandomSeed())
+			}
+			if testFlags.Config() != test.flags.Config() {
+				t.Errorf("unmatched config file %s", testFlags.Config())
+			}
+
+			if testFlags.Verbosity() != test.flags.Verbosity() {
+				t.Errorf("unmatched verbosity: %d", testFlags.Verbosity())
+			}
+
 			if !testFlags.Parallel() {
 				t.Errorf("unmatched flag parsed. Expected paralle to be true.")
 			}
 		})
 	}
 }
+
+func TestParseArgs_Repeated(t *testing.T) {
+	first, err := ParseArgs([]string{"-feature", "first", "-labels", "env=one"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := ParseArgs([]string{"-namespace", "second"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first.Feature() != "first" || first.Labels()["env"] != "one" {
+		t.Errorf("unexpected values from first parse: feature=%s labels=%v", first.Feature(), first.Labels())
+	}
+	if second.Feature() != "" || len(second.Labels()) != 0 {
+		t.Errorf("values from first parse leaked into second: feature=%s labels=%v", second.Feature(), second.Labels())
+	}
+	if second.Namespace() != "second" {
+		t.Errorf("unexpected namespace from second parse: %s", second.Namespace())
+	}
+}
+
+func TestParseArgs_GlobalKubeconfig(t *testing.T) {
+	t.Setenv("KUBECONFIG", "")
+	if _, err := ParseArgs([]string{"-kubeconfig", "/tmp/flag-kubeconfig"}); err != nil {
+		t.Fatal(err)
+	}
+	defer flag.Set("kubeconfig", "")
+
+	if kubeconfig := conf.ResolveKubeConfigFile(); kubeconfig != "/tmp/flag-kubeconfig" {
+		t.Errorf("expected --kubeconfig to be resolved, got %q", kubeconfig)
+	}
+
+	// a parse without the flag keeps the value, as flag.Parse would
+	if _, err := ParseArgs([]string{"-namespace", "other"}); err != nil {
+		t.Fatal(err)
+	}
+	if kubeconfig := conf.ResolveKubeConfigFile(); kubeconfig != "/tmp/flag-kubeconfig" {
+		t.Errorf("unexpected kubeconfig after second parse: %q", kubeconfig)
+	}
+}
+
+func TestParseArgs_Visit(t *testing.T) {
+	envFlags, err := ParseArgs([]string{"--parallel=false", "--namespace", "e2e"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var set []string
+	envFlags.Visit(func(name string) {
+		set = append(set, name)
+	})
+	if !reflect.DeepEqual(set, []string{"namespace", "parallel"}) {
+		t.Errorf("unexpected flags set: %v", set)
+	}
+}
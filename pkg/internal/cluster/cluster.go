@@ -0,0 +1,39 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cluster holds the checks of the cluster shared by the environment
+// and the env funcs.
+package cluster
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// OlderThan returns whether the server version, i.e. the GitVersion v1.23.1+k3s1
+// reported by the API server, is older than the minimum version, i.e. 1.22.
+func OlderThan(serverVersion, minVersion string) (bool, error) {
+	minimum, err := version.ParseGeneric(minVersion)
+	if err != nil {
+		return false, fmt.Errorf("invalid minimum version %s: %w", minVersion, err)
+	}
+	server, err := version.ParseGeneric(serverVersion)
+	if err != nil {
+		return false, fmt.Errorf("invalid server version %s: %w", serverVersion, err)
+	}
+	return server.LessThan(minimum), nil
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import "testing"
+
+func TestOlderThan(t *testing.T) {
+	tests := []struct {
+		server string
+		min    string
+		older  bool
+		err    bool
+	}{
+		{server: "v1.23.1", min: "1.22", older: false},
+		{server: "v1.21.14+k3s1", min: "1.22", older: true},
+		{server: "v1.22.0-gke.100", min: "1.22.0", older: false},
+		{server: "v1.23.1", min: "latest", err: true},
+		{server: "unknown", min: "1.22", err: true},
+	}
+	for _, test := range tests {
+		older, err := OlderThan(test.server, test.min)
+		if test.err {
+			if err == nil {
+				t.Errorf("expected an error for %s and %s", test.server, test.min)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if older != test.older {
+			t.Errorf("expected %s older than %s to be %t", test.server, test.min, test.older)
+		}
+	}
+}
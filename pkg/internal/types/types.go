@@ -18,8 +18,11 @@ package types
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
 	"sigs.k8s.io/e2e-framework/pkg/envconf"
 )
 
@@ -43,6 +46,13 @@ type FeatureEnvFunc func(context.Context, *envconf.Config, *testing.T, Feature)
 // to caller. Meant for use with before/after test hooks.
 type TestEnvFunc func(context.Context, *envconf.Config, *testing.T) (context.Context, error)
 
+// AssessmentEnvFunc represents a user-defined operation that
+// can be used to customized the behavior of the
+// environment. Changes to context are expected to surface
+// to caller. Meant for use with before/after assessment hooks,
+// it receives the name of the assessment.
+type AssessmentEnvFunc func(context.Context, *envconf.Config, *testing.T, string) (context.Context, error)
+
 // Environment represents an environment where
 // features can be tested.
 type Environment interface {
@@ -65,10 +75,23 @@ type Environment interface {
 	// after each feature is tested during an env.Test call.
 	AfterEachFeature(...FeatureEnvFunc) Environment
 
+	// BeforeEachAssessment registers funcs that are executed
+	// before each assessment of the features tested.
+	BeforeEachAssessment(...AssessmentEnvFunc) Environment
+
+	// AfterEachAssessment registers funcs that are executed
+	// after each assessment of the features tested.
+	AfterEachAssessment(...AssessmentEnvFunc) Environment
+
 	// Test executes a test feature defined in a TestXXX function
 	// This method surfaces context for further updates.
 	Test(*testing.T, ...Feature)
 
+	// TestSuite executes the suites of features defined in a TestXXX
+	// function, each suite running its setups before its features
+	// and its teardowns after them.
+	TestSuite(*testing.T, ...Suite)
+
 	// TestInParallel executes a series of test features defined in a
 	// TestXXX function in parallel. This works the same way Test method
 	// does with the caveat that the features will all be run in parallel
@@ -97,6 +120,61 @@ type Feature interface {
 	Steps() []Step
 }
 
+// Suite groups related features, tested together with shared
+// labels and steps run before and after all of them.
+type Suite interface {
+	// Name is a descriptive text for the suite
+	Name() string
+	// Labels returns the labels shared by the features of the suite
+	Labels() Labels
+	// Features returns the features of the suite
+	Features() []Feature
+	// Steps returns the setup and teardown steps of the suite
+	Steps() []Step
+}
+
+// ParallelFeature is implemented by features that declare
+// whether they are safe to be tested in parallel with other features.
+type ParallelFeature interface {
+	Feature
+	// Parallel returns true when the feature can be run concurrently
+	Parallel() bool
+}
+
+// Requirements are the capabilities the cluster must provide for a feature to be tested
+type Requirements struct {
+	// MinKubeVersion is the minimum Kubernetes version of the cluster, i.e. 1.23
+	MinKubeVersion string
+	// APIResources are the resources that must be served by the cluster
+	APIResources []schema.GroupVersionResource
+	// NodeSelector are the labels of the nodes the feature needs, i.e. kubernetes.io/os=windows.
+	// At least one schedulable node of the cluster must have all of them.
+	NodeSelector map[string]string
+}
+
+// FeatureWithRequirements is implemented by features that declare
+// requirements on the cluster they are tested against.
+type FeatureWithRequirements interface {
+	Feature
+	// Requirements returns the requirements of the feature
+	Requirements() Requirements
+}
+
+// RateLimit paces the create, apply, and delete operations made
+// during a feature to QPS operations per second, with bursts of Burst.
+type RateLimit struct {
+	QPS   float32
+	Burst int
+}
+
+// RateLimitedFeature is implemented by features overriding the rate
+// limit of the environment configuration.
+type RateLimitedFeature interface {
+	Feature
+	// RateLimit returns the rate limit of the feature, a zero QPS means no override
+	RateLimit() RateLimit
+}
+
 type Level uint8
 
 const (
@@ -108,6 +186,19 @@ const (
 	LevelTeardown
 )
 
+func (l Level) String() string {
+	switch l {
+	case LevelSetup:
+		return "setup"
+	case LevelAssess:
+		return "assess"
+	case LevelTeardown:
+		return "teardown"
+	default:
+		return fmt.Sprintf("Level(%d)", uint8(l))
+	}
+}
+
 type StepFunc func(context.Context, *testing.T, *envconf.Config) context.Context
 
 type Step interface {
@@ -118,3 +209,23 @@ type Step interface {
 	// Func is the operation for the step
 	Func() StepFunc
 }
+
+// OptionalStepFunc is the operation of an optional step. The failures it reports
+// through t are recorded without failing the test.
+type OptionalStepFunc func(context.Context, testing.TB, *envconf.Config) context.Context
+
+// OptionalStep is implemented by the assessments that do not fail their feature,
+// i.e. canary checks or newly added probes being stabilized.
+type OptionalStep interface {
+	Step
+	// OptionalFunc is the operation for the step
+	OptionalFunc() OptionalStepFunc
+}
+
+// LocatedStep is implemented by steps that record the location
+// of the code that registered them.
+type LocatedStep interface {
+	Step
+	// Location returns the file:line where the step was registered
+	Location() string
+}
@@ -0,0 +1,266 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package labels provides boolean expressions to select features by their labels,
+// for CI selections that cannot be expressed with label equality alone.
+package labels
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Filter is a parsed label filter expression
+type Filter struct {
+	expr string
+	root node
+}
+
+// ParseFilter parses a boolean label filter expression, i.e. "conformance && !slow || gpu".
+//
+// An identifier matches the features with a label whose key or value is the identifier,
+// key=value matches the features with the label set to value, and key!=value the features
+// without it. Identifiers are combined with ! (not), && (and), || (or), and parentheses,
+// && having precedence over ||.
+func ParseFilter(expr string) (*Filter, error) {
+	p := &parser{expr: expr}
+	p.next()
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("label filter %q: %w", expr, err)
+	}
+	if p.tok.kind != tokEOF {
+		return nil, fmt.Errorf("label filter %q: unexpected %q at offset %d", expr, p.tok.text, p.tok.pos)
+	}
+	return &Filter{expr: expr, root: root}, nil
+}
+
+// MustParseFilter is like ParseFilter but panics if the expression cannot be parsed
+func MustParseFilter(expr string) *Filter {
+	f, err := ParseFilter(expr)
+	if err != nil {
+		panic(err.Error())
+	}
+	return f
+}
+
+// Matches reports whether the labels satisfy the filter
+func (f *Filter) Matches(labels map[string]string) bool {
+	return f.root.eval(labels)
+}
+
+// String returns the filter expression
+func (f *Filter) String() string {
+	return f.expr
+}
+
+type node interface {
+	eval(labels map[string]string) bool
+}
+
+type (
+	notNode   struct{ operand node }
+	andNode   struct{ left, right node }
+	orNode    struct{ left, right node }
+	identNode struct{ name string }
+	// equalNode matches key=value, or key!=value when negated
+	equalNode struct {
+		key, value string
+		negated    bool
+	}
+)
+
+func (n notNode) eval(labels map[string]string) bool {
+	return !n.operand.eval(labels)
+}
+
+func (n andNode) eval(labels map[string]string) bool {
+	return n.left.eval(labels) && n.right.eval(labels)
+}
+
+func (n orNode) eval(labels map[string]string) bool {
+	return n.left.eval(labels) || n.right.eval(labels)
+}
+
+func (n identNode) eval(labels map[string]string) bool {
+	for k, v := range labels {
+		if k == n.name || v == n.name {
+			return true
+		}
+	}
+	return false
+}
+
+func (n equalNode) eval(labels map[string]string) bool {
+	v, ok := labels[n.key]
+	return (ok && v == n.value) != n.negated
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokNot
+	tokAnd
+	tokOr
+	tokLParen
+	tokRParen
+	tokEqual
+	tokNotEqual
+	tokInvalid
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// parser is a recursive descent parser of the filter expressions
+type parser struct {
+	expr string
+	pos  int
+	tok  token
+}
+
+// isIdentRune reports whether r can be part of an identifier, which
+// includes the characters allowed in Kubernetes label keys and values
+func isIdentRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("-_./:", r)
+}
+
+// next reads the next token of the expression
+func (p *parser) next() {
+	for p.pos < len(p.expr) && unicode.IsSpace(rune(p.expr[p.pos])) {
+		p.pos++
+	}
+	start := p.pos
+	if p.pos >= len(p.expr) {
+		p.tok = token{kind: tokEOF, text: "end of expression", pos: start}
+		return
+	}
+
+	two := p.expr[p.pos:]
+	switch {
+	case strings.HasPrefix(two, "&&"):
+		p.pos += 2
+		p.tok = token{kind: tokAnd, text: "&&", pos: start}
+	case strings.HasPrefix(two, "||"):
+		p.pos += 2
+		p.tok = token{kind: tokOr, text: "||", pos: start}
+	case strings.HasPrefix(two, "!="):
+		p.pos += 2
+		p.tok = token{kind: tokNotEqual, text: "!=", pos: start}
+	case two[0] == '!':
+		p.pos++
+		p.tok = token{kind: tokNot, text: "!", pos: start}
+	case two[0] == '=':
+		p.pos++
+		p.tok = token{kind: tokEqual, text: "=", pos: start}
+	case two[0] == '(':
+		p.pos++
+		p.tok = token{kind: tokLParen, text: "(", pos: start}
+	case two[0] == ')':
+		p.pos++
+		p.tok = token{kind: tokRParen, text: ")", pos: start}
+	default:
+		for _, r := range two {
+			if !isIdentRune(r) {
+				break
+			}
+			p.pos += len(string(r))
+		}
+		if p.pos == start {
+			p.pos++
+			p.tok = token{kind: tokInvalid, text: two[:1], pos: start}
+			return
+		}
+		p.tok = token{kind: tokIdent, text: p.expr[start:p.pos], pos: start}
+	}
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokAnd {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	switch p.tok.kind {
+	case tokNot:
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand: operand}, nil
+	case tokLParen:
+		p.next()
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokRParen {
+			return nil, fmt.Errorf("expected ) at offset %d, got %q", p.tok.pos, p.tok.text)
+		}
+		p.next()
+		return n, nil
+	case tokIdent:
+		name := p.tok.text
+		p.next()
+		if p.tok.kind != tokEqual && p.tok.kind != tokNotEqual {
+			return identNode{name: name}, nil
+		}
+		negated := p.tok.kind == tokNotEqual
+		p.next()
+		if p.tok.kind != tokIdent {
+			return nil, fmt.Errorf("expected label value at offset %d, got %q", p.tok.pos, p.tok.text)
+		}
+		value := p.tok.text
+		p.next()
+		return equalNode{key: name, value: value, negated: negated}, nil
+	default:
+		return nil, fmt.Errorf("expected label at offset %d, got %q", p.tok.pos, p.tok.text)
+	}
+}
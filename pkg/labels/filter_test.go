@@ -0,0 +1,76 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package labels
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFilter_Matches(t *testing.T) {
+	conformance := map[string]string{"type": "conformance"}
+	slowConformance := map[string]string{"type": "conformance", "slow": "true"}
+	gpu := map[string]string{"gpu": "nvidia", "slow": "true"}
+
+	tests := []struct {
+		expr    string
+		labels  map[string]string
+		matches bool
+	}{
+		{expr: "conformance", labels: conformance, matches: true},
+		{expr: "type", labels: conformance, matches: true},
+		{expr: "gpu", labels: conformance, matches: false},
+		{expr: "conformance && !slow || gpu", labels: conformance, matches: true},
+		{expr: "conformance && !slow || gpu", labels: slowConformance, matches: false},
+		{expr: "conformance && !slow || gpu", labels: gpu, matches: true},
+		{expr: "conformance && (!slow || gpu)", labels: gpu, matches: false},
+		{expr: "!(conformance || gpu)", labels: map[string]string{}, matches: true},
+		{expr: "type=conformance && slow!=true", labels: conformance, matches: true},
+		{expr: "type=conformance && slow!=true", labels: slowConformance, matches: false},
+		{expr: "gpu=nvidia", labels: gpu, matches: true},
+		{expr: "example.com/tier=1", labels: map[string]string{"example.com/tier": "1"}, matches: true},
+	}
+	for _, test := range tests {
+		f, err := ParseFilter(test.expr)
+		if err != nil {
+			t.Fatalf("%q: %s", test.expr, err)
+		}
+		if f.Matches(test.labels) != test.matches {
+			t.Errorf("%q with labels %v: expected match %t", test.expr, test.labels, test.matches)
+		}
+	}
+}
+
+func TestParseFilter_Errors(t *testing.T) {
+	tests := []struct {
+		expr string
+		err  string
+	}{
+		{expr: "", err: `expected label at offset 0, got "end of expression"`},
+		{expr: "a &&", err: `expected label at offset 4`},
+		{expr: "(a || b", err: `expected ) at offset 7`},
+		{expr: "a b", err: `unexpected "b" at offset 2`},
+		{expr: "a=", err: `expected label value at offset 2`},
+		{expr: "a & b", err: `unexpected "&" at offset 2`},
+	}
+	for _, test := range tests {
+		_, err := ParseFilter(test.expr)
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%q: expected error containing %q, got: %v", test.expr, test.err, err)
+		}
+	}
+}
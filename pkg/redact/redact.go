@@ -0,0 +1,159 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package redact masks registered secrets, such as tokens and passwords,
+// in logs, captured command output, and artifacts so that the output of
+// suites handling credentials can be safely shared.
+package redact
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Mask replaces the secrets in redacted text
+const Mask = "[REDACTED]"
+
+// Redactor masks the registered secrets. It is safe for concurrent use.
+type Redactor struct {
+	mu       sync.RWMutex
+	secrets  []string
+	replacer *strings.Replacer
+}
+
+// New returns a redactor masking the provided secrets
+func New(secrets ...string) *Redactor {
+	r := &Redactor{}
+	r.Add(secrets...)
+	return r
+}
+
+// Add registers secrets to mask, empty secrets are ignored
+func (r *Redactor) Add(secrets ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range secrets {
+		if s != "" {
+			r.secrets = append(r.secrets, s)
+		}
+	}
+	// replace the longest secrets first, in case secrets overlap
+	sort.SliceStable(r.secrets, func(i, j int) bool { return len(r.secrets[i]) > len(r.secrets[j]) })
+	pairs := make([]string, 0, 2*len(r.secrets))
+	for _, s := range r.secrets {
+		pairs = append(pairs, s, Mask)
+	}
+	r.replacer = strings.NewReplacer(pairs...)
+}
+
+// String returns s with the registered secrets masked
+func (r *Redactor) String(s string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.replacer == nil {
+		return s
+	}
+	return r.replacer.Replace(s)
+}
+
+// value returns v with the registered secrets masked, values other than
+// strings, errors, and stringers are formatted only if they contain a secret
+func (r *Redactor) value(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		return r.String(v)
+	case error:
+		return r.String(v.Error())
+	case fmt.Stringer:
+		return r.String(v.String())
+	case nil, bool, int, int32, int64, uint, uint32, uint64, float32, float64:
+		return v
+	}
+	if s := fmt.Sprintf("%+v", v); r.String(s) != s {
+		return r.String(s)
+	}
+	return v
+}
+
+func (r *Redactor) values(values []interface{}) []interface{} {
+	redacted := make([]interface{}, len(values))
+	for i, v := range values {
+		redacted[i] = r.value(v)
+	}
+	return redacted
+}
+
+// Filter implements the klog.LogFilter interface, install the redactor
+// with klog.SetLogFilter to mask the secrets in the logs.
+func (r *Redactor) Filter(args []interface{}) []interface{} {
+	return r.values(args)
+}
+
+// FilterF implements the klog.LogFilter interface
+func (r *Redactor) FilterF(format string, args []interface{}) (string, []interface{}) {
+	return r.String(format), r.values(args)
+}
+
+// FilterS implements the klog.LogFilter interface
+func (r *Redactor) FilterS(msg string, keysAndValues []interface{}) (string, []interface{}) {
+	return r.String(msg), r.values(keysAndValues)
+}
+
+// Writer masks the registered secrets in the data written to the underlying
+// writer. Data is written line by line so that secrets split across writes
+// are masked, Close must be called to write the last incomplete line.
+type Writer struct {
+	r   *Redactor
+	w   io.Writer
+	buf bytes.Buffer
+}
+
+// NewWriter returns a writer masking the secrets of the redactor before
+// writing to w, i.e. to capture the output of commands.
+func (r *Redactor) NewWriter(w io.Writer) *Writer {
+	return &Writer{r: r, w: w}
+}
+
+// Write writes the complete lines of p, with the secrets masked
+func (w *Writer) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+	if i := bytes.LastIndexByte(w.buf.Bytes(), '\n'); i >= 0 {
+		lines := w.buf.Next(i + 1)
+		if _, err := io.WriteString(w.w, w.r.String(string(lines))); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Close writes the remaining incomplete line and closes the
+// underlying writer if it is an io.Closer
+func (w *Writer) Close() error {
+	if w.buf.Len() > 0 {
+		if _, err := io.WriteString(w.w, w.r.String(w.buf.String())); err != nil {
+			return err
+		}
+		w.buf.Reset()
+	}
+	if c, ok := w.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
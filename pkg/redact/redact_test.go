@@ -0,0 +1,71 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redact
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestRedactor_String(t *testing.T) {
+	r := New("s3cret", "", "s3cret-token")
+	got := r.String("token=s3cret-token password=s3cret")
+	if got != "token=[REDACTED] password=[REDACTED]" {
+		t.Errorf("unexpected redaction: %s", got)
+	}
+	if got := New().String("nothing to hide"); got != "nothing to hide" {
+		t.Errorf("unexpected redaction: %s", got)
+	}
+}
+
+func TestRedactor_Filter(t *testing.T) {
+	r := New("hunter2")
+	type creds struct{ Password string }
+
+	msg, kv := r.FilterS("login with hunter2", []interface{}{"err", errors.New("bad password hunter2"), "creds", creds{"hunter2"}, "attempts", 3})
+	if msg != "login with [REDACTED]" {
+		t.Errorf("unexpected message: %s", msg)
+	}
+	if kv[1] != "bad password [REDACTED]" || kv[3] != "{Password:[REDACTED]}" || kv[5] != 3 {
+		t.Errorf("unexpected values: %v", kv)
+	}
+
+	format, args := r.FilterF("%s: %v", []interface{}{"hunter2", creds{"other"}})
+	if format != "%s: %v" || args[0] != Mask || args[1] != (creds{"other"}) {
+		t.Errorf("unexpected format and args: %s %v", format, args)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := New("hunter2").NewWriter(&out)
+	for _, chunk := range []string{"password: hun", "ter2\nnext ", "line hunter2"} {
+		if _, err := w.Write([]byte(chunk)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if out.String() != "password: [REDACTED]\n" {
+		t.Errorf("expected complete lines only to be written, got: %q", out.String())
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "password: [REDACTED]\nnext line [REDACTED]" {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+}
@@ -0,0 +1,308 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package specs loads features from declarative YAML specs, so that straightforward
+// e2e cases (apply fixture manifests, then wait for objects to have the expected
+// fields) can be contributed without writing Go:
+//
+//	name: nginx deployment
+//	labels:
+//	  type: smoke
+//	fixtures:
+//	- file: testdata/nginx.yaml
+//	assessments:
+//	- name: deployment available
+//	  object:
+//	    apiVersion: apps/v1
+//	    kind: Deployment
+//	    name: nginx
+//	  condition: Available
+//	  fields:
+//	    status:
+//	      readyReplicas: 2
+//	  jsonPath:
+//	    .spec.template.spec.containers[0].image: nginx:1.21
+//	  timeout: 2m
+//
+// The fixtures are created, in the namespace of the env config unless they set one,
+// before the assessments and deleted after them. Each assessment gets the object,
+// in the namespace of the env config unless set, until it has the status condition
+// set to True, the fields, which only need the fields to check, and the values
+// selected by the JSONPath expressions, or the timeout expires.
+package specs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	sigsyaml "sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/e2e-framework/klient/assert"
+	"sigs.k8s.io/e2e-framework/klient/decoder"
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+)
+
+// Spec is the declarative spec of a feature
+type Spec struct {
+	Name        string            `json:"name"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Fixtures    []Fixture         `json:"fixtures,omitempty"`
+	Assessments []Assessment      `json:"assessments"`
+}
+
+// Fixture is a stream of manifests created before the assessments, read from
+// a file or provided inline
+type Fixture struct {
+	// File is the path, or glob pattern, of the manifests, relative to the spec file when loaded with Load
+	File     string `json:"file,omitempty"`
+	Manifest string `json:"manifest,omitempty"`
+}
+
+// Assessment waits for an object to have the expected status condition and fields
+type Assessment struct {
+	Name   string    `json:"name"`
+	Object ObjectRef `json:"object"`
+	// Condition is the type of the status condition expected to be True, i.e. Ready
+	Condition string `json:"condition,omitempty"`
+	// Fields are the expected fields of the object, see assert.Fields
+	Fields map[string]interface{} `json:"fields,omitempty"`
+	// JSONPath maps JSONPath expressions to the expected values, see assert.JSONPath
+	JSONPath map[string]string `json:"jsonPath,omitempty"`
+	// Timeout of the wait, the default timeout of wait.For when not set
+	Timeout metav1.Duration `json:"timeout,omitempty"`
+}
+
+// ObjectRef identifies the object of an assessment
+type ObjectRef struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace,omitempty"`
+}
+
+// Parse reads the specs from a stream of YAML or JSON documents. Unknown fields are rejected.
+func Parse(r io.Reader) ([]Spec, error) {
+	var specs []Spec
+	reader := yaml.NewYAMLReader(bufio.NewReader(r))
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("specs: read spec: %w", err)
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		var spec Spec
+		if err := sigsyaml.UnmarshalStrict(doc, &spec); err != nil {
+			return nil, fmt.Errorf("specs: decode spec: %w", err)
+		}
+		if err := spec.validate(); err != nil {
+			return nil, fmt.Errorf("specs: spec %q: %w", spec.Name, err)
+		}
+		for i := range spec.Assessments {
+			if err := normalizeFields(&spec.Assessments[i]); err != nil {
+				return nil, fmt.Errorf("specs: spec %q: %w", spec.Name, err)
+			}
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// Load returns the features of the specs read from the files of fsys matching the
+// pattern, i.e. Load(os.DirFS("testdata"), "*.spec.yaml"). The fixture files are
+// read from fsys, relative to the spec file.
+func Load(fsys fs.FS, pattern string) ([]features.Feature, error) {
+	files, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("specs: %w", err)
+	}
+	var feats []features.Feature
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("specs: %w", err)
+		}
+		specs, err := Parse(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		for _, spec := range specs {
+			for i, fixture := range spec.Fixtures {
+				if fixture.File != "" {
+					spec.Fixtures[i].File = path.Join(path.Dir(file), fixture.File)
+				}
+			}
+			feats = append(feats, FromSpec(fsys, spec))
+		}
+	}
+	return feats, nil
+}
+
+// FromSpec returns the feature of the spec. The fixture files are read from fsys.
+func FromSpec(fsys fs.FS, spec Spec) features.Feature {
+	builder := features.New(spec.Name)
+	for k, v := range spec.Labels {
+		builder = builder.WithLabel(k, v)
+	}
+	if len(spec.Fixtures) > 0 {
+		builder = builder.
+			Setup(func(ctx context.Context, t *testing.T, cfg *envconf.Config) context.Context {
+				r := cfg.Client().Resources()
+				if err := eachFixture(ctx, fsys, spec.Fixtures, cfg.Namespace(), decoder.CreateHandler(r)); err != nil {
+					t.Fatalf("creating fixtures: %s", err)
+				}
+				return ctx
+			}).
+			Teardown(func(ctx context.Context, t *testing.T, cfg *envconf.Config) context.Context {
+				r := cfg.Client().Resources()
+				if err := eachFixture(ctx, fsys, spec.Fixtures, cfg.Namespace(), decoder.DeleteIgnoreNotFound(r)); err != nil {
+					t.Errorf("deleting fixtures: %s", err)
+				}
+				return ctx
+			})
+	}
+	for _, a := range spec.Assessments {
+		a := a
+		builder = builder.Assess(a.Name, func(ctx context.Context, t *testing.T, cfg *envconf.Config) context.Context {
+			r, err := resources.New(cfg.Client().RESTConfig())
+			if err != nil {
+				t.Fatal(err)
+			}
+			var opts []wait.Option
+			if a.Timeout.Duration > 0 {
+				opts = append(opts, wait.WithTimeout(a.Timeout.Duration))
+			}
+			if err := assert.Eventually(ctx, r, a.object(cfg.Namespace()), a.matcher(), opts...); err != nil {
+				t.Error(err)
+			}
+			return ctx
+		})
+	}
+	return builder.Feature()
+}
+
+// eachFixture decodes the objects of the fixtures, in the namespace unless they set one, and calls handler
+func eachFixture(ctx context.Context, fsys fs.FS, fixtures []Fixture, namespace string, handler decoder.HandlerFunc) error {
+	defaultNamespace := decoder.MutateOption(func(obj k8s.Object) error {
+		if obj.GetNamespace() == "" {
+			obj.SetNamespace(namespace)
+		}
+		return nil
+	})
+	for _, fixture := range fixtures {
+		var err error
+		if fixture.File != "" {
+			err = decoder.DecodeEachFile(ctx, fsys, fixture.File, handler, defaultNamespace)
+		} else {
+			err = decoder.DecodeEach(ctx, strings.NewReader(fixture.Manifest), handler, defaultNamespace)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// object returns the object of the assessment, in namespace unless set
+func (a Assessment) object(namespace string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(a.Object.APIVersion)
+	obj.SetKind(a.Object.Kind)
+	obj.SetName(a.Object.Name)
+	obj.SetNamespace(namespace)
+	if a.Object.Namespace != "" {
+		obj.SetNamespace(a.Object.Namespace)
+	}
+	return obj
+}
+
+// matcher returns the matcher of the expectations of the assessment
+func (a Assessment) matcher() assert.Matcher {
+	var matchers []assert.Matcher
+	if a.Condition != "" {
+		matchers = append(matchers, assert.JSONPath(fmt.Sprintf(`.status.conditions[?(@.type=="%s")].status`, a.Condition), "True"))
+	}
+	if len(a.Fields) > 0 {
+		matchers = append(matchers, assert.Fields(&unstructured.Unstructured{Object: a.Fields}))
+	}
+	for expr, value := range a.JSONPath {
+		matchers = append(matchers, assert.JSONPath(expr, value))
+	}
+	return assert.All(matchers...)
+}
+
+func (s Spec) validate() error {
+	if s.Name == "" {
+		return errors.New("name is required")
+	}
+	if len(s.Assessments) == 0 {
+		return errors.New("at least one assessment is required")
+	}
+	for i, f := range s.Fixtures {
+		if (f.File == "") == (f.Manifest == "") {
+			return fmt.Errorf("fixture #%d: one of file or manifest is required", i)
+		}
+	}
+	for _, a := range s.Assessments {
+		if a.Name == "" {
+			return errors.New("assessment name is required")
+		}
+		if a.Object.APIVersion == "" || a.Object.Kind == "" || a.Object.Name == "" {
+			return fmt.Errorf("assessment %q: object apiVersion, kind, and name are required", a.Name)
+		}
+		if a.Condition == "" && len(a.Fields) == 0 && len(a.JSONPath) == 0 {
+			return fmt.Errorf("assessment %q: one of condition, fields, or jsonPath is required", a.Name)
+		}
+	}
+	return nil
+}
+
+// normalizeFields decodes the whole numbers of the expected fields as int64, as
+// they are decoded in the objects retrieved from the API server
+func normalizeFields(a *Assessment) error {
+	if len(a.Fields) == 0 {
+		return nil
+	}
+	data, err := utiljson.Marshal(a.Fields)
+	if err != nil {
+		return fmt.Errorf("assessment %q: fields: %w", a.Name, err)
+	}
+	fields := make(map[string]interface{})
+	if err := utiljson.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("assessment %q: fields: %w", a.Name, err)
+	}
+	a.Fields = fields
+	return nil
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package specs
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+)
+
+const testSpec = `
+name: nginx deployment
+labels:
+  type: smoke
+fixtures:
+- file: testdata/nginx.yaml
+- manifest: |
+    apiVersion: v1
+    kind: ConfigMap
+    metadata:
+      name: settings
+      namespace: kube-system
+assessments:
+- name: deployment available
+  object:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: nginx
+  condition: Available
+  fields:
+    status:
+      readyReplicas: 2
+  jsonPath:
+    .spec.template.spec.containers[0].image: nginx:1.21
+  timeout: 2m
+---
+name: config
+assessments:
+- name: config present
+  object:
+    apiVersion: v1
+    kind: ConfigMap
+    name: settings
+    namespace: kube-system
+  fields:
+    data:
+      mode: fast
+`
+
+const testFixture = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+`
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"specs/nginx.spec.yaml":     {Data: []byte(testSpec)},
+		"specs/testdata/nginx.yaml": {Data: []byte(testFixture)},
+	}
+	feats, err := Load(fsys, "specs/*.spec.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(feats) != 2 {
+		t.Fatalf("expected 2 features, got %d", len(feats))
+	}
+
+	nginx := feats[0]
+	if nginx.Name() != "nginx deployment" || nginx.Labels()["type"] != "smoke" {
+		t.Errorf("unexpected feature %q with labels %v", nginx.Name(), nginx.Labels())
+	}
+	var steps []string
+	for _, step := range nginx.Steps() {
+		steps = append(steps, step.Level().String()+":"+step.Name())
+	}
+	expected := []string{"setup:nginx deployment-setup", "teardown:nginx deployment-teardown", "assess:deployment available"}
+	if !reflect.DeepEqual(steps, expected) {
+		t.Errorf("expected steps %v, got %v", expected, steps)
+	}
+	if len(feats[1].Steps()) != 1 {
+		t.Errorf("expected a single assessment step without fixtures, got %d steps", len(feats[1].Steps()))
+	}
+}
+
+func TestEachFixture(t *testing.T) {
+	fsys := fstest.MapFS{"specs/testdata/nginx.yaml": {Data: []byte(testFixture)}}
+	specs, err := Parse(strings.NewReader(testSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixtures := specs[0].Fixtures
+	fixtures[0].File = "specs/" + fixtures[0].File
+
+	var objects []string
+	err = eachFixture(context.Background(), fsys, fixtures, "e2e", func(_ context.Context, obj k8s.Object) error {
+		objects = append(objects, obj.GetNamespace()+"/"+obj.GetName())
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"e2e/nginx", "kube-system/settings"}; !reflect.DeepEqual(objects, expected) {
+		t.Errorf("expected objects %v, got %v", expected, objects)
+	}
+}
+
+func TestAssessment_Matcher(t *testing.T) {
+	specs, err := Parse(strings.NewReader(testSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := specs[0].Assessments[0]
+	if a.Timeout.Minutes() != 2 {
+		t.Errorf("unexpected timeout %s", a.Timeout.Duration)
+	}
+	obj := a.object("e2e")
+	if obj.GetNamespace() != "e2e" || obj.GetKind() != "Deployment" || obj.GetName() != "nginx" {
+		t.Errorf("unexpected object %s %s/%s", obj.GetKind(), obj.GetNamespace(), obj.GetName())
+	}
+
+	deployment := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{
+			"containers": []interface{}{map[string]interface{}{"image": "nginx:1.21"}},
+		}}},
+		"status": map[string]interface{}{
+			"readyReplicas": int64(1),
+			"conditions":    []interface{}{map[string]interface{}{"type": "Available", "status": "False"}},
+		},
+	}}
+	mismatches, err := a.matcher()(deployment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mismatches) != 2 {
+		t.Errorf("expected the condition and replicas mismatches, got %v", mismatches)
+	}
+
+	deployment.Object["status"] = map[string]interface{}{
+		"readyReplicas": int64(2),
+		"conditions":    []interface{}{map[string]interface{}{"type": "Available", "status": "True"}},
+	}
+	if mismatches, err = a.matcher()(deployment); err != nil || len(mismatches) != 0 {
+		t.Errorf("expected the deployment to match, got %v, %v", mismatches, err)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		err  string
+	}{
+		{name: "unknown field", spec: "name: x\nasessments: []", err: "unknown field"},
+		{name: "no name", spec: "assessments: [{name: a}]", err: "name is required"},
+		{name: "no assessment", spec: "name: x", err: "at least one assessment"},
+		{name: "fixture without manifest", spec: "name: x\nfixtures: [{}]\nassessments: [{name: a}]", err: "fixture #0"},
+		{name: "assessment without object", spec: "name: x\nassessments: [{name: a, condition: Ready}]", err: "object apiVersion"},
+		{name: "assessment without expectation", spec: "name: x\nassessments: [{name: a, object: {apiVersion: v1, kind: Pod, name: p}}]", err: "one of condition"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(test.spec))
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("expected error containing %q, got %v", test.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tracing exports the OpenTelemetry spans created by the environment
+// for its setup, features, and assessments, so that long e2e runs can be
+// analyzed in tracing UIs such as Jaeger.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// TracerName is the name of the tracer creating the spans of the environment
+const TracerName = "sigs.k8s.io/e2e-framework"
+
+// DefaultServiceName is the service name the spans are reported under
+const DefaultServiceName = "e2e-framework"
+
+// Attribute keys set on the spans of the environment
+const (
+	SuiteKey      = attribute.Key("e2e.suite")
+	FeatureKey    = attribute.Key("e2e.feature")
+	AssessmentKey = attribute.Key("e2e.assessment")
+	TestKey       = attribute.Key("e2e.test")
+	StatusKey     = attribute.Key("e2e.status")
+)
+
+// NewTracerProvider returns a tracer provider batching the spans to the OTLP/HTTP
+// collector at endpoint (i.e. http://localhost:4318) with the upstream otlptracehttp
+// exporter. The /v1/traces path is used unless endpoint specifies a path. The provider
+// must be shut down to flush the remaining spans.
+func NewTracerProvider(ctx context.Context, endpoint string) (*sdktrace.TracerProvider, error) {
+	opts, err := endpointOptions(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("tracing exporter: %w", err)
+	}
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", DefaultServiceName))),
+	), nil
+}
+
+// endpointOptions converts the endpoint URL to the options of the exporter, which
+// takes the host, path, and scheme separately.
+func endpointOptions(endpoint string) ([]otlptracehttp.Option, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("tracing endpoint: %w", err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("tracing endpoint: missing host in %q", endpoint)
+	}
+
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(u.Host)}
+	switch u.Scheme {
+	case "http":
+		opts = append(opts, otlptracehttp.WithInsecure())
+	case "https":
+	default:
+		return nil, fmt.Errorf("tracing endpoint: unsupported scheme %q", u.Scheme)
+	}
+	if strings.Trim(u.Path, "/") != "" {
+		opts = append(opts, otlptracehttp.WithURLPath(u.Path))
+	}
+	return opts, nil
+}
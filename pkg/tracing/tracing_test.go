@@ -0,0 +1,52 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import "testing"
+
+func TestEndpointOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		options  int
+		err      bool
+	}{
+		{name: "insecure", endpoint: "http://localhost:4318", options: 2},
+		{name: "secure", endpoint: "https://collector.example.com", options: 1},
+		{name: "with path", endpoint: "http://localhost:4318/custom/traces", options: 3},
+		{name: "without scheme", endpoint: "localhost:4318", err: true},
+		{name: "unsupported scheme", endpoint: "grpc://localhost:4317", err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts, err := endpointOptions(test.endpoint)
+			if test.err {
+				if err == nil {
+					t.Fatalf("expected an error for endpoint %s", test.endpoint)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(opts) != test.options {
+				t.Errorf("expected %d options, got %d", test.options, len(opts))
+			}
+		})
+	}
+}
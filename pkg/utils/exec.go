@@ -0,0 +1,174 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	log "k8s.io/klog/v2"
+)
+
+// CommandResult is the outcome of a command run with RunCommand
+type CommandResult struct {
+	// Command is the command line that was run
+	Command  string
+	ExitCode int
+	Stdout   string
+	Stderr   string
+	Duration time.Duration
+}
+
+// CommandError is returned when a command could not be started, was cancelled,
+// or exited with a non-zero code
+type CommandError struct {
+	Result *CommandResult
+	Err    error
+}
+
+func (e *CommandError) Error() string {
+	msg := fmt.Sprintf("command %q: %s", e.Result.Command, e.Err)
+	if stderr := strings.TrimSpace(e.Result.Stderr); stderr != "" {
+		msg = fmt.Sprintf("%s: %s", msg, stderr)
+	}
+	return msg
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+type commandOptions struct {
+	dir     string
+	env     []string
+	stdin   io.Reader
+	output  io.Writer
+	timeout time.Duration
+}
+
+// CommandOption configures how RunCommand runs a command
+type CommandOption func(*commandOptions)
+
+// WithCommandDir sets the working directory of the command
+func WithCommandDir(dir string) CommandOption {
+	return func(o *commandOptions) {
+		o.dir = dir
+	}
+}
+
+// WithCommandEnv adds environment variables, formatted as KEY=VALUE, to
+// the environment of the test process passed to the command
+func WithCommandEnv(env ...string) CommandOption {
+	return func(o *commandOptions) {
+		o.env = append(o.env, env...)
+	}
+}
+
+// WithCommandStdin sets the standard input of the command
+func WithCommandStdin(stdin io.Reader) CommandOption {
+	return func(o *commandOptions) {
+		o.stdin = stdin
+	}
+}
+
+// WithCommandOutput streams the standard output and error of the command to w,
+// i.e. os.Stdout, as it runs. The output is captured in the result all the same.
+func WithCommandOutput(w io.Writer) CommandOption {
+	return func(o *commandOptions) {
+		o.output = w
+	}
+}
+
+// WithCommandTimeout kills the command if it runs longer than timeout
+func WithCommandTimeout(timeout time.Duration) CommandOption {
+	return func(o *commandOptions) {
+		o.timeout = timeout
+	}
+}
+
+// RunCommand runs the command, i.e. a cloud CLI or a database seeding script, until it exits
+// or ctx is done, in which case the command is killed. Its standard output and error are captured
+// in the returned result. A *CommandError, with the result, is returned when the command cannot
+// be started, is killed, or exits with a non-zero code.
+func RunCommand(ctx context.Context, name string, args []string, opts ...CommandOption) (*CommandResult, error) {
+	options := &commandOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	if options.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = options.dir
+	cmd.Stdin = options.stdin
+	if len(options.env) > 0 {
+		cmd.Env = append(os.Environ(), options.env...)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if options.output != nil {
+		// stdout and stderr are copied concurrently, so they share a writer that serializes the writes
+		output := &lockedWriter{w: options.output}
+		cmd.Stdout, cmd.Stderr = io.MultiWriter(&stdout, output), io.MultiWriter(&stderr, output)
+	}
+
+	result := &CommandResult{Command: strings.Join(append([]string{name}, args...), " ")}
+	log.V(4).Infof("Running command %q", result.Command)
+	start := time.Now()
+	err := cmd.Run()
+	result.Duration = time.Since(start)
+	result.Stdout, result.Stderr = stdout.String(), stderr.String()
+	result.ExitCode = cmd.ProcessState.ExitCode()
+	log.V(4).Infof("Command %q exited with code %d after %s", result.Command, result.ExitCode, result.Duration)
+
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = fmt.Errorf("%s: %w", err, ctxErr)
+		}
+		return result, &CommandError{Result: result, Err: err}
+	}
+	return result, nil
+}
+
+// lockedWriter serializes the writes to w
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+// IsExitCode reports whether err is the error of a command that exited with the code
+func IsExitCode(err error, code int) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr) && exitErr.ExitCode() == code
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunCommand(t *testing.T) {
+	var output bytes.Buffer
+	result, err := RunCommand(context.TODO(), "sh", []string{"-c", `echo "$GREETING"; echo oops >&2`},
+		WithCommandEnv("GREETING=hello"), WithCommandOutput(&output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Stdout != "hello\n" || result.Stderr != "oops\n" || result.ExitCode != 0 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if !strings.Contains(output.String(), "hello") || !strings.Contains(output.String(), "oops") {
+		t.Errorf("expected the output to be streamed, got %q", output.String())
+	}
+}
+
+func TestRunCommand_Failure(t *testing.T) {
+	result, err := RunCommand(context.TODO(), "sh", []string{"-c", "echo bad input >&2; exit 3"})
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) {
+		t.Fatalf("expected a *CommandError, got %v", err)
+	}
+	if result.ExitCode != 3 || !IsExitCode(err, 3) {
+		t.Errorf("expected exit code 3, got %d", result.ExitCode)
+	}
+	if expected := `command "sh -c echo bad input >&2; exit 3": exit status 3: bad input`; err.Error() != expected {
+		t.Errorf("expected error %q, got %q", expected, err)
+	}
+}
+
+func TestRunCommand_Cancelled(t *testing.T) {
+	start := time.Now()
+	_, err := RunCommand(context.TODO(), "sleep", []string{"10"}, WithCommandTimeout(50*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the command to be killed on timeout, got %v", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Error("expected the command not to run to completion")
+	}
+
+	_, err = RunCommand(context.TODO(), "does-not-exist-e2e", nil)
+	if err == nil {
+		t.Error("expected an error for a missing command")
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package utils provides helpers equivalent to common kubectl operations
+// (apply, wait, delete) performed through the client, so that tests can
+// use manifests without a kubectl binary on the runner, and a wrapper to run
+// the external tools that remain needed (cloud CLIs, seeding scripts).
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	log "k8s.io/klog/v2"
+
+	"sigs.k8s.io/e2e-framework/klient/decoder"
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/klient/wait/conditions"
+)
+
+// ApplyManifests decodes the manifests in fsys matching pattern and applies the
+// objects with server-side apply, the equivalent of `kubectl apply --server-side -f`.
+// It returns the applied objects, as returned by the API server.
+func ApplyManifests(ctx context.Context, r *resources.Resources, fsys fs.FS, pattern string, opts ...decoder.DecodeOption) ([]k8s.Object, error) {
+	var applied []k8s.Object
+	err := decoder.DecodeEachFile(ctx, fsys, pattern, func(ctx context.Context, obj k8s.Object) error {
+		log.V(4).Infof("Applying %s %s/%s", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetNamespace(), obj.GetName())
+		if err := r.Apply(ctx, obj); err != nil {
+			return err
+		}
+		applied = append(applied, obj)
+		return nil
+	}, opts...)
+	if err != nil {
+		return applied, fmt.Errorf("apply manifests: %w", err)
+	}
+	return applied, nil
+}
+
+// DeleteManifests decodes the manifests in fsys matching pattern and deletes the objects,
+// ignoring those already deleted, the equivalent of `kubectl delete --ignore-not-found -f`.
+// It returns the deleted objects, which can be passed to WaitForDeletion.
+func DeleteManifests(ctx context.Context, r *resources.Resources, fsys fs.FS, pattern string, opts ...decoder.DecodeOption) ([]k8s.Object, error) {
+	var deleted []k8s.Object
+	err := decoder.DecodeEachFile(ctx, fsys, pattern, func(ctx context.Context, obj k8s.Object) error {
+		log.V(4).Infof("Deleting %s %s/%s", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetNamespace(), obj.GetName())
+		if err := r.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		deleted = append(deleted, obj)
+		return nil
+	}, opts...)
+	if err != nil {
+		return deleted, fmt.Errorf("delete manifests: %w", err)
+	}
+	return deleted, nil
+}
+
+// WaitForCondition waits for all the objects to report the status condition with the given
+// status, the equivalent of `kubectl wait --for=condition=<conditionType>=<status>`.
+// The wait options apply to each object in turn.
+func WaitForCondition(r *resources.Resources, objs []k8s.Object, conditionType, status string, opts ...wait.Option) error {
+	c := conditions.New(r)
+	for _, obj := range objs {
+		err := wait.For(c.ResourceMatch(obj, func(object k8s.Object) bool {
+			return HasCondition(object, conditionType, status)
+		}), opts...)
+		if err != nil {
+			return fmt.Errorf("wait for condition %s=%s on %s/%s: %w", conditionType, status, obj.GetNamespace(), obj.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// WaitForDeletion waits for all the objects to be deleted, the equivalent of `kubectl wait --for=delete`.
+// The wait options apply to each object in turn.
+func WaitForDeletion(r *resources.Resources, objs []k8s.Object, opts ...wait.Option) error {
+	c := conditions.New(r)
+	for _, obj := range objs {
+		if err := wait.For(c.ResourceDeleted(obj), opts...); err != nil {
+			return fmt.Errorf("wait for deletion of %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// HasCondition reports whether the object has a condition of the given type and status in its
+// status.conditions, following the convention used by most built-in and custom resources.
+func HasCondition(obj k8s.Object, conditionType, status string) bool {
+	content, ok := obj.(runtime.Unstructured)
+	var fields map[string]interface{}
+	if ok {
+		fields = content.UnstructuredContent()
+	} else {
+		var err error
+		if fields, err = runtime.DefaultUnstructuredConverter.ToUnstructured(obj); err != nil {
+			log.V(4).Infof("has condition: convert %T: %s", obj, err)
+			return false
+		}
+	}
+
+	conds, found, err := unstructured.NestedSlice(fields, "status", "conditions")
+	if !found || err != nil {
+		return false
+	}
+	for _, cond := range conds {
+		m, ok := cond.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if m["type"] == conditionType && m["status"] == status {
+			return true
+		}
+	}
+	return false
+}
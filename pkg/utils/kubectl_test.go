@@ -0,0 +1,62 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+)
+
+func TestHasCondition(t *testing.T) {
+	deployment := &appsv1.Deployment{Status: appsv1.DeploymentStatus{
+		Conditions: []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: v1.ConditionTrue}},
+	}}
+	custom := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "False"},
+			},
+		},
+	}}
+
+	tests := []struct {
+		name          string
+		obj           k8s.Object
+		conditionType string
+		status        string
+		expected      bool
+	}{
+		{name: "typed match", obj: deployment, conditionType: "Available", status: "True", expected: true},
+		{name: "typed status mismatch", obj: deployment, conditionType: "Available", status: "False"},
+		{name: "typed missing", obj: deployment, conditionType: "Progressing", status: "True"},
+		{name: "unstructured match", obj: custom, conditionType: "Ready", status: "False", expected: true},
+		{name: "no conditions", obj: &v1.ConfigMap{}, conditionType: "Ready", status: "True"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := HasCondition(test.obj, test.conditionType, test.status); got != test.expected {
+				t.Errorf("expected %t, got %t", test.expected, got)
+			}
+		})
+	}
+}
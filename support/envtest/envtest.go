@@ -0,0 +1,170 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package envtest runs a local Kubernetes control plane, an etcd and a kube-apiserver
+// process, with controller-runtime's envtest, from the binaries installed with
+// setup-envtest. Features testing APIs only (CRDs, webhooks, RBAC, validation) can
+// run against it in seconds, without kind or a real cluster. There are no nodes nor
+// controllers: pods are never scheduled, and no garbage collection happens.
+package envtest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/rest"
+	log "k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+)
+
+const (
+	// AssetsEnvVar is the environment variable pointing to the directory of the control plane
+	// binaries, as set by `setup-envtest use -p env`. It takes precedence over WithAssetsDir.
+	AssetsEnvVar = "KUBEBUILDER_ASSETS"
+	// DefaultStartTimeout is the time each control plane process has to start
+	DefaultStartTimeout = 20 * time.Second
+)
+
+// ControlPlane is a local control plane started with controller-runtime's envtest
+type ControlPlane struct {
+	name        string
+	env         *envtest.Environment
+	restConfig  *rest.Config
+	dir         string
+	kubecfgFile string
+}
+
+// NewControlPlane creates a control plane, name is used in its kubeconfig
+func NewControlPlane(name string) *ControlPlane {
+	return &ControlPlane{
+		name: name,
+		env:  &envtest.Environment{ControlPlaneStartTimeout: DefaultStartTimeout},
+	}
+}
+
+// WithAssetsDir sets the directory of the etcd and kube-apiserver binaries,
+// used when $KUBEBUILDER_ASSETS is not set
+func (c *ControlPlane) WithAssetsDir(dir string) *ControlPlane {
+	c.env.BinaryAssetsDirectory = dir
+	return c
+}
+
+// WithStartTimeout sets the time each control plane process has to start
+func (c *ControlPlane) WithStartTimeout(timeout time.Duration) *ControlPlane {
+	c.env.ControlPlaneStartTimeout = timeout
+	return c
+}
+
+// WithAPIServerFlags adds flags, i.e. --feature-gates=..., to the kube-apiserver command line.
+// A flag set here overrides the flag of the same name set by envtest.
+func (c *ControlPlane) WithAPIServerFlags(flags ...string) *ControlPlane {
+	args := c.env.ControlPlane.GetAPIServer().Configure()
+	for _, f := range flags {
+		name, value, hasValue := parseFlag(f)
+		if hasValue {
+			args.Set(name, value)
+		} else {
+			args.Enable(name)
+		}
+	}
+	return c
+}
+
+// Environment returns the envtest environment of the control plane, to configure
+// what WithXXX does not cover before Start, i.e. the CRDs and webhooks to install
+func (c *ControlPlane) Environment() *envtest.Environment {
+	return c.env
+}
+
+// Start starts etcd and kube-apiserver, waits for the API server to be ready, and returns
+// the path of a kubeconfig file granting cluster-admin access to it.
+func (c *ControlPlane) Start() (string, error) {
+	log.V(4).Info("Starting envtest control plane ", c.name)
+	// envtest retries its own start, and cannot be stopped when the API server did not start
+	if _, err := c.env.Start(); err != nil {
+		return "", fmt.Errorf("envtest: start control plane %s (set %s to the envtest binaries directory): %w", c.name, AssetsEnvVar, err)
+	}
+
+	kubecfg, err := c.writeKubeconfig()
+	if err != nil {
+		if stopErr := c.Stop(); stopErr != nil {
+			log.V(4).ErrorS(stopErr, "Stopping envtest control plane after a failed start")
+		}
+		return "", fmt.Errorf("envtest: start control plane %s: %w", c.name, err)
+	}
+	log.V(4).Info("Started envtest control plane ", c.name, " at ", c.restConfig.Host)
+	return kubecfg, nil
+}
+
+// writeKubeconfig provisions a cluster-admin user and writes its kubeconfig file
+func (c *ControlPlane) writeKubeconfig() (string, error) {
+	user, err := c.env.AddUser(envtest.User{Name: c.name, Groups: []string{"system:masters"}}, nil)
+	if err != nil {
+		return "", fmt.Errorf("add admin user: %w", err)
+	}
+	data, err := user.KubeConfig()
+	if err != nil {
+		return "", fmt.Errorf("generate kubeconfig: %w", err)
+	}
+	dir, err := ioutil.TempDir("", fmt.Sprintf("envtest-%s-", c.name))
+	if err != nil {
+		return "", err
+	}
+	c.dir = dir
+	c.kubecfgFile = filepath.Join(dir, "kubeconfig")
+	if err := ioutil.WriteFile(c.kubecfgFile, data, 0o600); err != nil {
+		return "", fmt.Errorf("write kubeconfig: %w", err)
+	}
+	c.restConfig = user.Config()
+	return c.kubecfgFile, nil
+}
+
+// RESTConfig returns the configuration of a cluster-admin client of the started control plane
+func (c *ControlPlane) RESTConfig() *rest.Config {
+	return c.restConfig
+}
+
+// KubeconfigFile returns the path of the kubeconfig file of the started control plane
+func (c *ControlPlane) KubeconfigFile() string {
+	return c.kubecfgFile
+}
+
+// Stop stops the kube-apiserver and etcd processes, and removes the files of the control plane
+func (c *ControlPlane) Stop() error {
+	log.V(4).Info("Stopping envtest control plane ", c.name)
+	if err := c.env.Stop(); err != nil {
+		return fmt.Errorf("envtest: stop control plane %s: %w", c.name, err)
+	}
+	if c.dir != "" {
+		if err := os.RemoveAll(c.dir); err != nil {
+			return fmt.Errorf("envtest: stop control plane %s: %w", c.name, err)
+		}
+	}
+	return nil
+}
+
+// parseFlag splits a --name=value command line flag
+func parseFlag(flag string) (name, value string, hasValue bool) {
+	flag = strings.TrimLeft(flag, "-")
+	if i := strings.Index(flag, "="); i >= 0 {
+		return flag[:i], flag[i+1:], true
+	}
+	return flag, "", false
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envtest
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+func TestParseFlag(t *testing.T) {
+	tests := []struct {
+		flag     string
+		name     string
+		value    string
+		hasValue bool
+	}{
+		{flag: "--feature-gates=Foo=true", name: "feature-gates", value: "Foo=true", hasValue: true},
+		{flag: "--authorization-mode=", name: "authorization-mode", value: "", hasValue: true},
+		{flag: "-v=4", name: "v", value: "4", hasValue: true},
+		{flag: "--allow-privileged", name: "allow-privileged"},
+	}
+	for _, test := range tests {
+		name, value, hasValue := parseFlag(test.flag)
+		if name != test.name || value != test.value || hasValue != test.hasValue {
+			t.Errorf("flag %s: expected %s, %s, %t, got %s, %s, %t", test.flag, test.name, test.value, test.hasValue, name, value, hasValue)
+		}
+	}
+}
+
+func TestControlPlane_WithAPIServerFlags(t *testing.T) {
+	cp := NewControlPlane("flags").WithAPIServerFlags("--feature-gates=Foo=true", "--allow-privileged")
+	args := cp.Environment().ControlPlane.GetAPIServer().Configure()
+	if got := args.Get("feature-gates").Get(nil); len(got) != 1 || got[0] != "Foo=true" {
+		t.Errorf("unexpected feature-gates flag %v", got)
+	}
+	// a non-nil empty value is passed as --allow-privileged
+	if got := args.Get("allow-privileged").Get(nil); got == nil || len(got) != 0 {
+		t.Errorf("unexpected allow-privileged flag %v", got)
+	}
+}
+
+func TestControlPlane_Start_MissingBinaries(t *testing.T) {
+	t.Setenv(AssetsEnvVar, t.TempDir())
+	cp := NewControlPlane("missing").WithStartTimeout(time.Second)
+	_, err := cp.Start()
+	if err == nil || !strings.Contains(err.Error(), AssetsEnvVar) {
+		t.Fatalf("expected an error pointing to %s, got %v", AssetsEnvVar, err)
+	}
+}
+
+// TestControlPlane runs the control plane when the envtest binaries are installed
+func TestControlPlane(t *testing.T) {
+	if os.Getenv(AssetsEnvVar) == "" {
+		t.Skipf("%s not set", AssetsEnvVar)
+	}
+	cp := NewControlPlane("test")
+	kubecfg, err := cp.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := cp.Stop(); err != nil {
+			t.Error(err)
+		}
+	}()
+	if kubecfg != cp.KubeconfigFile() {
+		t.Errorf("expected kubeconfig %s, got %s", cp.KubeconfigFile(), kubecfg)
+	}
+	clientset, err := kubernetes.NewForConfig(cp.RESTConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := clientset.Discovery().ServerVersion(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package incluster provides helpers to package a compiled test binary
+// so that a test suite can be executed from inside a cluster as a Job,
+// using the Job's service account to reach the API server.
+package incluster
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	log "k8s.io/klog/v2"
+)
+
+const (
+	// DefaultBinaryPath is the location of the test binary inside the container image.
+	DefaultBinaryPath = "/e2e.test"
+	// DefaultBaseImage is the base image used when generating a Dockerfile.
+	DefaultBaseImage = "gcr.io/distroless/static:nonroot"
+)
+
+type Opts struct {
+	// Namespace is the namespace where the Job is created
+	Namespace string
+	// ServiceAccount is the name of the service account used by the Job pod.
+	// Its token is used by the test suite to access the API server.
+	ServiceAccount string
+	// BinaryPath is the location of the test binary inside the image
+	BinaryPath string
+	// Args are passed to the test binary (i.e. -test.v, --labels)
+	Args []string
+	// Env are additional environment variables set on the test container
+	Env []v1.EnvVar
+	// BackoffLimit is the number of retries before the Job is marked as failed
+	BackoffLimit int32
+}
+
+type Option func(*Opts)
+
+// WithNamespace sets the namespace where the Job is created
+func WithNamespace(namespace string) Option {
+	return func(opts *Opts) {
+		opts.Namespace = namespace
+	}
+}
+
+// WithServiceAccount sets the service account used to run the test suite
+func WithServiceAccount(name string) Option {
+	return func(opts *Opts) {
+		opts.ServiceAccount = name
+	}
+}
+
+// WithBinaryPath sets the location of the test binary inside the image
+func WithBinaryPath(path string) Option {
+	return func(opts *Opts) {
+		opts.BinaryPath = path
+	}
+}
+
+// WithArgs appends arguments passed to the test binary
+func WithArgs(args ...string) Option {
+	return func(opts *Opts) {
+		opts.Args = append(opts.Args, args...)
+	}
+}
+
+// WithEnv appends environment variables to the test container
+func WithEnv(env ...v1.EnvVar) Option {
+	return func(opts *Opts) {
+		opts.Env = append(opts.Env, env...)
+	}
+}
+
+// WithBackoffLimit sets the number of retries of the Job
+func WithBackoffLimit(limit int32) Option {
+	return func(opts *Opts) {
+		opts.BackoffLimit = limit
+	}
+}
+
+// command creates the commands run by BuildTestBinary, it is replaced in tests
+var command = exec.Command
+
+// BuildTestBinary compiles the test package found at pkgDir into a statically
+// linked linux test binary written at output, using `go test -c`.
+func BuildTestBinary(pkgDir, output string) error {
+	out, err := filepath.Abs(output)
+	if err != nil {
+		return fmt.Errorf("incluster: test binary path: %w", err)
+	}
+	cmd := command("go", "test", "-c", "-o", out, pkgDir)
+	// only set GOOS/CGO_ENABLED for the build, not in the current process
+	cmd.Env = append(os.Environ(), "CGO_ENABLED=0", "GOOS=linux")
+	log.V(4).Info("Building test binary: ", cmd.String())
+	if result, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("incluster: build test binary failed: %w: %s", err, result)
+	}
+	return nil
+}
+
+// Dockerfile returns the content of a Dockerfile that packages the test binary
+// found at binary (relative to the build context) on top of baseImage. If baseImage
+// is empty, DefaultBaseImage is used.
+func Dockerfile(baseImage, binary string) string {
+	if baseImage == "" {
+		baseImage = DefaultBaseImage
+	}
+	return fmt.Sprintf("FROM %s\nCOPY %s %s\nENTRYPOINT [\"%s\"]\n", baseImage, binary, DefaultBinaryPath, DefaultBinaryPath)
+}
+
+// NewJob returns a Job that runs the test binary packaged in image. The test suite
+// uses the service account of the Job pod when its config is created with
+// env.NewInClusterConfig or set up with envconf.Config.WithInClusterDetection.
+func NewJob(name, image string, opts ...Option) *batchv1.Job {
+	options := &Opts{
+		Namespace:  "default",
+		BinaryPath: DefaultBinaryPath,
+	}
+	for _, fn := range opts {
+		fn(options)
+	}
+
+	labels := map[string]string{"app.kubernetes.io/name": name, "app.kubernetes.io/component": "e2e"}
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: options.Namespace, Labels: labels},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &options.BackoffLimit,
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: v1.PodSpec{
+					ServiceAccountName: options.ServiceAccount,
+					RestartPolicy:      v1.RestartPolicyNever,
+					Containers: []v1.Container{
+						{
+							Name:    "e2e",
+							Image:   image,
+							Command: []string{options.BinaryPath},
+							Args:    options.Args,
+							Env:     options.Env,
+						},
+					},
+				},
+			},
+		},
+	}
+}
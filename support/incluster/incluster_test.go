@@ -0,0 +1,196 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package incluster
+
+import (
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestNewJob(t *testing.T) {
+	tests := []struct {
+		name           string
+		opts           []Option
+		namespace      string
+		serviceAccount string
+		command        []string
+		args           []string
+		env            []v1.EnvVar
+		backoffLimit   int32
+	}{
+		{
+			name:      "defaults",
+			namespace: "default",
+			command:   []string{DefaultBinaryPath},
+		},
+		{
+			name: "with options",
+			opts: []Option{
+				WithNamespace("e2e"),
+				WithServiceAccount("runner"),
+				WithBinaryPath("/bin/e2e.test"),
+				WithArgs("-test.v"),
+				WithArgs("--labels", "type=smoke"),
+				WithEnv(v1.EnvVar{Name: "FOO", Value: "bar"}),
+				WithBackoffLimit(2),
+			},
+			namespace:      "e2e",
+			serviceAccount: "runner",
+			command:        []string{"/bin/e2e.test"},
+			args:           []string{"-test.v", "--labels", "type=smoke"},
+			env:            []v1.EnvVar{{Name: "FOO", Value: "bar"}},
+			backoffLimit:   2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			job := NewJob("suite", "registry/e2e:latest", test.opts...)
+			if job.Name != "suite" || job.Namespace != test.namespace {
+				t.Errorf("unexpected job name/namespace: %s/%s", job.Namespace, job.Name)
+			}
+			if *job.Spec.BackoffLimit != test.backoffLimit {
+				t.Errorf("unexpected backoff limit: %d", *job.Spec.BackoffLimit)
+			}
+			pod := job.Spec.Template.Spec
+			if pod.ServiceAccountName != test.serviceAccount {
+				t.Errorf("unexpected service account: %s", pod.ServiceAccountName)
+			}
+			if pod.RestartPolicy != v1.RestartPolicyNever {
+				t.Errorf("unexpected restart policy: %s", pod.RestartPolicy)
+			}
+			if len(pod.Containers) != 1 {
+				t.Fatalf("expected one container, got %d", len(pod.Containers))
+			}
+			container := pod.Containers[0]
+			if container.Image != "registry/e2e:latest" {
+				t.Errorf("unexpected image: %s", container.Image)
+			}
+			if !reflect.DeepEqual(container.Command, test.command) {
+				t.Errorf("unexpected command: %v", container.Command)
+			}
+			if !reflect.DeepEqual(container.Args, test.args) {
+				t.Errorf("unexpected args: %v", container.Args)
+			}
+			if !reflect.DeepEqual(container.Env, test.env) {
+				t.Errorf("unexpected env: %v", container.Env)
+			}
+		})
+	}
+}
+
+func TestDockerfile(t *testing.T) {
+	tests := []struct {
+		name      string
+		baseImage string
+		binary    string
+		expected  string
+	}{
+		{
+			name:     "default base image",
+			binary:   "e2e.test",
+			expected: "FROM gcr.io/distroless/static:nonroot\nCOPY e2e.test /e2e.test\nENTRYPOINT [\"/e2e.test\"]\n",
+		},
+		{
+			name:      "custom base image",
+			baseImage: "alpine:3.15",
+			binary:    "bin/e2e.test",
+			expected:  "FROM alpine:3.15\nCOPY bin/e2e.test /e2e.test\nENTRYPOINT [\"/e2e.test\"]\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if dockerfile := Dockerfile(test.baseImage, test.binary); dockerfile != test.expected {
+				t.Errorf("unexpected Dockerfile:\n%s", dockerfile)
+			}
+		})
+	}
+}
+
+func TestBuildTestBinary(t *testing.T) {
+	out, err := filepath.Abs("out dir/e2e.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		pkgDir string
+		output string
+		run    string
+		args   []string
+		err    string
+	}{
+		{
+			name:   "build",
+			pkgDir: "./e2e",
+			output: "out dir/e2e.test",
+			run:    "true",
+			args:   []string{"go", "test", "-c", "-o", out, "./e2e"},
+		},
+		{
+			name:   "arguments are not interpreted by a shell",
+			pkgDir: "./e2e; rm -rf /",
+			output: "out dir/e2e.test",
+			run:    "true",
+			args:   []string{"go", "test", "-c", "-o", out, "./e2e; rm -rf /"},
+		},
+		{
+			name:   "build failure",
+			pkgDir: "./e2e",
+			output: "out dir/e2e.test",
+			run:    "false",
+			args:   []string{"go", "test", "-c", "-o", out, "./e2e"},
+			err:    "build test binary failed",
+		},
+	}
+
+	orig := command
+	defer func() { command = orig }()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var args []string
+			var cmd *exec.Cmd
+			command = func(name string, arg ...string) *exec.Cmd {
+				args = append([]string{name}, arg...)
+				cmd = exec.Command(test.run)
+				return cmd
+			}
+
+			err := BuildTestBinary(test.pkgDir, test.output)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Errorf("expected error containing %q, got %v", test.err, err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(args, test.args) {
+				t.Errorf("unexpected command:\n got: %q\nwant: %q", args, test.args)
+			}
+			env := strings.Join(cmd.Env, "\n")
+			if !strings.Contains(env, "CGO_ENABLED=0") || !strings.Contains(env, "GOOS=linux") {
+				t.Errorf("expected CGO_ENABLED=0 and GOOS=linux in the build env")
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kind
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/template"
+
+	log "k8s.io/klog/v2"
+)
+
+// configFuncs are the functions available to kind config templates
+var configFuncs = template.FuncMap{
+	"join": strings.Join,
+	// until returns the integers from 0 to n-1, i.e. to range over node counts
+	"until": func(n int) []int {
+		r := make([]int, n)
+		for i := range r {
+			r[i] = i
+		}
+		return r
+	},
+}
+
+// RenderConfig executes the Go template of a kind config with data, so that node
+// counts, feature gates, or registry mirrors can be parameterized without
+// maintaining a static config file per variant. Besides the template builtins,
+// the join (strings.Join) and until (0 to n-1) functions are available:
+//
+//	kind: Cluster
+//	apiVersion: kind.x-k8s.io/v1alpha4
+//	nodes:
+//	- role: control-plane
+//	{{- range until .Workers }}
+//	- role: worker
+//	{{- end }}
+func RenderConfig(configTemplate string, data interface{}) ([]byte, error) {
+	tmpl, err := template.New("kind-config").Funcs(configFuncs).Option("missingkey=error").Parse(configTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("kind config template: %w", err)
+	}
+	var config bytes.Buffer
+	if err := tmpl.Execute(&config, data); err != nil {
+		return nil, fmt.Errorf("kind config template: %w", err)
+	}
+	return config.Bytes(), nil
+}
+
+// CreateWithConfigTemplate creates the cluster with the kind config rendered from
+// the Go template and data, see RenderConfig.
+func (k *Cluster) CreateWithConfigTemplate(imageName, configTemplate string, data interface{}) (string, error) {
+	config, err := RenderConfig(configTemplate, data)
+	if err != nil {
+		return "", err
+	}
+
+	file, err := ioutil.TempFile("", fmt.Sprintf("kind-config-%s-*.yaml", k.name))
+	if err != nil {
+		return "", fmt.Errorf("kind config file: %w", err)
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(config); err != nil {
+		file.Close()
+		return "", fmt.Errorf("kind config file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("kind config file: %w", err)
+	}
+
+	log.V(4).Infof("Rendered kind config for cluster %s:\n%s", k.name, config)
+	return k.CreateWithConfig(imageName, file.Name())
+}
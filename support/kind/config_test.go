@@ -0,0 +1,71 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kind
+
+import (
+	"testing"
+)
+
+const configTemplate = `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+featureGates:
+{{- range $gate, $enabled := .FeatureGates }}
+  {{ $gate }}: {{ $enabled }}
+{{- end }}
+containerdConfigPatches:
+- |-
+  [plugins."io.containerd.grpc.v1.cri".registry.mirrors."docker.io"]
+    endpoint = ["{{ join .Mirrors "\", \"" }}"]
+nodes:
+- role: control-plane
+{{- range until .Workers }}
+- role: worker
+{{- end }}
+`
+
+func TestRenderConfig(t *testing.T) {
+	data := map[string]interface{}{
+		"Workers":      2,
+		"FeatureGates": map[string]bool{"EphemeralContainers": true},
+		"Mirrors":      []string{"http://registry:5000", "https://mirror.gcr.io"},
+	}
+	config, err := RenderConfig(configTemplate, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+featureGates:
+  EphemeralContainers: true
+containerdConfigPatches:
+- |-
+  [plugins."io.containerd.grpc.v1.cri".registry.mirrors."docker.io"]
+    endpoint = ["http://registry:5000", "https://mirror.gcr.io"]
+nodes:
+- role: control-plane
+- role: worker
+- role: worker
+`
+	if string(config) != expected {
+		t.Errorf("unexpected config:\n%s", config)
+	}
+
+	if _, err := RenderConfig(configTemplate, map[string]interface{}{"Workers": 1}); err == nil {
+		t.Error("expected missing template data to fail rendering")
+	}
+}
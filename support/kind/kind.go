@@ -31,15 +31,28 @@ import (
 
 var kindVersion = "v0.11.0"
 
+// kindClusterLabel is the label kind sets on the node containers of a cluster, and
+// that volumes created for the cluster, i.e. with extraMounts, are expected to have
+const kindClusterLabel = "io.x-k8s.kind.cluster"
+
+// kindNetwork is the docker network shared by kind clusters
+const kindNetwork = "kind"
+
 type Cluster struct {
-	name        string
-	e           *gexe.Echo
-	kubecfgFile string
-	version     string
+	name           string
+	e              *gexe.Echo
+	kubecfgFile    string
+	version        string
+	dockerCleanup  bool
+	networkCleanup bool
+	// docker runs a docker command, it is replaced in tests
+	docker func(args string) (string, error)
 }
 
 func NewCluster(name string) *Cluster {
-	return &Cluster{name: name, e: gexe.New()}
+	k := &Cluster{name: name, e: gexe.New()}
+	k.docker = k.runDocker
+	return k
 }
 
 // WithVersion set kind version
@@ -48,6 +61,25 @@ func (k *Cluster) WithVersion(ver string) *Cluster {
 	return k
 }
 
+// WithDockerCleanup configures Destroy to also remove the containers labelled as
+// belonging to the cluster, along with their anonymous volumes, and the volumes
+// labelled as belonging to the cluster, to avoid accumulating residue on long-lived
+// hosts when the deletion of a cluster was interrupted. The containers and volumes
+// of other clusters are left untouched.
+func (k *Cluster) WithDockerCleanup() *Cluster {
+	k.dockerCleanup = true
+	return k
+}
+
+// WithNetworkCleanup configures Destroy to also remove the kind docker network
+// when no container is attached to it anymore. The network is shared by all the
+// kind clusters of the host, so this should only be used when no other cluster
+// is being created concurrently.
+func (k *Cluster) WithNetworkCleanup() *Cluster {
+	k.networkCleanup = true
+	return k
+}
+
 func (k *Cluster) getKubeconfig() (string, error) {
 	kubecfg := fmt.Sprintf("%s-kubecfg", k.name)
 
@@ -144,6 +176,8 @@ func (k *Cluster) Destroy() error {
 		return fmt.Errorf("kind: delete cluster failed: %s: %s", p.Err(), p.Result())
 	}
 
+	k.cleanupDocker()
+
 	log.V(4).Info("Removing kubeconfig file ", k.kubecfgFile)
 	if err := os.RemoveAll(k.kubecfgFile); err != nil {
 		return fmt.Errorf("kind: remove kubefconfig failed: %w", err)
@@ -152,9 +186,60 @@ func (k *Cluster) Destroy() error {
 	return nil
 }
 
+// cleanupDocker removes the docker residue left after the cluster deletion, as
+// configured with WithDockerCleanup and WithNetworkCleanup. Failures are logged
+// and ignored since the cluster itself is already gone.
+func (k *Cluster) cleanupDocker() {
+	if k.dockerCleanup {
+		filter := fmt.Sprintf("label=%s=%s", kindClusterLabel, k.name)
+		log.V(4).Info("Removing docker containers and volumes of kind cluster ", k.name)
+		if ids := k.dockerList(fmt.Sprintf(`ps --all --quiet --filter %s`, filter)); len(ids) > 0 {
+			if out, err := k.docker(fmt.Sprintf(`rm --force --volumes %s`, strings.Join(ids, " "))); err != nil {
+				log.V(4).Infof("kind: remove docker containers: %s: %s", err, out)
+			}
+		}
+		if names := k.dockerList(fmt.Sprintf(`volume ls --quiet --filter %s`, filter)); len(names) > 0 {
+			if out, err := k.docker(fmt.Sprintf(`volume rm --force %s`, strings.Join(names, " "))); err != nil {
+				log.V(4).Infof("kind: remove docker volumes: %s: %s", err, out)
+			}
+		}
+	}
+
+	if k.networkCleanup {
+		// the network is shared across kind clusters, only remove it when unused
+		out, err := k.docker(fmt.Sprintf(`network inspect --format "{{len .Containers}}" %s`, kindNetwork))
+		if err != nil {
+			log.V(4).Infof("kind: inspect docker network %s: %s: %s", kindNetwork, err, out)
+			return
+		}
+		if strings.Trim(strings.TrimSpace(out), `"`) != "0" {
+			log.V(4).Infof("kind: docker network %s still in use, skipping removal", kindNetwork)
+			return
+		}
+		if out, err := k.docker(fmt.Sprintf(`network rm %s`, kindNetwork)); err != nil {
+			log.V(4).Infof("kind: remove docker network %s: %s: %s", kindNetwork, err, out)
+		}
+	}
+}
+
+// dockerList returns the IDs or names listed by the docker command, one per line
+func (k *Cluster) dockerList(args string) []string {
+	out, err := k.docker(args)
+	if err != nil {
+		log.V(4).Infof("kind: docker %s: %s: %s", args, err, out)
+		return nil
+	}
+	return strings.Fields(out)
+}
+
+func (k *Cluster) runDocker(args string) (string, error) {
+	p := k.e.RunProc(fmt.Sprintf(`docker %s`, args))
+	return p.Result(), p.Err()
+}
+
 func (k *Cluster) findOrInstallKind(e *gexe.Echo) error {
 	if e.Prog().Avail("kind") == "" {
-		log.V(4).Infof(`kind not found, installing with GO111MODULE="on" go get sigs.k8s.io/kind@%s`, kindVersion)
+		log.V(4).Info(`kind not found, installing with GO111MODULE="on" go get sigs.k8s.io/kind`)
 		if err := k.installKind(e); err != nil {
 			return err
 		}
@@ -163,12 +248,14 @@ func (k *Cluster) findOrInstallKind(e *gexe.Echo) error {
 }
 
 func (k *Cluster) installKind(e *gexe.Echo) error {
+	// do not update the package default as it is shared by all clusters
+	version := kindVersion
 	if k.version != "" {
-		kindVersion = k.version
+		version = k.version
 	}
 
-	log.V(4).Infof("Installing: go get sigs.k8s.io/kind@%s", kindVersion)
-	p := e.SetEnv("GO111MODULE", "on").RunProc(fmt.Sprintf("go get sigs.k8s.io/kind@%s", kindVersion))
+	log.V(4).Infof("Installing: go get sigs.k8s.io/kind@%s", version)
+	p := e.SetEnv("GO111MODULE", "on").RunProc(fmt.Sprintf("go get sigs.k8s.io/kind@%s", version))
 	if p.Err() != nil {
 		return fmt.Errorf("failed to install kind: %s", p.Err())
 	}
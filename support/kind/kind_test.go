@@ -0,0 +1,166 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kind
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestCluster_cleanupDocker(t *testing.T) {
+	tests := []struct {
+		name     string
+		cluster  func(*Cluster) *Cluster
+		inspect  string
+		inspErr  error
+		expected []string
+	}{
+		{
+			name:     "no cleanup",
+			cluster:  func(k *Cluster) *Cluster { return k },
+			expected: nil,
+		},
+		{
+			name:    "docker cleanup lists cluster containers and volumes",
+			cluster: func(k *Cluster) *Cluster { return k.WithDockerCleanup() },
+			expected: []string{
+				`ps --all --quiet --filter label=io.x-k8s.kind.cluster=test`,
+				`volume ls --quiet --filter label=io.x-k8s.kind.cluster=test`,
+			},
+		},
+		{
+			name:    "network cleanup removes unused network",
+			cluster: func(k *Cluster) *Cluster { return k.WithNetworkCleanup() },
+			inspect: `"0"`,
+			expected: []string{
+				`network inspect --format "{{len .Containers}}" kind`,
+				`network rm kind`,
+			},
+		},
+		{
+			name:     "network cleanup keeps network in use",
+			cluster:  func(k *Cluster) *Cluster { return k.WithNetworkCleanup() },
+			inspect:  `"2"`,
+			expected: []string{`network inspect --format "{{len .Containers}}" kind`},
+		},
+		{
+			name:     "network cleanup keeps network on inspect failure",
+			cluster:  func(k *Cluster) *Cluster { return k.WithNetworkCleanup() },
+			inspErr:  fmt.Errorf("no such network"),
+			expected: []string{`network inspect --format "{{len .Containers}}" kind`},
+		},
+		{
+			name:    "docker and network cleanup",
+			cluster: func(k *Cluster) *Cluster { return k.WithDockerCleanup().WithNetworkCleanup() },
+			inspect: "0\n",
+			expected: []string{
+				`ps --all --quiet --filter label=io.x-k8s.kind.cluster=test`,
+				`volume ls --quiet --filter label=io.x-k8s.kind.cluster=test`,
+				`network inspect --format "{{len .Containers}}" kind`,
+				`network rm kind`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var commands []string
+			k := test.cluster(NewCluster("test"))
+			k.docker = func(args string) (string, error) {
+				commands = append(commands, args)
+				if args == `network inspect --format "{{len .Containers}}" kind` {
+					return test.inspect, test.inspErr
+				}
+				return "", nil
+			}
+
+			k.cleanupDocker()
+			if !reflect.DeepEqual(commands, test.expected) {
+				t.Errorf("unexpected docker commands:\n got: %q\nwant: %q", commands, test.expected)
+			}
+		})
+	}
+}
+
+// fakeDocker stands for the docker daemon, listing its containers and volumes by label
+// filter and removing them, to check which resources the cleanup selects
+type fakeDocker struct {
+	containers map[string]map[string]string
+	volumes    map[string]map[string]string
+	removed    []string
+}
+
+func (d *fakeDocker) run(args string) (string, error) {
+	fields := strings.Fields(args)
+	switch {
+	case strings.HasPrefix(args, "ps --all --quiet --filter label="):
+		return d.list(d.containers, fields[len(fields)-1]), nil
+	case strings.HasPrefix(args, "volume ls --quiet --filter label="):
+		return d.list(d.volumes, fields[len(fields)-1]), nil
+	case strings.HasPrefix(args, "rm --force --volumes "):
+		for _, id := range fields[3:] {
+			d.removed = append(d.removed, "container "+id)
+		}
+	case strings.HasPrefix(args, "volume rm --force "):
+		for _, name := range fields[3:] {
+			d.removed = append(d.removed, "volume "+name)
+		}
+	default:
+		return "", fmt.Errorf("unexpected docker command %q", args)
+	}
+	return "", nil
+}
+
+// list returns the names of the resources matching the label=key=value filter
+func (d *fakeDocker) list(resources map[string]map[string]string, filter string) string {
+	kv := strings.SplitN(strings.TrimPrefix(filter, "label="), "=", 2)
+	var names []string
+	for name, labels := range resources {
+		if v, ok := labels[kv[0]]; ok && v == kv[1] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, "\n")
+}
+
+func TestCluster_cleanupDocker_Selection(t *testing.T) {
+	d := &fakeDocker{
+		containers: map[string]map[string]string{
+			"test-control-plane":  {kindClusterLabel: "test"},
+			"test-worker":         {kindClusterLabel: "test"},
+			"other-control-plane": {kindClusterLabel: "other"},
+			"registry":            {},
+		},
+		volumes: map[string]map[string]string{
+			"test-data":  {kindClusterLabel: "test"},
+			"other-data": {kindClusterLabel: "other"},
+			"unlabelled": {},
+		},
+	}
+	k := NewCluster("test").WithDockerCleanup()
+	k.docker = d.run
+	k.cleanupDocker()
+
+	expected := []string{"container test-control-plane", "container test-worker", "volume test-data"}
+	if !reflect.DeepEqual(d.removed, expected) {
+		t.Errorf("unexpected docker resources removed:\n got: %q\nwant: %q", d.removed, expected)
+	}
+}
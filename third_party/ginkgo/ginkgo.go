@@ -0,0 +1,149 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ginkgo provides adapters between e2e-framework and Ginkgo (v1) suites, so that
+// suites can be migrated incrementally from one framework to the other.
+//
+// Features are run from Ginkgo specs, as subtests of the test running the suite:
+//
+//   func TestSuite(t *testing.T) {
+//       e2eginkgo.RunSpecs(t, testenv, "my suite")
+//   }
+//
+//   var _ = ginkgo.Describe("pods", func() {
+//       ginkgo.It("runs the feature", e2eginkgo.TestFeatures(testenv, podFeature))
+//   })
+//
+// where RunSpecs maps the environment Setup and Finish funcs to the beginning and
+// end of the suite. Conversely, Ginkgo spec bodies are wrapped as assessments with Spec,
+// and a whole Ginkgo suite is run as a feature step with Suite, so that mixed suites run
+// under a single Environment.Run.
+//
+// The package is a module of its own, so that Ginkgo is only a dependency of the suites using it.
+package ginkgo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	g "github.com/onsi/ginkgo"
+
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+)
+
+var (
+	// suiteT is the test running the Ginkgo suite with RunSpecs
+	suiteTMu sync.Mutex
+	suiteT   *testing.T
+)
+
+// RunSpecs runs the Ginkgo suite, from a TestXxx func, between the Setup and Finish funcs of
+// the environment, as Ginkgo BeforeSuite and AfterSuite would. The context updated by the
+// Setup funcs is available to the features run with TestFeatures. Returns whether the suite passed.
+func RunSpecs(t *testing.T, testEnv env.Environment, description string) bool {
+	suiteTMu.Lock()
+	suiteT = t
+	suiteTMu.Unlock()
+	defer func() {
+		suiteTMu.Lock()
+		suiteT = nil
+		suiteTMu.Unlock()
+	}()
+	return env.RunTests(testEnv, func() int {
+		if g.RunSpecs(t, description) {
+			return 0
+		}
+		return 1
+	}) == 0
+}
+
+// TestFeatures returns a Ginkgo spec body that tests the features with the environment as a
+// subtest of the test running the suite with RunSpecs. The environment BeforeEachTest,
+// BeforeEachFeature, AfterEachFeature, and AfterEachTest funcs are run as for Environment.Test.
+// The spec fails when any of the features fails, the details being in the go test output.
+func TestFeatures(testEnv env.Environment, feats ...features.Feature) func() {
+	return func() {
+		suiteTMu.Lock()
+		t := suiteT
+		suiteTMu.Unlock()
+		if t == nil {
+			g.Fail("e2e-framework features must be run in a suite started with RunSpecs", 1)
+		}
+		name := g.CurrentGinkgoTestDescription().FullTestText
+		passed := t.Run(name, func(t *testing.T) {
+			testEnv.Test(t, feats...)
+		})
+		if !passed {
+			g.Fail(fmt.Sprintf("e2e-framework feature(s) failed, see the test output of %s/%s", t.Name(), name), 1)
+		}
+	}
+}
+
+var (
+	// current is the test of the Spec being run, if any, used by Fail
+	currentMu sync.Mutex
+	current   *testing.T
+)
+
+// Spec wraps a Ginkgo spec body as a feature step. To report the assertion failures of the
+// body to the test, register Fail as the gomega fail handler, in place of ginkgo.Fail:
+//
+//   gomega.RegisterFailHandler(e2eginkgo.Fail)
+//
+// Wrapped specs must not run concurrently, so they should not be used in parallel features.
+func Spec(body func()) features.Func {
+	return func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+		currentMu.Lock()
+		current = t
+		currentMu.Unlock()
+		defer func() {
+			currentMu.Lock()
+			current = nil
+			currentMu.Unlock()
+			// failures reported with ginkgo.Fail outside of a Ginkgo suite panic
+			if r := recover(); r != nil {
+				if r == g.GINKGO_PANIC {
+					t.Fatal("ginkgo failure, register this package Fail func as the gomega fail handler for details")
+				}
+				panic(r)
+			}
+		}()
+		body()
+		return ctx
+	}
+}
+
+// Fail reports a failure to the test of the Spec being run, if any, or to Ginkgo otherwise.
+// It can be registered as the gomega fail handler for both e2e-framework and Ginkgo tests.
+func Fail(message string, callerSkip ...int) {
+	currentMu.Lock()
+	t := current
+	currentMu.Unlock()
+	if t == nil {
+		skip := 1
+		if len(callerSkip) > 0 {
+			skip += callerSkip[0]
+		}
+		g.Fail(message, skip)
+		return
+	}
+	t.Helper()
+	t.Fatal(message)
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ginkgo
+
+import (
+	"context"
+	"testing"
+
+	g "github.com/onsi/ginkgo"
+
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+)
+
+type ctxKey struct{}
+
+var (
+	suiteEnv = env.New()
+	events   []string
+)
+
+var _ = g.Describe("suite", func() {
+	g.It("runs features", TestFeatures(suiteEnv, features.New("feature").
+		Assess("setup context", func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+			events = append(events, "assess-"+ctx.Value(ctxKey{}).(string))
+			return ctx
+		}).Feature()))
+})
+
+func TestRunSpecs(t *testing.T) {
+	suiteEnv.Setup(func(ctx context.Context, _ *envconf.Config) (context.Context, error) {
+		events = append(events, "setup")
+		return context.WithValue(ctx, ctxKey{}, "from-setup"), nil
+	}).Finish(func(ctx context.Context, _ *envconf.Config) (context.Context, error) {
+		events = append(events, "finish")
+		return ctx, nil
+	})
+
+	if !RunSpecs(t, suiteEnv, "e2e-framework interop") {
+		t.Fatal("expected suite to pass")
+	}
+	expected := []string{"setup", "assess-from-setup", "finish"}
+	if len(events) != len(expected) {
+		t.Fatalf("expected events %v, got %v", expected, events)
+	}
+	for i := range expected {
+		if events[i] != expected[i] {
+			t.Fatalf("expected events %v, got %v", expected, events)
+		}
+	}
+}
+
+func TestSpec(t *testing.T) {
+	ran := false
+	testEnv := env.New()
+	testEnv.Test(t, features.New("wrapped").Assess("spec", Spec(func() {
+		ran = true
+	})).Feature())
+	if !ran {
+		t.Error("expected wrapped spec body to run")
+	}
+
+	failing := &testing.T{}
+	done := make(chan struct{})
+	// run in its own goroutine since Fail stops it with t.Fatal
+	go func() {
+		defer close(done)
+		Spec(func() { Fail("boom") })(context.TODO(), failing, envconf.New())
+	}()
+	<-done
+	if !failing.Failed() {
+		t.Error("expected Fail to fail the wrapped spec test")
+	}
+}
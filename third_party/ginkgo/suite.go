@@ -0,0 +1,192 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ginkgo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	g "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/config"
+	"github.com/onsi/ginkgo/types"
+	log "k8s.io/klog/v2"
+
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+)
+
+// SuiteOption configures the Ginkgo suite run by Suite
+type SuiteOption func(*suiteOptions)
+
+type suiteOptions struct {
+	focus []string
+	skip  []string
+}
+
+// WithFocus runs only the Ginkgo specs whose text matches one of the regular expressions,
+// as the ginkgo.focus flag does
+func WithFocus(patterns ...string) SuiteOption {
+	return func(o *suiteOptions) {
+		o.focus = append(o.focus, patterns...)
+	}
+}
+
+// WithSkip skips the Ginkgo specs whose text matches one of the regular expressions,
+// as the ginkgo.skip flag does
+func WithSkip(patterns ...string) SuiteOption {
+	return func(o *suiteOptions) {
+		o.skip = append(o.skip, patterns...)
+	}
+}
+
+var (
+	// suiteMu guards the run of the Ginkgo suite by Suite, and the context
+	// and config of the environment available to the specs
+	suiteMu     sync.Mutex
+	suiteRan    bool
+	suiteCtx    context.Context
+	suiteConfig *envconf.Config
+)
+
+// Suite returns a feature step running the Ginkgo specs of the test binary, so that existing
+// Ginkgo suites run as features of an environment, along with the other features, sharing
+// its Setup funcs, feature filtering, and reporting:
+//
+//	feature := features.New("legacy specs").
+//	    WithLabel("type", "ginkgo").
+//	    Assess("specs", e2eginkgo.Suite("legacy suite", e2eginkgo.WithSkip(`\[Slow\]`))).
+//	    Feature()
+//	testenv.Test(t, feature)
+//
+// Each spec is reported as a subtest of the step, named after the spec text, which fails
+// when the spec fails and is skipped when the spec is skipped or pending. The BeforeSuite
+// and AfterSuite nodes are run as hooks of the step. The specs get the context and the
+// config of the environment with CurrentContext and CurrentConfig.
+//
+// Ginkgo runs its suite once per test binary, so Suite must be used in a single step of
+// the binary and cannot be combined with RunSpecs.
+func Suite(description string, opts ...SuiteOption) features.Func {
+	options := &suiteOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return func(ctx context.Context, t *testing.T, cfg *envconf.Config) context.Context {
+		suiteMu.Lock()
+		defer suiteMu.Unlock()
+		if suiteRan {
+			t.Fatal("the Ginkgo suite can only be run once per test binary")
+		}
+		suiteRan = true
+
+		suiteCtx, suiteConfig = ctx, cfg
+		prev := config.GinkgoConfig
+		config.GinkgoConfig.FocusStrings = append(append([]string(nil), prev.FocusStrings...), options.focus...)
+		config.GinkgoConfig.SkipStrings = append(append([]string(nil), prev.SkipStrings...), options.skip...)
+		defer func() {
+			config.GinkgoConfig = prev
+			suiteCtx, suiteConfig = nil, nil
+		}()
+
+		log.V(4).Infof("Running Ginkgo suite %q", description)
+		g.RunSpecsWithDefaultAndCustomReporters(t, description, []g.Reporter{&testReporter{t: t}})
+		return ctx
+	}
+}
+
+// CurrentContext returns the context of the environment running the Ginkgo suite with Suite,
+// as updated by its Setup funcs and the previous steps of the feature
+func CurrentContext() context.Context {
+	if suiteCtx == nil {
+		return context.Background()
+	}
+	return suiteCtx
+}
+
+// CurrentConfig returns the config of the environment running the Ginkgo suite with Suite,
+// or nil when the suite is not run by an environment
+func CurrentConfig() *envconf.Config {
+	return suiteConfig
+}
+
+// testReporter reports the Ginkgo specs as subtests of t. The reporter is called
+// from the goroutine running the suite, which is the goroutine of t.
+type testReporter struct {
+	t *testing.T
+}
+
+func (r *testReporter) SpecSuiteWillBegin(config.GinkgoConfigType, *types.SuiteSummary) {}
+
+func (r *testReporter) BeforeSuiteDidRun(summary *types.SetupSummary) {
+	r.reportSetup("BeforeSuite", summary)
+}
+
+func (r *testReporter) SpecWillRun(*types.SpecSummary) {}
+
+func (r *testReporter) SpecDidComplete(summary *types.SpecSummary) {
+	r.t.Run(specName(summary.ComponentTexts), func(t *testing.T) {
+		switch {
+		case summary.HasFailureState():
+			t.Error(failureMessage(summary.State, summary.Failure))
+		case summary.Pending():
+			t.Skip("pending spec")
+		case summary.Skipped():
+			t.Skip(summary.Failure.Message)
+		}
+	})
+}
+
+func (r *testReporter) AfterSuiteDidRun(summary *types.SetupSummary) {
+	r.reportSetup("AfterSuite", summary)
+}
+
+func (r *testReporter) SpecSuiteDidEnd(*types.SuiteSummary) {}
+
+// reportSetup reports the failure of a BeforeSuite or AfterSuite node as a failed subtest
+func (r *testReporter) reportSetup(name string, summary *types.SetupSummary) {
+	if summary.State.IsFailure() {
+		r.t.Run(name, func(t *testing.T) {
+			t.Error(failureMessage(summary.State, summary.Failure))
+		})
+	}
+}
+
+// specName joins the texts of the containers and the subject of a spec,
+// without the top level container
+func specName(texts []string) string {
+	if len(texts) > 1 {
+		texts = texts[1:]
+	}
+	return strings.Join(texts, " ")
+}
+
+func failureMessage(state types.SpecState, failure types.SpecFailure) string {
+	outcome := "failed"
+	switch state {
+	case types.SpecStatePanicked:
+		outcome = "panicked"
+	case types.SpecStateTimedOut:
+		outcome = "timed out"
+	}
+	msg := fmt.Sprintf("%s: %s\n%s", outcome, failure.Message, failure.Location)
+	if failure.ForwardedPanic != "" {
+		msg += "\n" + failure.ForwardedPanic
+	}
+	return msg
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ginkgo
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	g "github.com/onsi/ginkgo"
+
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+)
+
+// the interop specs are only registered in the child process of TestSuite,
+// as the Ginkgo suite of the test binary is also run by TestRunSpecs
+var _ = os.Getenv("E2E_FRAMEWORK_GINKGO_SUITE") == "1" && g.Describe("interop specs", func() {
+	g.It("reads the env context", func() {
+		if CurrentContext().Value(ctxKey{}) != "from-setup" || CurrentConfig() == nil {
+			g.Fail("expected the context and config of the environment")
+		}
+	})
+	g.It("fails", func() {
+		g.Fail("boom")
+	})
+	g.PIt("is pending", func() {})
+	g.It("is skipped by option", func() {
+		g.Fail("expected the spec to be skipped")
+	})
+})
+
+func TestSuite(t *testing.T) {
+	if os.Getenv("E2E_FRAMEWORK_GINKGO_SUITE") == "1" {
+		testEnv := env.NewWithConfig(envconf.New())
+		testEnv.Setup(func(ctx context.Context, _ *envconf.Config) (context.Context, error) {
+			return context.WithValue(ctx, ctxKey{}, "from-setup"), nil
+		})
+		feature := features.New("ginkgo").
+			Assess("specs", Suite("e2e-framework suite", WithFocus("interop specs"), WithSkip("skipped by option"))).
+			Feature()
+		env.RunTests(testEnv, func() int {
+			testEnv.Test(t, feature)
+			return 0
+		})
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestSuite$", "-test.v")
+	cmd.Env = append(os.Environ(), "E2E_FRAMEWORK_GINKGO_SUITE=1")
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected the failing spec to fail the suite, output:\n%s", out)
+	}
+	for _, expected := range []string{
+		"--- PASS: TestSuite/ginkgo/specs/interop_specs_reads_the_env_context",
+		"--- FAIL: TestSuite/ginkgo/specs/interop_specs_fails",
+		"failed: boom",
+		"--- SKIP: TestSuite/ginkgo/specs/interop_specs_is_pending",
+		"--- SKIP: TestSuite/ginkgo/specs/interop_specs_is_skipped_by_option",
+		"--- SKIP: TestSuite/ginkgo/specs/suite_runs_features",
+		"--- FAIL: TestSuite/ginkgo/specs ",
+	} {
+		if !strings.Contains(string(out), expected) {
+			t.Errorf("expected %q in the output:\n%s", expected, out)
+		}
+	}
+}